@@ -18,6 +18,7 @@ import (
 	"github.com/ymatrix-data/mxbench/internal/engine/benchmark"
 	"github.com/ymatrix-data/mxbench/internal/engine/generator"
 	"github.com/ymatrix-data/mxbench/internal/engine/writer"
+	"github.com/ymatrix-data/mxbench/internal/result"
 	"github.com/ymatrix-data/mxbench/internal/util"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
 	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
@@ -36,9 +37,7 @@ func main() {
 	var lastExitSignalTime time.Time
 
 	cfg = config.Init()
-	cfg.NewGeneratorFunc = generator.GetGenerator(cfg.GeneratorCfg)
-	cfg.NewWriterFunc = writer.GetWriter(cfg.WriterCfg)
-	cfg.NewBenchmarkFunc = benchmark.GetBenchmark(cfg.BenchmarkCfg)
+	initPlugins(cfg)
 
 	err = config.DoAfterInit(cfg)
 	if err != nil {
@@ -54,6 +53,18 @@ func main() {
 		mxerror.FromError(err).OSExit()
 	}
 
+	if cfg.GlobalCfg.Command == "compare" {
+		report, err := result.Compare(cfg)
+		if err != nil {
+			if mxerr, ok := err.(*mxerror.MxbenchError); ok {
+				mxerr.OSExit()
+			}
+			mxerror.FromError(err).OSExit()
+		}
+		fmt.Print(report)
+		os.Exit(0)
+	}
+
 	util.PrintLogo("https://www.ymatrix.cn")
 
 	// Limit CPU used for mxbench, to avoid CPI hogging.
@@ -80,7 +91,15 @@ func main() {
 		}
 	}
 
-	e, err := engine.NewEngineFromConfig(cfg)
+	var e engine.IEngine
+	switch cfg.GlobalCfg.Command {
+	case "sweep":
+		e, err = newSweep(cfg)
+	case "scenario":
+		e, err = newScenario(cfg)
+	default:
+		e, err = engine.New(cfg)
+	}
 	if err != nil {
 		failQuit(e, err)
 	}
@@ -90,7 +109,7 @@ func main() {
 		if err != nil {
 			failQuit(e, err)
 		}
-		gracefulQuit(e.Config, e)
+		gracefulQuit(cfg, e)
 	}()
 
 	signalChan := make(chan os.Signal, 4)
@@ -139,6 +158,41 @@ func main() {
 	}
 }
 
+func initPlugins(cfg *engine.Config) {
+	cfg.NewGeneratorFunc = generator.GetGenerator(cfg.GeneratorCfg)
+	cfg.NewWriterFunc = writer.GetWriter(cfg.WriterCfg)
+	cfg.NewBenchmarkFunc = benchmark.GetBenchmark(cfg.BenchmarkCfg)
+	cfg.SaveResultFunc = result.Save
+}
+
+// newSweep creates the engine of command sweep, running an engine for each point in turn
+func newSweep(cfg *engine.Config) (*engine.Sweep, error) {
+	cfgs, err := config.SweepConfigs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cfgs {
+		initPlugins(c)
+	}
+	log.Info("Sweep %d points of %d options", len(cfgs), len(cfg.SweepCfg.Options))
+	return engine.NewSweep(cfg, cfgs), nil
+}
+
+// newScenario creates the engine of command scenario, running an engine for each phase in turn
+func newScenario(cfg *engine.Config) (*engine.Scenario, error) {
+	cfgs, err := config.ScenarioConfigs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cfgs {
+		if c != nil {
+			initPlugins(c)
+		}
+	}
+	log.Info("Scenario of %d phases", len(cfgs))
+	return engine.NewScenario(cfg, cfgs), nil
+}
+
 func failQuit(e engine.IEngine, err error) {
 	failQuitOnce.Do(func() {
 		if !e.IsNil() {
@@ -150,6 +204,7 @@ func failQuit(e engine.IEngine, err error) {
 			if !e.IsNil() {
 				e.PrintStat()
 				e.GetFormattedSummary()
+				e.SaveResult()
 			}
 
 			mxerror.FromError(err).OSExit()
@@ -161,15 +216,21 @@ func failQuit(e engine.IEngine, err error) {
 func gracefulQuit(cfg *engine.Config, e engine.IEngine) {
 	// Perform a graceful stop
 	gracefulQuitOnce.Do(func() {
+		var thresholdErr error
 		if !e.IsNil() {
 			e.Close()
 			injector.PostEngineClose()
 			e.PrintStat()
+			thresholdErr = e.CheckThresholds()
 			e.GetFormattedSummary()
+			e.SaveResult()
 		}
 		if cfg.GlobalCfg.CPUProfile {
 			stopCPUProfile()
 		}
+		if mxerr, ok := thresholdErr.(*mxerror.MxbenchError); ok {
+			mxerr.OSExit()
+		}
 		log.Info("mxbench exit normally")
 		os.Exit(0)
 	})
@@ -32,8 +32,78 @@ func DoAfterInit(cfg *engine.Config) error {
 	if err != nil {
 		return err
 	}
+	err = cfg.ThresholdsCfg.DoAfterInit()
+	if err != nil {
+		return err
+	}
 
 	// derivation
 	cfg.GeneratorCfg.GlobalConfig = &cfg.GlobalCfg
 	return nil
 }
+
+// SweepConfigs returns the config of each point of the sweep, in the order of cfg.SweepCfg.GetPoints(),
+// parsed from the command line with the values of the point appended as flags.
+// The points share the seed of cfg, so that they run against the same data.
+func SweepConfigs(cfg *engine.Config) ([]*engine.Config, error) {
+	points := cfg.SweepCfg.GetPoints()
+	cfgs := make([]*engine.Config, 0, len(points))
+	for i, point := range points {
+		c, err := parseWithArgs(cfg, point.GetArgs())
+		if err != nil {
+			return nil, mxerror.IncorrectUsageErrorf("invalid sweep point %d (%s): %v", i+1, point, err)
+		}
+		if point.SharesTable {
+			// run the queries only, against the table loaded by the previous point
+			c.GeneratorCfg.Plugin = "nil"
+			c.WriterCfg.Plugin = "nil"
+		}
+		if err = DoAfterInit(c); err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, c)
+	}
+	return cfgs, nil
+}
+
+// ScenarioConfigs returns the config of each phase of the scenario in order, nil for a phase executing SQL,
+// parsed the same way as SweepConfigs with the options of the phase.
+// The table is created by the first phase running mxbench, which the later phases run against.
+func ScenarioConfigs(cfg *engine.Config) ([]*engine.Config, error) {
+	phases := cfg.ScenarioCfg.Phases
+	cfgs := make([]*engine.Config, 0, len(phases))
+	tableExists := false
+	for i, phase := range phases {
+		if len(phase.SQL) > 0 {
+			cfgs = append(cfgs, nil)
+			continue
+		}
+		c, err := parseWithArgs(cfg, phase.Args)
+		if err != nil {
+			return nil, mxerror.IncorrectUsageErrorf("invalid scenario phase %d (%s): %v", i+1, phase.Name, err)
+		}
+		c.GlobalCfg.TableExists = tableExists
+		tableExists = true
+		if err = DoAfterInit(c); err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, c)
+	}
+	return cfgs, nil
+}
+
+// parseWithArgs parses the command line with the args appended into a new config sharing the seed of cfg.
+func parseWithArgs(cfg *engine.Config, args []string) (*engine.Config, error) {
+	c, err := engine.NewConfig()
+	if err != nil {
+		return nil, err
+	}
+	args = append(append([]string{}, os.Args[1:]...), args...)
+	if err = parser.NewWithArgs(c, args).ParseDerived(); err != nil {
+		return nil, err
+	}
+	if c.GlobalCfg.Seed == 0 {
+		c.GlobalCfg.Seed = cfg.GlobalCfg.GetSeed()
+	}
+	return c, nil
+}
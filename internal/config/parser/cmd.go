@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"strings"
 )
 
 /**
@@ -41,7 +42,33 @@ func (parser *CmdParser) parse() error {
 			if cfg.GlobalCfg.CfgFile != "" {
 				return errIncorrectUsage
 			}
+			if len(args) > 2 {
+				return errConfigUsage
+			}
+			if len(args) == 2 {
+				switch format := strings.ToLower(args[1]); format {
+				case _CONFIG_FORMAT_TOML, _CONFIG_FORMAT_YAML, _CONFIG_FORMAT_JSON:
+					parser.configFormat = format
+				case "yml":
+					parser.configFormat = _CONFIG_FORMAT_YAML
+				default:
+					return errConfigUsage
+				}
+			}
 			configWanted = true
+		case "compare":
+			if len(args) != 3 {
+				return errCompareUsage
+			}
+			cfg.GlobalCfg.CompareRunIDs = args[1:]
+		case "sweep":
+			if len(args) != 1 || cfg.GlobalCfg.CfgFile == "" {
+				return errSweepUsage
+			}
+		case "scenario":
+			if len(args) != 1 || cfg.GlobalCfg.CfgFile == "" {
+				return errScenarioUsage
+			}
 		case "run":
 			// Run is the default behavior that start the bench in current session
 		default:
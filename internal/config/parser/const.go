@@ -2,6 +2,13 @@ package parser
 
 import "github.com/ymatrix-data/mxbench/internal/util/mxerror"
 
+// Formats of config files
+const (
+	_CONFIG_FORMAT_TOML = "toml"
+	_CONFIG_FORMAT_YAML = "yaml"
+	_CONFIG_FORMAT_JSON = "json"
+)
+
 var (
 	errUnknown    = mxerror.CommonError("unknown")
 	errParseFlags = mxerror.CommonError("parse flags error")
@@ -11,4 +18,8 @@ var (
 	errVersionWanted = mxerror.SuccessError("version wanted")
 
 	errIncorrectUsage = mxerror.IncorrectUsageError("invalid usage: command config conflict with --config option")
+	errConfigUsage    = mxerror.IncorrectUsageError("invalid usage: command config takes an optional format of toml, yaml or json, e.g. config yaml")
+	errCompareUsage   = mxerror.IncorrectUsageError("invalid usage: command compare needs 2 run ids, e.g. compare <run-id> <run-id>")
+	errSweepUsage     = mxerror.IncorrectUsageError("invalid usage: command sweep runs the [sweep] section of the config file, e.g. sweep --config mxbench.conf")
+	errScenarioUsage  = mxerror.IncorrectUsageError("invalid usage: command scenario runs the [[scenario]] phases of the config file, e.g. scenario --config mxbench.conf")
 )
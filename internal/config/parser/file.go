@@ -3,14 +3,19 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
+// Keys of a config file naming the base config files it overrides
+var includeKeys = []string{"include", "extends"}
+
 /**
-* Parse toml file into configuration struct.
+* Parse toml, yaml or json file into configuration struct.
 * If the value comes from cli, use the cli value,
 * Otherwise, override it.
  */
@@ -24,7 +29,7 @@ func newFileParser(cmdParser *CmdParser) *FileParser {
 	}
 }
 
-// Import config from given toml file
+// Import config from given config file
 func (parser *FileParser) parse() (err error) {
 	if !parser.isCmdParsed {
 		panic(errors.New("need cmd parsed"))
@@ -36,12 +41,22 @@ func (parser *FileParser) parse() (err error) {
 		return nil
 	}
 
-	v := viper.New()
-	v.SetConfigFile(cfg.GlobalCfg.CfgFile)
-	v.SetConfigType("toml")
+	settings, err := parser.readConfigFile(cfg.GlobalCfg.CfgFile, nil)
+	if err != nil {
+		return err
+	}
 
-	err = v.ReadInConfig()
-	if err != nil { // Handle errors reading the config file
+	if !parser.derived {
+		if err = parser.parseCommandSections(settings); err != nil {
+			return err
+		}
+	}
+	delete(settings, _SWEEP_SECTION)
+	delete(settings, _SCENARIO_SECTION)
+
+	v := viper.New()
+	err = v.MergeConfigMap(settings)
+	if err != nil {
 		return fmt.Errorf("error reading config file: %s", err)
 	}
 
@@ -62,6 +77,143 @@ func (parser *FileParser) parse() (err error) {
 	return nil
 }
 
+// parseCommandSections parses the sections of the config file run by commands sweep and scenario
+func (parser *FileParser) parseCommandSections(settings map[string]interface{}) (err error) {
+	cfg := parser.cfg
+	cfg.SweepCfg, err = parser.parseSweep(settings[_SWEEP_SECTION])
+	if err != nil {
+		return fmt.Errorf("error parsing config file: %s", err)
+	}
+	if cfg.GlobalCfg.Command == "sweep" && len(cfg.SweepCfg.Options) == 0 {
+		return errSweepUsage
+	}
+
+	cfg.ScenarioCfg, err = parser.parseScenario(settings[_SCENARIO_SECTION])
+	if err != nil {
+		return fmt.Errorf("error parsing config file: %s", err)
+	}
+	if cfg.GlobalCfg.Command == "scenario" && len(cfg.ScenarioCfg.Phases) == 0 {
+		return errScenarioUsage
+	}
+	return nil
+}
+
+// readConfigFile returns the settings of the config file,
+// over the settings of the files it includes in order.
+// includedBy is the chain of files including it, to tell a cycle.
+func (parser *FileParser) readConfigFile(path string, includedBy []string) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %s", err)
+	}
+	for i, p := range includedBy {
+		if p == absPath {
+			return nil, fmt.Errorf("error reading config file: %s includes itself by %s",
+				path, strings.Join(append(includedBy[i:], absPath), " -> "))
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(configFileType(path))
+
+	err = v.ReadInConfig()
+	if err != nil { // Handle errors reading the config file
+		return nil, fmt.Errorf("error reading config file: %s", err)
+	}
+	settings := v.AllSettings()
+
+	bases, err := includedFiles(settings, path)
+	if err != nil {
+		return nil, err
+	}
+	if err = parser.checkKeys(settings); err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %s", path, err)
+	}
+
+	merged := map[string]interface{}{}
+	for _, base := range bases {
+		baseSettings, err := parser.readConfigFile(base, append(includedBy, absPath))
+		if err != nil {
+			return nil, err
+		}
+		mergeSettings(merged, baseSettings)
+	}
+	mergeSettings(merged, settings)
+	return merged, nil
+}
+
+// configFileType tells the format of the config file by its extension,
+// which is toml unless it is .yaml, .yml or .json.
+func configFileType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return _CONFIG_FORMAT_YAML
+	case ".json":
+		return _CONFIG_FORMAT_JSON
+	}
+	return _CONFIG_FORMAT_TOML
+}
+
+// includedFiles removes the include key from the settings of the config file,
+// returning the paths of the files it names, which are relative to the config file.
+func includedFiles(settings map[string]interface{}, path string) ([]string, error) {
+	var key string
+	var value interface{}
+	for _, k := range includeKeys {
+		if v, ok := settings[k]; ok {
+			if key != "" {
+				return nil, fmt.Errorf("error parsing config file %s: %s and %s are the same, use either of them", path, key, k)
+			}
+			key, value = k, v
+			delete(settings, k)
+		}
+	}
+
+	var names []string
+	switch value := value.(type) {
+	case nil:
+	case string:
+		names = []string{value}
+	case []interface{}:
+		for _, v := range value {
+			name, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("error parsing config file %s: %s must be a file path or a list of them", path, key)
+			}
+			names = append(names, name)
+		}
+	default:
+		return nil, fmt.Errorf("error parsing config file %s: %s must be a file path or a list of them", path, key)
+	}
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// mergeSettings overrides dst by src, merging the sections in both of them
+func mergeSettings(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcSection, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstSection, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dstSection = map[string]interface{}{}
+			dst[k] = dstSection
+		}
+		mergeSettings(dstSection, srcSection)
+	}
+}
+
 func (parser *FileParser) getDecodeHook(c *mapstructure.DecoderConfig) mapstructure.DecodeHookFuncValue {
 	return func(from reflect.Value, to reflect.Value) (interface{}, error) {
 		tag2ValueMap, ok := from.Interface().(map[string]interface{})
@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/generator/telematics"
+)
+
+var _ = Describe("File parser", func() {
+	var dir string
+	var osArgs []string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mxbench_parser")
+		Expect(err).NotTo(HaveOccurred())
+		osArgs = os.Args
+	})
+
+	AfterEach(func() {
+		os.Args = osArgs
+		os.RemoveAll(dir)
+	})
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	parseFile := func(path string, args ...string) (*engine.Config, error) {
+		os.Args = append([]string{"mxbench", "run", "--config", path}, args...)
+		cfg := &engine.Config{}
+		parser := New(cfg)
+		Expect(parser.flags.parse()).To(Succeed())
+		Expect(parser.env.parse()).To(Succeed())
+		Expect(parser.cmd.parse()).To(Succeed())
+		return cfg, parser.file.parse()
+	}
+
+	batchSize := func(cfg *engine.Config) int {
+		return cfg.GeneratorCfg.PluginConfig.(*telematics.Config).BatchSize
+	}
+
+	It("should read toml, yaml and json by the file extension", func() {
+		for _, path := range []string{
+			writeFile("mxbench.conf", `
+[global]
+  tag-num = 20
+[database]
+  db-master-port = 6000
+[generator]
+  generator = "telematics"
+  [generator.telematics]
+    generator-batch-size = 10
+`),
+			writeFile("mxbench.yml", `
+global:
+  tag-num: 20
+database:
+  db-master-port: 6000
+generator:
+  generator: "telematics"
+  telematics:
+    generator-batch-size: 10
+`),
+			writeFile("mxbench.json", `{
+  "global": {"tag-num": 20},
+  "database": {"db-master-port": 6000},
+  "generator": {"generator": "telematics", "telematics": {"generator-batch-size": 10}}
+}`),
+		} {
+			cfg, err := parseFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.GlobalCfg.TagNum).To(Equal(int64(20)))
+			Expect(cfg.DB.MasterPort).To(Equal(6000))
+			Expect(batchSize(cfg)).To(Equal(10))
+		}
+	})
+
+	It("should override the included files in order", func() {
+		writeFile("base/base.yaml", `
+include: common.toml
+global:
+  tag-num: 20
+generator:
+  telematics:
+    generator-batch-size: 10
+`)
+		writeFile("base/common.toml", `
+[global]
+  tag-num = 10
+  total-metrics-count = 100
+[database]
+  db-master-port = 6000
+`)
+		writeFile("other.json", `{"database": {"db-master-port": 7000}}`)
+		path := writeFile("scenario.toml", `
+extends = ["base/base.yaml", "other.json"]
+[global]
+  total-metrics-count = 200
+`)
+		cfg, err := parseFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.GlobalCfg.TagNum).To(Equal(int64(20)))
+		Expect(cfg.GlobalCfg.TotalMetricsCount).To(Equal(int64(200)))
+		Expect(cfg.DB.MasterPort).To(Equal(7000))
+		Expect(batchSize(cfg)).To(Equal(10))
+
+		// the command line still takes precedence
+		cfg, err = parseFile(path, "--tag-num", "5")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.GlobalCfg.TagNum).To(Equal(int64(5)))
+		Expect(cfg.GlobalCfg.TotalMetricsCount).To(Equal(int64(200)))
+	})
+
+	It("should fail on including in a cycle", func() {
+		writeFile("a.toml", `include = "b.toml"`)
+		writeFile("b.toml", `include = ["a.toml"]`)
+		_, err := parseFile(filepath.Join(dir, "a.toml"))
+		Expect(err).To(MatchError(ContainSubstring("a.toml includes itself by")))
+
+		path := writeFile("both.toml", "include = \"b.toml\"\nextends = \"b.toml\"\n")
+		_, err = parseFile(path)
+		Expect(err).To(MatchError(ContainSubstring("use either of them")))
+
+		path = writeFile("missing.toml", `include = "not_exist.toml"`)
+		_, err = parseFile(path)
+		Expect(err).To(MatchError(ContainSubstring("error reading config file")))
+	})
+
+	It("should list the closest key names of unknown keys", func() {
+		path := writeFile("mxbench.toml", `
+[global]
+  tag-nums = 20
+[benchmark]
+  tag-num = 20
+  [benchmark.telematic]
+    benchmark-parallel = [1]
+[benchmark.telematics]
+  benchmark-paralel = [1]
+[generater]
+  generator = "nil"
+`)
+		_, err := parseFile(path)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`unknown key "tag-nums" in [global], did you mean "tag-num", `))
+		Expect(err.Error()).To(ContainSubstring(`unknown key "tag-num" in [benchmark], which is an option of [global]`))
+		Expect(err.Error()).To(ContainSubstring(`unknown key "telematic" in [benchmark], did you mean "telematics" or `))
+		Expect(err.Error()).To(ContainSubstring(`unknown key "benchmark-paralel" in [benchmark.telematics], did you mean "benchmark-parallel", `))
+		Expect(err.Error()).To(ContainSubstring(`unknown key "generater", did you mean "generator", `))
+	})
+})
+
+var _ = Describe("Closest names", func() {
+	It("should sort the names by edit distance", func() {
+		Expect(editDistance("", "abc")).To(Equal(3))
+		Expect(editDistance("kitten", "sitting")).To(Equal(3))
+		Expect(editDistance("tag-num", "tag-num")).To(Equal(0))
+		Expect(closestNames("tag-nun", []string{"table-name", "tag-num", "ts-end", "tables"}, 2)).
+			To(Equal([]string{"tag-num", "tables"}))
+		Expect(closestNames("x", []string{"ab"}, 3)).To(Equal([]string{"ab"}))
+	})
+})
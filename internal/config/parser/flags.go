@@ -2,9 +2,12 @@ package parser
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -57,7 +60,7 @@ type MatrixFlagSets []MatrixFlagSet
 /**
  * Parse cli flags into configuration struct.
  * Print usage info
- * Print configuration struct into toml
+ * Print configuration struct into toml, yaml or json
  */
 type FlagsParser struct {
 	// Protect field value effect by cli flag
@@ -68,6 +71,14 @@ type FlagsParser struct {
 	mainFlagSet  *pflag.FlagSet
 	tempPath     string
 	isFlagParsed bool
+	// The format command config prints in
+	configFormat string
+	// The command line arguments to parse, os.Args[1:] unless given
+	args []string
+	// Parsing a config derived from the one of the command, e.g. of a sweep point,
+	// which returns the error on an invalid flag instead of printing the usage,
+	// and switches the plugins by the flags, regardless of the config file
+	derived bool
 
 	RenderGeneratorConfigFunc func(*viper.Viper, engine.GeneratorConfig, bool, ...viper.DecoderConfigOption) (interface{}, error)
 	RenderWriterConfigFunc    func(*viper.Viper, engine.WriterConfig, bool, ...viper.DecoderConfigOption) (interface{}, error)
@@ -81,11 +92,13 @@ type FlagsParser struct {
 	cfg *engine.Config
 }
 
-func newFlagsParser(cfg *engine.Config) *FlagsParser {
+func newFlagsParser(cfg *engine.Config, args []string) *FlagsParser {
 	parser := &FlagsParser{
 		FlagSets:     MatrixFlagSets{},
 		protectedKey: map[string]bool{},
 		existFlagMap: map[string]bool{},
+		configFormat: _CONFIG_FORMAT_TOML,
+		args:         args,
 		cfg:          cfg,
 	}
 
@@ -105,6 +118,7 @@ func newFlagsParser(cfg *engine.Config) *FlagsParser {
 	parser.mainFlagSet = main
 
 	cfg.Usage = parser.Usage
+	cfg.RenderConfig = parser.Render
 
 	tempDir := util.TempDir()
 	parser.tempPath = filepath.Join(tempDir, fmt.Sprintf("mxbenchcfg.%d.toml", os.Getpid()))
@@ -145,7 +159,17 @@ func (parser *FlagsParser) parse() error {
 	fSetWriter, fSetWriterSub := parser.InitWriterFlagSet(&cfg.WriterCfg)
 	parser.addFlagSet("writer", fSetWriter, fSetWriterSub...)
 
-	if err := parser.mainFlagSet.Parse(os.Args[1:]); err != nil {
+	fSetThresholds := cfg.ThresholdsFlagSet()
+	parser.addFlagSet("thresholds", fSetThresholds)
+
+	if parser.derived {
+		parser.mainFlagSet.SetOutput(io.Discard)
+		parser.mainFlagSet.Usage = func() {}
+	}
+	if err := parser.mainFlagSet.Parse(parser.args); err != nil {
+		if parser.derived {
+			return err
+		}
 		fmt.Println(err)
 		return errParseFlags
 	}
@@ -164,7 +188,10 @@ func (parser *FlagsParser) Usage() {
 	fmt.Println("")
 	fmt.Println("The commands are:")
 	fmt.Println("    run            Run mxbench in command line")
-	fmt.Println("    config         Print full sample configuration to STDOUT")
+	fmt.Println("    config         Print full sample configuration to STDOUT, in toml, or yaml or json if given")
+	fmt.Println("    sweep          Run mxbench for each combination of the values in [sweep] of --config")
+	fmt.Println("    scenario       Run the [[scenario]] phases of --config one after another")
+	fmt.Println("    compare        Compare the results of 2 runs saved in --results-db")
 	fmt.Println("    help           Show usage")
 	fmt.Println("    version        Show version")
 	fmt.Println("")
@@ -188,6 +215,9 @@ Examples:
     # example to generate with listed argements, all non-listed values be default:
     %[1]s config --db-master-port 6000 > mxbench.conf
 
+    # generate in yaml or json, which --config loads by the file extension:
+    %[1]s config yaml [<args...>] > mxbench.yaml
+
     # edit mxbench.conf with your customized configuration for each plugin:
     # such as delimiter or time format
     vim mxbench.conf
@@ -211,7 +241,7 @@ func (parser *FlagsParser) initPlugin(v *viper.Viper, opts ...viper.DecoderConfi
 			cfgGenerator := v.GetString("generator.generator")
 			renew := false
 			if cliGenerator.Changed {
-				if cliGenerator.Value.String() != cfgGenerator {
+				if cliGenerator.Value.String() != cfgGenerator && !parser.derived {
 					return fmt.Errorf("conflict generator configuration, cli: %s, config: %s", cliGenerator.Value.String(), cfgGenerator)
 				}
 			} else {
@@ -231,7 +261,7 @@ func (parser *FlagsParser) initPlugin(v *viper.Viper, opts ...viper.DecoderConfi
 			cfgBench := v.GetString("benchmark.benchmark")
 			renew := false
 			if cliBench.Changed {
-				if cliBench.Value.String() != cfgBench {
+				if cliBench.Value.String() != cfgBench && !parser.derived {
 					return fmt.Errorf("conflict benchmark configuration, cli: %s, config: %s", cliBench.Value.String(), cfgBench)
 				}
 			} else {
@@ -251,7 +281,7 @@ func (parser *FlagsParser) initPlugin(v *viper.Viper, opts ...viper.DecoderConfi
 			cfgWriter := v.GetString("writer.writer")
 			renew := false
 			if cliWriter.Changed {
-				if cliWriter.Value.String() != cfgWriter {
+				if cliWriter.Value.String() != cfgWriter && !parser.derived {
 					return fmt.Errorf("conflict writer configuration, cli: %s, config: %s", cliWriter.Value.String(), cfgWriter)
 				}
 			} else {
@@ -268,28 +298,82 @@ func (parser *FlagsParser) initPlugin(v *viper.Viper, opts ...viper.DecoderConfi
 	return
 }
 
-// Print current command line flags in toml format
+// Print current command line flags in the format given to command config
 func (parser *FlagsParser) Print() error {
+	var out string
+	var err error
+	switch parser.configFormat {
+	case _CONFIG_FORMAT_YAML:
+		out, err = parser.RenderYAML()
+	case _CONFIG_FORMAT_JSON:
+		out, err = parser.RenderJSON()
+	default:
+		out, err = parser.Render()
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+// Render returns what Print prints in toml
+func (parser *FlagsParser) Render() (string, error) {
 	err := parser.writeToTemp()
 	if err != nil {
-		return errors.Errorf("[Config] failed: %s", err)
+		return "", errors.Errorf("[Config] failed: %s", err)
 	}
-	err = parser.beautifyPrintTemp()
+	var out strings.Builder
+	err = parser.beautifyPrintTemp(&out)
 	if err != nil {
-		return errors.Errorf("[Config] failed: %s", err)
+		return "", errors.Errorf("[Config] failed: %s", err)
 	}
-	return nil
+	return out.String(), nil
 }
 
-// Write current config to temp file
-func (parser *FlagsParser) writeToTemp() error {
+// RenderYAML returns what Print prints in yaml,
+// which comments the same items with the same usage hints as the toml
+func (parser *FlagsParser) RenderYAML() (string, error) {
+	settings, err := parser.settings()
+	if err != nil {
+		return "", errors.Errorf("[Config] failed: %s", err)
+	}
+	var out strings.Builder
+	err = parser.printYAML(&out, settings, "", 0)
+	if err != nil {
+		return "", errors.Errorf("[Config] failed: %s", err)
+	}
+	return out.String(), nil
+}
+
+// RenderJSON returns what Print prints in json,
+// where nothing is commented as json has no comments
+func (parser *FlagsParser) RenderJSON() (string, error) {
+	settings, err := parser.settings()
+	if err != nil {
+		return "", errors.Errorf("[Config] failed: %s", err)
+	}
+	var out strings.Builder
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(settings)
+	if err != nil {
+		return "", errors.Errorf("[Config] failed: %s", err)
+	}
+	return out.String(), nil
+}
+
+// Return the current config by sections
+func (parser *FlagsParser) settings() (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
 	for _, fs := range parser.FlagSets {
 		v := viper.New()
 		var subSet string
 
 		err := v.BindPFlags(fs.FSet)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		switch fs.Label {
@@ -306,13 +390,25 @@ func (parser *FlagsParser) writeToTemp() error {
 			for _, ss := range fs.SubSet {
 				err = sv.BindPFlags(ss.FSet)
 				if err != nil {
-					return fmt.Errorf("bind flags: %s", err)
+					return nil, fmt.Errorf("bind flags: %s", err)
 				}
 			}
 			v.Set(subSet, sv.AllSettings())
 		}
 
-		viper.Set(fs.Label, v.AllSettings())
+		settings[fs.Label] = v.AllSettings()
+	}
+	return settings, nil
+}
+
+// Write current config to temp file
+func (parser *FlagsParser) writeToTemp() error {
+	settings, err := parser.settings()
+	if err != nil {
+		return err
+	}
+	for label, section := range settings {
+		viper.Set(label, section)
 	}
 
 	viper.SetConfigFile(parser.tempPath)
@@ -321,11 +417,58 @@ func (parser *FlagsParser) writeToTemp() error {
 	return viper.WriteConfig()
 }
 
+// Print the sections of the config in yaml, where values are in json,
+// which is yaml as well
+func (parser *FlagsParser) printYAML(out io.Writer, settings map[string]interface{}, currentSet string, indent int) error {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	prefix := strings.Repeat(" ", indent)
+	for _, k := range keys {
+		if section, ok := settings[k].(map[string]interface{}); ok {
+			fmt.Fprintf(out, "%s%s:\n", prefix, k)
+			set := k
+			if currentSet != "" {
+				set = currentSet + "." + k
+			}
+			if err := parser.printYAML(out, section, set, indent+2); err != nil {
+				return err
+			}
+			continue
+		}
+		if filterItems.isItemIn(currentSet, k) {
+			continue
+		}
+
+		var value strings.Builder
+		enc := json.NewEncoder(&value)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(settings[k]); err != nil {
+			return err
+		}
+		line := fmt.Sprintf("%s%s: %s", prefix, k, strings.TrimSpace(value.String()))
+		flag := parser.findFlag(k)
+		if flag != nil {
+			if usage := parser.indentUsage(indent, flag.Usage); usage != "" {
+				fmt.Fprintln(out, usage)
+			}
+			if !flag.Changed && !mandatoryItems.isItemIn(currentSet, k) {
+				line = prefix + "# " + line[indent:]
+			}
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
 // For export config, after viper.WriteConfig() we traverse the config
 // file and perform additional processing
 // 1. Filer some items that we don't want to see in the config file
 // 2. Add usage hint if any
-func (parser *FlagsParser) beautifyPrintTemp() (err error) {
+func (parser *FlagsParser) beautifyPrintTemp(out io.Writer) (err error) {
 	defer os.Remove(parser.tempPath)
 
 	var fh *os.File
@@ -373,9 +516,9 @@ func (parser *FlagsParser) beautifyPrintTemp() (err error) {
 
 	OUTPUT:
 		if usage != "" {
-			fmt.Println(usage)
+			fmt.Fprintln(out, usage)
 		}
-		fmt.Println(line)
+		fmt.Fprintln(out, line)
 	}
 
 	if err = scanner.Err(); err != nil {
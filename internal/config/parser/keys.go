@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+// The number of the closest key names suggested for an unknown key
+const _SUGGESTION_NUM = 3
+
+// checkKeys returns an error listing the keys of the settings that are neither sections
+// nor options, each with the closest key names in place of it.
+func (parser *FileParser) checkKeys(settings map[string]interface{}) error {
+	sections := []string{_SWEEP_SECTION, _SCENARIO_SECTION}
+	for _, fs := range parser.FlagSets {
+		sections = append(sections, fs.Label)
+	}
+
+	var unknowns []string
+	for name, value := range settings {
+		if name == _SWEEP_SECTION || name == _SCENARIO_SECTION {
+			// checked by parseSweep and parseScenario, as their keys may be of any section
+			continue
+		}
+		var fs *MatrixFlagSet
+		for i := range parser.FlagSets {
+			if parser.FlagSets[i].Label == name {
+				fs = &parser.FlagSets[i]
+			}
+		}
+		if fs == nil {
+			unknowns = append(unknowns, parser.unknownKey("", name, sections))
+			continue
+		}
+		section, _ := value.(map[string]interface{})
+		unknowns = append(unknowns, parser.checkSectionKeys(fs, section)...)
+	}
+	if len(unknowns) == 0 {
+		return nil
+	}
+	sort.Strings(unknowns)
+	return errors.New(strings.Join(unknowns, "\n"))
+}
+
+func (parser *FileParser) checkSectionKeys(fs *MatrixFlagSet, section map[string]interface{}) []string {
+	var candidates []string
+	fs.FSet.VisitAll(func(flag *pflag.Flag) {
+		candidates = append(candidates, flag.Name)
+	})
+	for _, ss := range fs.SubSet {
+		candidates = append(candidates, strings.TrimPrefix(ss.Label, fs.Label+"."))
+	}
+
+	var unknowns []string
+	for key, value := range section {
+		if fs.FSet.Lookup(key) != nil {
+			continue
+		}
+		if pluginSet := parser.pluginFlagSet(fs.Label, key); pluginSet != nil {
+			pluginSection, _ := value.(map[string]interface{})
+			unknowns = append(unknowns, parser.checkSectionKeys(&MatrixFlagSet{fs.Label + "." + key, pluginSet, nil}, pluginSection)...)
+			continue
+		}
+		unknowns = append(unknowns, parser.unknownKey(fs.Label, key, candidates))
+	}
+	return unknowns
+}
+
+// pluginFlagSet returns the flags of the plugin named in the section of the plugin type,
+// nil if there is no such plugin
+func (parser *FileParser) pluginFlagSet(label, name string) (fs *pflag.FlagSet) {
+	switch label {
+	case "generator":
+		fs, _ = parser.GetGeneratorDefaultFlagsFunc(engine.GeneratorConfig{Plugin: name})
+	case "writer":
+		fs, _ = parser.GetWriterDefaultFlagsFunc(engine.WriterConfig{Plugin: name})
+	case "benchmark":
+		fs, _ = parser.GetBenchmarkDefaultFlagsFunc(engine.BenchmarkConfig{Plugin: name})
+	}
+	return
+}
+
+func (parser *FileParser) unknownKey(section, key string, candidates []string) string {
+	msg := fmt.Sprintf("unknown key %q", key)
+	if section != "" {
+		msg += fmt.Sprintf(" in [%s]", section)
+	}
+
+	// an option put in a wrong section
+	for _, fs := range parser.FlagSets {
+		for _, set := range append(MatrixFlagSets{fs}, fs.SubSet...) {
+			if set.Label != section && set.FSet.Lookup(key) != nil {
+				return msg + fmt.Sprintf(", which is an option of [%s]", set.Label)
+			}
+		}
+	}
+
+	suggestions := closestNames(key, candidates, _SUGGESTION_NUM)
+	if len(suggestions) == 0 {
+		return msg
+	}
+	for i := range suggestions {
+		suggestions[i] = fmt.Sprintf("%q", suggestions[i])
+	}
+	if len(suggestions) == 1 {
+		return msg + fmt.Sprintf(", did you mean %s?", suggestions[0])
+	}
+	last := len(suggestions) - 1
+	return msg + fmt.Sprintf(", did you mean %s or %s?", strings.Join(suggestions[:last], ", "), suggestions[last])
+}
+
+// closestNames returns at most n names closest to the name by edit distance
+func closestNames(name string, names []string, n int) []string {
+	distances := make(map[string]int, len(names))
+	for _, candidate := range names {
+		distances[candidate] = editDistance(name, candidate)
+	}
+	closest := make([]string, 0, len(names))
+	for candidate := range distances {
+		closest = append(closest, candidate)
+	}
+	sort.Slice(closest, func(i, j int) bool {
+		di, dj := distances[closest[i]], distances[closest[j]]
+		if di != dj {
+			return di < dj
+		}
+		return closest[i] < closest[j]
+	})
+	if len(closest) > n {
+		closest = closest[:n]
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
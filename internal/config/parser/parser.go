@@ -2,7 +2,6 @@ package parser
 
 import (
 	"fmt"
-	"math"
 	"os"
 	"strings"
 
@@ -24,11 +23,16 @@ type Parser struct {
 }
 
 func New(cfg *engine.Config) *Parser {
+	return NewWithArgs(cfg, os.Args[1:])
+}
+
+// NewWithArgs creates a parser of the given command line arguments instead of os.Args.
+func NewWithArgs(cfg *engine.Config, args []string) *Parser {
 	parser := &Parser{
 		cfg: cfg,
 	}
 
-	parser.flags = newFlagsParser(cfg)
+	parser.flags = newFlagsParser(cfg, args)
 	parser.env = newEnvParser(parser.flags)
 	parser.cmd = newCmdParser(parser.env)
 	parser.file = newFileParser(parser.cmd)
@@ -55,6 +59,45 @@ func (parser *Parser) Parse() {
 	parser.catch(err)
 }
 
+// ParseDerived parses a config derived from the one of the command, e.g. of a sweep point or a scenario phase,
+// the same way as Parse, except that it returns the error instead of printing the usage and exiting,
+// and leaves the [sweep] and [[scenario]] of the config file alone.
+func (parser *Parser) ParseDerived() error {
+	parser.flags.derived = true
+	for _, parse := range []func() error{
+		parser.flags.parse,
+		parser.env.parse,
+		parser.cmd.parse,
+		parser.file.parse,
+	} {
+		if err := parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pluginArgs returns the plugin flag and its value in the arguments, as either --flag value or --flag=value,
+// or all the arguments if the flag is not given.
+// The last one is returned if the flag is given more than once, e.g. overridden by a sweep point,
+// the same as the value of the flag parsed.
+func (parser *FlagsParser) pluginArgs(flag string) []string {
+	args := parser.args
+	for i, v := range parser.args {
+		if strings.HasPrefix(strings.ToLower(v), flag+"=") {
+			args = parser.args[i : i+1]
+		}
+		if strings.ToLower(v) == flag {
+			to := i + 2
+			if to > len(parser.args) {
+				to = len(parser.args)
+			}
+			args = parser.args[i:to]
+		}
+	}
+	return args
+}
+
 func (parser *Parser) catch(err error) {
 	if err == nil {
 		return
@@ -96,24 +139,7 @@ Sub-options varies based on generator type`
 	parentSet.SetOutput(os.Stdout)
 
 	// Try parse the type of generator first, and attach per plugin specific flags
-	var v string
-	var i, from int
-	var to = math.MaxInt32
-	for i, v = range os.Args[1:] {
-		if strings.ToLower(v) == "--generator" {
-			from = i + 1
-			to = i + 3
-			break
-		}
-	}
-
-	if from >= len(os.Args) {
-		from = len(os.Args) - 1
-	}
-	if to > len(os.Args) {
-		to = len(os.Args)
-	}
-	_ = parentSet.Parse(os.Args[from:to])
+	_ = parentSet.Parse(parser.pluginArgs("--generator"))
 
 	var pluginFlags *pflag.FlagSet
 	var subSet []MatrixFlagSet
@@ -137,24 +163,7 @@ Types restricted to: telematics/nil`
 	parentSet.SetOutput(os.Stdout)
 
 	// Try parse the type of benchmark first, and attach per plugin specific flags
-	var v string
-	var i, from int
-	var to = math.MaxInt32
-	for i, v = range os.Args[1:] {
-		if strings.ToLower(v) == "--benchmark" {
-			from = i + 1
-			to = i + 3
-			break
-		}
-	}
-
-	if from >= len(os.Args) {
-		from = len(os.Args) - 1
-	}
-	if to > len(os.Args) {
-		to = len(os.Args)
-	}
-	_ = parentSet.Parse(os.Args[from:to])
+	_ = parentSet.Parse(parser.pluginArgs("--benchmark"))
 
 	var pluginFlags *pflag.FlagSet
 	var subSet []MatrixFlagSet
@@ -171,7 +180,7 @@ Types restricted to: telematics/nil`
 
 func (parser *FlagsParser) InitWriterFlagSet(cfg *engine.WriterConfig) (*pflag.FlagSet, []MatrixFlagSet) {
 	const desc = `Writer populates data to MatrixGate
-Types restricted to: http/stdin/nil`
+Types restricted to: http/stdin/copy/insert/nil`
 
 	parentSet := pflag.NewFlagSet("writer", pflag.ContinueOnError)
 	parentSet.StringVar(&cfg.Plugin, "writer", "http", desc)
@@ -179,24 +188,7 @@ Types restricted to: http/stdin/nil`
 	parentSet.SetOutput(os.Stdout)
 
 	// Try parse the type of writer first, and attach per plugin specific flags
-	var v string
-	var i, from int
-	var to = math.MaxInt32
-	for i, v = range os.Args[1:] {
-		if strings.ToLower(v) == "--writer" {
-			from = i + 1
-			to = i + 3
-			break
-		}
-	}
-
-	if from >= len(os.Args) {
-		from = len(os.Args) - 1
-	}
-	if to > len(os.Args) {
-		to = len(os.Args)
-	}
-	_ = parentSet.Parse(os.Args[from:to])
+	_ = parentSet.Parse(parser.pluginArgs("--writer"))
 
 	var pluginFlags *pflag.FlagSet
 	var subSet []MatrixFlagSet
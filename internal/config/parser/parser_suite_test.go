@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+func TestParser(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Parser Suite")
+}
+
+// parseArgs parses the arguments as the command line of mxbench, returning the first error
+func parseArgs(cfg *engine.Config, args ...string) error {
+	parser := NewWithArgs(cfg, args)
+	for _, parse := range []func() error{parser.flags.parse, parser.env.parse, parser.cmd.parse, parser.file.parse} {
+		if err := parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
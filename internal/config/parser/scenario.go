@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+// The array of tables of the config file listing the phases to run by command scenario
+const _SCENARIO_SECTION = "scenario"
+
+// Keys of a phase of the scenario other than the options
+const (
+	_PHASE_KEY_NAME     = "name"
+	_PHASE_KEY_DURATION = "duration"
+	_PHASE_KEY_SQL      = "sql"
+)
+
+// parseScenario returns the phases of the scenario in order,
+// each with its options in the form of the command line.
+func (parser *FileParser) parseScenario(section interface{}) (engine.ScenarioConfig, error) {
+	var scenarioCfg engine.ScenarioConfig
+	var phases []interface{}
+	switch section := section.(type) {
+	case nil:
+		return scenarioCfg, nil
+	case []interface{}:
+		phases = section
+	case []map[string]interface{}:
+		for _, phase := range section {
+			phases = append(phases, phase)
+		}
+	default:
+		return scenarioCfg, fmt.Errorf("%s must be a list of phases, e.g. [[%s]] in toml", _SCENARIO_SECTION, _SCENARIO_SECTION)
+	}
+
+	var candidates []string
+	for _, fs := range parser.FlagSets {
+		for _, set := range append(MatrixFlagSets{fs}, fs.SubSet...) {
+			set.FSet.VisitAll(func(flag *pflag.Flag) {
+				candidates = append(candidates, flag.Name)
+			})
+		}
+	}
+	candidates = append(candidates, _PHASE_KEY_NAME, _PHASE_KEY_DURATION, _PHASE_KEY_SQL)
+
+	var errs []string
+	for i, v := range phases {
+		settings, ok := v.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("phase %d of %s must be a table of options", i+1, _SCENARIO_SECTION))
+			continue
+		}
+		phase, phaseErrs := parser.parsePhase(settings, candidates)
+		if phase.Name == "" {
+			phase.Name = fmt.Sprintf("phase-%d", i+1)
+		}
+		for _, err := range phaseErrs {
+			errs = append(errs, fmt.Sprintf("phase %d of %s: %s", i+1, _SCENARIO_SECTION, err))
+		}
+		scenarioCfg.Phases = append(scenarioCfg.Phases, phase)
+	}
+	if len(errs) > 0 {
+		return scenarioCfg, errors.New(strings.Join(errs, "\n"))
+	}
+	return scenarioCfg, nil
+}
+
+func (parser *FileParser) parsePhase(settings map[string]interface{}, candidates []string) (engine.ScenarioPhase, []string) {
+	var phase engine.ScenarioPhase
+	var errs []string
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := settings[name]
+		switch name {
+		case _PHASE_KEY_NAME:
+			phase.Name = fmt.Sprint(value)
+			continue
+		case _PHASE_KEY_DURATION:
+			d, err := time.ParseDuration(fmt.Sprint(value))
+			if err != nil || d <= 0 {
+				errs = append(errs, fmt.Sprintf("invalid duration %v, e.g. \"10m\"", value))
+			}
+			phase.Duration = d
+			continue
+		case _PHASE_KEY_SQL:
+			switch value := value.(type) {
+			case string:
+				phase.SQL = []string{value}
+			case []interface{}:
+				for _, stmt := range value {
+					phase.SQL = append(phase.SQL, fmt.Sprint(stmt))
+				}
+			default:
+				errs = append(errs, "sql must be a statement or a list of them")
+			}
+			continue
+		case "config", "help", "version":
+			errs = append(errs, fmt.Sprintf("%s can not be given in a phase", name))
+			continue
+		}
+
+		if !parser.isPhaseOption(settings, name) {
+			errs = append(errs, parser.unknownKey("", name, candidates))
+			continue
+		}
+		arg, err := cliArg(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		phase.Args = append(phase.Args, fmt.Sprintf("--%s=%s", name, arg))
+	}
+
+	if len(phase.SQL) > 0 && (len(phase.Args) > 0 || phase.Duration > 0) {
+		errs = append(errs, "a phase executing sql takes no options or duration")
+	}
+	return phase, errs
+}
+
+// isPhaseOption tells the name is an option of the phase,
+// including the ones of the plugins the phase switches to.
+func (parser *FileParser) isPhaseOption(settings map[string]interface{}, name string) bool {
+	if parser.findFlag(name) != nil {
+		return true
+	}
+	for _, label := range []string{"generator", "writer", "benchmark"} {
+		plugin, ok := settings[label].(string)
+		if !ok {
+			continue
+		}
+		if fs := parser.pluginFlagSet(label, plugin); fs != nil && fs.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/generator/file"
+)
+
+var _ = Describe("Scenario phases", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mxbench_scenario")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeScenario := func(content string) string {
+		path := filepath.Join(dir, "scenario.toml")
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+
+	const scenario = `
+[global]
+  table-name = "t1"
+[benchmark]
+  benchmark = "telematics"
+
+[[scenario]]
+  name = "backfill"
+  benchmark = "nil"
+  ts-end = "2022-04-25 10:00:00"
+
+[[scenario]]
+  sql = ["VACUUM t1", "ANALYZE t1"]
+
+[[scenario]]
+  name = "steady state"
+  duration = "10m"
+  realtime = true
+  benchmark-parallel = [4, 8]
+
+[[scenario]]
+  name = "replay"
+  generator = "file"
+  generator-file-paths = ["a.csv", "b.csv"]
+`
+
+	It("should parse the phases in order", func() {
+		cfg := &engine.Config{}
+		err := NewWithArgs(cfg, []string{"scenario", "--config", writeScenario(scenario)}).ParseDerived()
+		Expect(err).NotTo(HaveOccurred())
+		// derived configs leave the phases alone
+		Expect(cfg.ScenarioCfg.Phases).To(BeEmpty())
+
+		cfg = &engine.Config{}
+		Expect(parseArgs(cfg, "scenario", "--config", writeScenario(scenario))).To(Succeed())
+		Expect(cfg.ScenarioCfg.Phases).To(Equal([]engine.ScenarioPhase{
+			{Name: "backfill", Args: []string{"--benchmark=nil", "--ts-end=2022-04-25 10:00:00"}},
+			{Name: "phase-2", SQL: []string{"VACUUM t1", "ANALYZE t1"}},
+			{Name: "steady state", Duration: 10 * time.Minute, Args: []string{"--benchmark-parallel=4,8", "--realtime=true"}},
+			{Name: "replay", Args: []string{"--generator=file", "--generator-file-paths=a.csv,b.csv"}},
+		}))
+	})
+
+	It("should switch the plugins by the options of a phase", func() {
+		path := writeScenario(scenario)
+		cfg := &engine.Config{}
+		err := NewWithArgs(cfg, []string{"scenario", "--config", path, "--benchmark=nil"}).ParseDerived()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BenchmarkCfg.Plugin).To(Equal("nil"))
+
+		cfg = &engine.Config{}
+		err = NewWithArgs(cfg, []string{"scenario", "--config", path, "--generator=file", "--generator-file-paths=a.csv,b.csv"}).ParseDerived()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.GeneratorCfg.Plugin).To(Equal("file"))
+		Expect(cfg.GeneratorCfg.PluginConfig.(*file.Config).FilePaths).To(Equal([]string{"a.csv", "b.csv"}))
+	})
+
+	It("should reject invalid phases", func() {
+		err := parseArgs(&engine.Config{}, "scenario", "--config", writeScenario(`
+[[scenario]]
+  tag-nm = 10
+  duration = "forever"
+
+[[scenario]]
+  sql = "VACUUM t1"
+  tag-num = 10
+`))
+		Expect(err).To(MatchError("error parsing config file: " +
+			"phase 1 of scenario: invalid duration forever, e.g. \"10m\"\n" +
+			`phase 1 of scenario: unknown key "tag-nm", did you mean "tag-num", "tables" or "ts-end"?` + "\n" +
+			"phase 2 of scenario: a phase executing sql takes no options or duration"))
+	})
+})
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+// The section of the config file listing the values of the options to run by command sweep
+const _SWEEP_SECTION = "sweep"
+
+// Sections of the options not changing the table loaded
+var tableKeepingSections = []string{"benchmark", "thresholds"}
+
+// parseSweep returns the options of the sweep section, each with its values in the form of the command line.
+// The options keeping the table are put after the others, so that the points running against the same table
+// are next to each other.
+func (parser *FileParser) parseSweep(section interface{}) (engine.SweepConfig, error) {
+	var sweepCfg engine.SweepConfig
+	if section == nil {
+		return sweepCfg, nil
+	}
+	options, ok := section.(map[string]interface{})
+	if !ok {
+		return sweepCfg, fmt.Errorf("[%s] must be a section of options", _SWEEP_SECTION)
+	}
+
+	var candidates []string
+	for _, fs := range parser.FlagSets {
+		for _, set := range append(MatrixFlagSets{fs}, fs.SubSet...) {
+			set.FSet.VisitAll(func(flag *pflag.Flag) {
+				candidates = append(candidates, flag.Name)
+			})
+		}
+	}
+
+	var errs []string
+	for name, value := range options {
+		if parser.findFlag(name) == nil {
+			errs = append(errs, parser.unknownKey(_SWEEP_SECTION, name, candidates))
+			continue
+		}
+		switch name {
+		case "config", "help", "version":
+			errs = append(errs, fmt.Sprintf("%s can not be swept", name))
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			errs = append(errs, fmt.Sprintf("%s in [%s] must be a list of values", name, _SWEEP_SECTION))
+			continue
+		}
+		opt := engine.SweepOption{Name: name, KeepsTable: parser.keepsTable(name)}
+		for _, v := range list {
+			arg, err := cliArg(v)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s in [%s]: %s", name, _SWEEP_SECTION, err))
+				break
+			}
+			opt.Values = append(opt.Values, arg)
+		}
+		sweepCfg.Options = append(sweepCfg.Options, opt)
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return sweepCfg, errors.New(strings.Join(errs, "\n"))
+	}
+
+	sort.Slice(sweepCfg.Options, func(i, j int) bool {
+		oi, oj := sweepCfg.Options[i], sweepCfg.Options[j]
+		if oi.KeepsTable != oj.KeepsTable {
+			return oj.KeepsTable
+		}
+		return oi.Name < oj.Name
+	})
+	return sweepCfg, nil
+}
+
+// keepsTable tells the option is of a section not changing the table loaded
+func (parser *FileParser) keepsTable(name string) bool {
+	for _, fs := range parser.FlagSets {
+		for _, set := range append(MatrixFlagSets{fs}, fs.SubSet...) {
+			if set.FSet.Lookup(name) == nil {
+				continue
+			}
+			for _, label := range tableKeepingSections {
+				if fs.Label == label {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// cliArg returns the value as it is given in the command line,
+// where a list is joined by commas.
+func cliArg(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		args := make([]string, 0, len(v))
+		for _, e := range v {
+			arg, err := cliArg(e)
+			if err != nil {
+				return "", err
+			}
+			args = append(args, arg)
+		}
+		return strings.Join(args, ","), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/writer/copy"
+)
+
+var _ = Describe("Sweep section", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mxbench_sweep")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	parseSweep := func(content string, args ...string) (*engine.Config, error) {
+		path := filepath.Join(dir, "sweep.toml")
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		cfg := &engine.Config{}
+		return cfg, parseArgs(cfg, append([]string{"sweep", "--config", path}, args...)...)
+	}
+
+	It("should parse the values of the options in the form of the command line", func() {
+		cfg, err := parseSweep(`
+[global]
+  table-name = "t1"
+  tag-num = 20
+[sweep]
+  benchmark-parallel = [[4, 8], [16]]
+  tag-num = [1000, 10000]
+  storage-type = ["mars2", "mars3"]
+  generator-rate-limit = [2.5, 1e6]
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.GlobalCfg.Command).To(Equal("sweep"))
+		// the sweep section is not a part of the config
+		Expect(cfg.GlobalCfg.TagNum).To(Equal(int64(20)))
+		Expect(cfg.SweepCfg.Options).To(Equal([]engine.SweepOption{
+			{Name: "generator-rate-limit", Values: []string{"2.5", "1000000"}},
+			{Name: "storage-type", Values: []string{"mars2", "mars3"}},
+			{Name: "tag-num", Values: []string{"1000", "10000"}},
+			{Name: "benchmark-parallel", Values: []string{"4,8", "16"}, KeepsTable: true},
+		}))
+	})
+
+	It("should parse a point of the sweep as the command line", func() {
+		path := filepath.Join(dir, "sweep.toml")
+		Expect(os.WriteFile(path, []byte(`
+[global]
+  tag-num = 20
+[sweep]
+  tag-num = [1000]
+`), 0644)).To(Succeed())
+		cfg := &engine.Config{}
+		Expect(NewWithArgs(cfg, []string{"sweep", "--config", path, "--tag-num=1000"}).ParseDerived()).To(Succeed())
+		Expect(cfg.GlobalCfg.TagNum).To(Equal(int64(1000)))
+
+		err := NewWithArgs(&engine.Config{}, []string{"sweep", "--config", path, "--tag-num=abc"}).ParseDerived()
+		Expect(err).To(MatchError(ContainSubstring(`invalid argument "abc" for "--tag-num" flag`)))
+	})
+
+	It("should parse the plugin of a point overriding the one of the command line", func() {
+		path := filepath.Join(dir, "sweep.toml")
+		Expect(os.WriteFile(path, []byte(`
+[sweep]
+  writer = ["copy"]
+`), 0644)).To(Succeed())
+		cfg := &engine.Config{}
+		Expect(NewWithArgs(cfg, []string{"sweep", "--config", path, "--writer", "http", "--writer=copy"}).ParseDerived()).To(Succeed())
+		Expect(cfg.WriterCfg.Plugin).To(Equal("copy"))
+		Expect(cfg.WriterCfg.PluginConfig).To(BeAssignableToTypeOf(&copy.Config{}))
+	})
+
+	It("should reject invalid options", func() {
+		_, err := parseSweep(`
+[sweep]
+  tag-nm = [1000]
+  storage-type = "mars3"
+  config = ["a.toml"]
+`)
+		Expect(err).To(MatchError("error parsing config file: " +
+			"config can not be swept\n" +
+			"storage-type in [sweep] must be a list of values\n" +
+			`unknown key "tag-nm" in [sweep], did you mean "tag-num", "tables" or "ts-end"?`))
+
+		_, err = parseSweep(`
+[global]
+  tag-num = 20
+`)
+		Expect(err).To(Equal(errSweepUsage))
+	})
+})
@@ -2,15 +2,49 @@ package engine
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 )
 
+type ArrivalDistribution = string
+
+const (
+	ArrivalUniform ArrivalDistribution = "uniform"
+	ArrivalPoisson ArrivalDistribution = "poisson"
+)
+
 type ExecBenchOption struct {
 	Parallel int
 	RunTimes int64
 	Duration time.Duration
+
+	// TargetQPS switches to open-loop mode when it is positive:
+	// queries are issued at this arrival rate no matter how fast they return,
+	// and Parallel is the number of connections serving them.
+	TargetQPS float64
+	Arrival   ArrivalDistribution
+	// Seed seeds the poisson arrivals, 0 means a random seed
+	Seed int64
+
+	// Prepared prepares the query once on each connection and executes it with bound parameters,
+	// if it is a PreparedQuery, instead of sending the SQL text each time.
+	Prepared bool
+
+	// Validate fetches the results of the query and validates them, if it is a ValidatedQuery,
+	// and FailOnInvalid fails the benchmark if any of them is invalid.
+	Validate      bool
+	FailOnInvalid bool
+
+	// Timeout fails an execution running longer than it by statement_timeout, 0 means no timeout.
+	Timeout time.Duration
+	// Retries is the times to retry an execution failed by a serialization failure or a lost connection,
+	// reconnecting first if the connection is lost.
+	Retries int
+	// ContinueOnError keeps executing the query on the connection after an execution fails,
+	// instead of stopping the connection. Either way the error is counted by its class.
+	ContinueOnError bool
 }
 
 type Query interface {
@@ -18,6 +52,49 @@ type Query interface {
 	GetName() string
 }
 
+// PreparedQuery is a Query which can also be executed as a prepared statement.
+type PreparedQuery interface {
+	Query
+	// GetPreparedSQL returns the statement with the parameters as $1, $2, ...
+	GetPreparedSQL() string
+	// GetParams returns the parameters of an execution, which vary as the SQL returned by GetSQL does
+	GetParams() []interface{}
+}
+
+// ValidatedQuery is a Query whose results can be validated, whether it is also a PreparedQuery or not.
+type ValidatedQuery interface {
+	Query
+	// GetValidatedSQL returns the SQL text of an execution, and the parameters it is drawn with to validate its results by
+	GetValidatedSQL() (string, []interface{})
+	// Validate checks the number of rows returned by an execution with the parameters,
+	// which are returned by GetValidatedSQL, or by GetParams if the query is prepared
+	Validate(params []interface{}, rows int64) error
+}
+
+// MixedQuery is a mixed workload of the queries, run concurrently by one pool of connections,
+// where each execution picks one of the queries by its weight.
+// It is executed with a MixedBenchStat, which breaks the stat down by the queries.
+type MixedQuery struct {
+	Queries []Query
+	Weights []float64
+}
+
+func (q *MixedQuery) GetSQL() string {
+	statements := make([]string, 0, len(q.Queries))
+	for _, query := range q.Queries {
+		statements = append(statements, query.GetSQL())
+	}
+	return strings.Join(statements, ";\n")
+}
+
+func (q *MixedQuery) GetName() string {
+	names := make([]string, 0, len(q.Queries))
+	for _, query := range q.Queries {
+		names = append(names, query.GetName())
+	}
+	return "MIXED(" + strings.Join(names, "+") + ")"
+}
+
 type ExecBenchFunc func(context.Context, Query, Stat) error
 
 type IBenchmark interface {
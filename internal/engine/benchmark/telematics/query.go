@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/lib/pq"
+
 	"github.com/ymatrix-data/mxbench/internal/engine"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 )
@@ -20,6 +22,12 @@ var allQueryNames = []string{
 	_QUERY_NAME_SINGLE_TAG_DETAIL_QUERY,
 }
 
+var (
+	_ engine.ValidatedQuery = (*querySingleLatest)(nil)
+	_ engine.ValidatedQuery = (*queryMultiLatest)(nil)
+	_ engine.ValidatedQuery = (*querySingleDetail)(nil)
+)
+
 var queryNameNewFunc = map[string]func(*metadata.Metadata, *Config) engine.Query{
 	_QUERY_NAME_SINGLE_TAG_LATEST_QUERY: newQuerySingleLatest,
 	_QUERY_NAME_MULTI_TAG_LATEST_QUERY:  newQueryMultiLatest,
@@ -49,3 +57,29 @@ func getQueryParams(meta *metadata.Metadata, cfg *Config) (int64, int64, metadat
 	}
 	return simpleMetricsCount, jsonMetricsCount, durationGenerator
 }
+
+// getDurationParamsGenerator returns the generator of the duration of getQueryParams as the parameters of a prepared query
+func getDurationParamsGenerator(meta *metadata.Metadata, cfg *Config) metadata.ParamsGenerator {
+	if cfg != nil && cfg.TimestampStart != "" && cfg.TimestampEnd != "" {
+		return meta.GetFixedStartEndTSParamsGenerator(cfg.TimestampStart, cfg.TimestampEnd)
+	}
+	return meta.GetRandomStartEndTSParamsGenerator(_DETAIL_QUERY_DURATION)
+}
+
+// quoteParams returns the parameters of a prepared query quoted as the literals of the SQL text
+func quoteParams(params []interface{}) []interface{} {
+	quoted := make([]interface{}, 0, len(params))
+	for _, p := range params {
+		quoted = append(quoted, pq.QuoteLiteral(fmt.Sprint(p)))
+	}
+	return quoted
+}
+
+// preparedParams returns the parameters $from, $from+1, ... of a prepared query, separated by comma
+func preparedParams(from, num int) string {
+	params := make([]string, 0, num)
+	for i := from; i < from+num; i++ {
+		params = append(params, fmt.Sprintf("$%d", i))
+	}
+	return strings.Join(params, ", ")
+}
@@ -2,25 +2,155 @@ package telematics
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
 )
 
-const _CUSTOM_QUERY_NAME_PREFIX = "CUSTOM_QUERY_"
+const (
+	_CUSTOM_QUERY_NAME_PREFIX = "CUSTOM_QUERY_"
 
+	_PLACEHOLDER_VIN        = "vin"
+	_PLACEHOLDER_VINS       = "vins"
+	_PLACEHOLDER_TS_RANGE   = "ts_range"
+	_PLACEHOLDER_METRIC_COL = "metric_col"
+	_PLACEHOLDER_RANDOM_INT = "random_int"
+
+	_DEFAULT_PLACEHOLDER_VINS_NUM = 10
+)
+
+// placeholderRegexp matches a placeholder of a custom query, e.g. {{vin}}, {{vins:10}} or {{random_int:1,100}}
+var placeholderRegexp = regexp.MustCompile(`\{\{\s*(\w+)\s*(?::([^}]*))?\}\}`)
+
+// queryCustom is a custom query, whose placeholders are substituted on every execution.
 type queryCustom struct {
-	statement string
-	number    int // from 1
+	name string
+	// literals are the parts of the statement around the placeholders,
+	// one more than the generators of the placeholders
+	literals   []string
+	generators []func() string
 }
 
 func (q *queryCustom) GetSQL() string {
-	return q.statement
+	if len(q.generators) == 0 {
+		return q.literals[0]
+	}
+	var sb strings.Builder
+	for i, g := range q.generators {
+		sb.WriteString(q.literals[i])
+		sb.WriteString(g())
+	}
+	sb.WriteString(q.literals[len(q.literals)-1])
+	return sb.String()
 }
 
 func (q *queryCustom) GetName() string {
-	return fmt.Sprintf("%s%d", _CUSTOM_QUERY_NAME_PREFIX, q.number)
+	return q.name
+}
+
+func newQueryCustom(meta *metadata.Metadata, cfg *Config, statement, name string) (engine.Query, error) {
+	q := &queryCustom{name: name}
+	last := 0
+	for _, loc := range placeholderRegexp.FindAllStringSubmatchIndex(statement, -1) {
+		placeholder := statement[loc[2]:loc[3]]
+		var arg string
+		if loc[4] >= 0 {
+			arg = strings.TrimSpace(statement[loc[4]:loc[5]])
+		}
+		g, err := newPlaceholderGenerator(meta, cfg, placeholder, arg)
+		if err != nil {
+			return nil, mxerror.CommonErrorf("invalid placeholder %s of custom query %s: %v", statement[loc[0]:loc[1]], name, err)
+		}
+		q.literals = append(q.literals, statement[last:loc[0]])
+		q.generators = append(q.generators, g)
+		last = loc[1]
+	}
+	q.literals = append(q.literals, statement[last:])
+	return q, nil
+}
+
+// newPlaceholderGenerator returns the generator substituting the placeholder with its argument, if any:
+//   - {{vin}}: a random vin, quoted
+//   - {{vins:n}}: n random vins, quoted and separated by comma, 10 by default
+//   - {{ts_range:seconds}}: a predicate of the timestamp column in a random range of the seconds,
+//     one hour or the range of benchmark-ts-start and benchmark-ts-end by default
+//   - {{metric_col}}: the name of a random metrics column
+//   - {{random_int:min,max}}: a random integer in [min, max]
+func newPlaceholderGenerator(meta *metadata.Metadata, cfg *Config, placeholder, arg string) (func() string, error) {
+	switch placeholder {
+	case _PLACEHOLDER_VIN:
+		if arg != "" {
+			return nil, fmt.Errorf("%s takes no argument", placeholder)
+		}
+		return meta.GetSingleVinGenerator(), nil
+
+	case _PLACEHOLDER_VINS:
+		num := _DEFAULT_PLACEHOLDER_VINS_NUM
+		if arg != "" {
+			var err error
+			if num, err = strconv.Atoi(arg); err != nil || num <= 0 {
+				return nil, fmt.Errorf("the number of vins should be a positive integer")
+			}
+		}
+		return meta.GetRandomVinsGenerator(num), nil
+
+	case _PLACEHOLDER_TS_RANGE:
+		_, _, durationGenerator := getQueryParams(meta, cfg)
+		if arg != "" {
+			seconds, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil || seconds <= 0 {
+				return nil, fmt.Errorf("the range should be a positive number of seconds")
+			}
+			durationGenerator = meta.GetRandomStartEndTSArgGenerator(time.Duration(seconds) * time.Second)
+		}
+		column := meta.Table.ColumnNameTS
+		return func() string {
+			start, end := durationGenerator()
+			return fmt.Sprintf("%[1]s >= %[2]s AND %[1]s < %[3]s", column, start, end)
+		}, nil
+
+	case _PLACEHOLDER_METRIC_COL:
+		if arg != "" {
+			return nil, fmt.Errorf("%s takes no argument", placeholder)
+		}
+		g := meta.GetRandomMetricsColumnGenerator()
+		if g == nil {
+			return nil, fmt.Errorf("the table has no metrics column")
+		}
+		return g, nil
+
+	case _PLACEHOLDER_RANDOM_INT:
+		bounds := strings.Split(arg, ",")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("%s takes the min and max, e.g. {{%s:1,100}}", placeholder, placeholder)
+		}
+		min, err := strconv.ParseInt(strings.TrimSpace(bounds[0]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid min: %v", err)
+		}
+		max, err := strconv.ParseInt(strings.TrimSpace(bounds[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max: %v", err)
+		}
+		if min > max {
+			return nil, fmt.Errorf("min %d is greater than max %d", min, max)
+		}
+		return meta.GetRandomIntGenerator(min, max), nil
+	}
+	return nil, fmt.Errorf("unknown placeholder %s, should be one of %s", placeholder,
+		strings.Join([]string{_PLACEHOLDER_VIN, _PLACEHOLDER_VINS, _PLACEHOLDER_TS_RANGE, _PLACEHOLDER_METRIC_COL, _PLACEHOLDER_RANDOM_INT}, ", "))
 }
 
-func newQueryCustom(statement string, number int) engine.Query {
-	return &queryCustom{statement: statement, number: number}
+// customQueryName returns the name of the i-th custom query, from 0,
+// given by benchmark-custom-query-names, or CUSTOM_QUERY_<i+1> otherwise.
+func (cfg *Config) customQueryName(i int) string {
+	if i < len(cfg.CustomQueryNames) && cfg.CustomQueryNames[i] != "" {
+		return cfg.CustomQueryNames[i]
+	}
+	return _CUSTOM_QUERY_NAME_PREFIX + strconv.Itoa(i+1)
 }
@@ -0,0 +1,111 @@
+package telematics
+
+import (
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util"
+)
+
+var _ = Describe("Custom Query", func() {
+	var meta *metadata.Metadata
+
+	BeforeEach(func() {
+		startAt, _ := time.Parse(util.TIME_FMT, "2016-01-01 00:00:00")
+		endAt, _ := time.Parse(util.TIME_FMT, "2016-01-02 00:00:00")
+		meta = &metadata.Metadata{
+			Table: &metadata.Table{
+				Columns: metadata.Columns{
+					metadata.NewColumn("ts", metadata.ColumnTypeTimestamp),
+					metadata.NewColumn("vin", metadata.ColumnTypeText),
+					metadata.NewColumn("c0", metadata.MetricsTypeFloat4),
+					metadata.NewColumn("c1", metadata.MetricsTypeFloat4),
+				},
+				ColumnNameTS:  "ts",
+				ColumnNameVIN: "vin",
+				VinValues:     []string{"v1", "v2", "v3"},
+			},
+			Cfg: &metadata.Config{StartAt: startAt, EndAt: endAt, Seed: 1},
+		}
+	})
+
+	It("should return the statement without placeholders as it is", func() {
+		q, err := newQueryCustom(meta, &Config{}, "SELECT COUNT(*) FROM t1", "Q")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(q.GetName()).To(Equal("Q"))
+		Expect(q.GetSQL()).To(Equal("SELECT COUNT(*) FROM t1"))
+	})
+
+	It("should substitute the placeholders on every execution", func() {
+		q, err := newQueryCustom(meta, &Config{},
+			"SELECT {{metric_col}} FROM t1 WHERE vin = {{vin}} AND {{ts_range:60}} AND c0 > {{ random_int: 1, 100 }} AND vin IN ({{vins:2}})", "Q")
+		Expect(err).NotTo(HaveOccurred())
+
+		re := regexp.MustCompile(`^SELECT c[01] FROM t1 WHERE vin = 'v[1-3]' ` +
+			`AND ts >= '(2016-01-01 [0-9:]+)' AND ts < '(2016-01-0[12] [0-9:]+)' ` +
+			`AND c0 > ([0-9]+) AND vin IN \('v[1-3]', 'v[1-3]'\)$`)
+		statements := map[string]bool{}
+		for i := 0; i < 20; i++ {
+			sql := q.GetSQL()
+			m := re.FindStringSubmatch(sql)
+			Expect(m).NotTo(BeNil(), sql)
+			start, _ := time.Parse(util.TIME_FMT, m[1])
+			end, _ := time.Parse(util.TIME_FMT, m[2])
+			Expect(end.Sub(start)).To(Equal(time.Minute))
+			Expect(m[3]).To(MatchRegexp(`^([1-9][0-9]?|100)$`))
+			statements[sql] = true
+		}
+		Expect(len(statements)).To(BeNumerically(">", 1))
+	})
+
+	It("should use the range of benchmark-ts-start and benchmark-ts-end by default", func() {
+		q, err := newQueryCustom(meta, &Config{TimestampStart: "2016-01-01 01:00:00", TimestampEnd: "2016-01-01 02:00:00"},
+			"SELECT * FROM t1 WHERE {{ts_range}}", "Q")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(q.GetSQL()).To(Equal("SELECT * FROM t1 WHERE ts >= '2016-01-01 01:00:00' AND ts < '2016-01-01 02:00:00'"))
+	})
+
+	It("should reject invalid placeholders", func() {
+		for _, statement := range []string{
+			"SELECT {{unknown}}",
+			"SELECT {{vin:1}}",
+			"SELECT {{vins:0}}",
+			"SELECT {{ts_range:abc}}",
+			"SELECT {{random_int:1}}",
+			"SELECT {{random_int:10,1}}",
+		} {
+			_, err := newQueryCustom(meta, &Config{}, statement, "Q")
+			Expect(err).To(HaveOccurred(), statement)
+		}
+	})
+
+	It("should name the custom queries", func() {
+		cfg := &Config{CustomQueryNames: []string{"LATEST", ""}}
+		Expect(cfg.customQueryName(0)).To(Equal("LATEST"))
+		Expect(cfg.customQueryName(1)).To(Equal("CUSTOM_QUERY_2"))
+		Expect(cfg.customQueryName(2)).To(Equal("CUSTOM_QUERY_3"))
+	})
+
+	It("should reject duplicate or extra names of custom queries", func() {
+		b := Benchmark{meta: meta, cfg: &Config{
+			RunQueryNames:    []string{_QUERY_NAME_SINGLE_TAG_LATEST_QUERY},
+			CustomQueries:    []string{"SELECT 1"},
+			CustomQueryNames: []string{_QUERY_NAME_SINGLE_TAG_LATEST_QUERY},
+		}}
+		_, err := b.newCustomizedQueries()
+		Expect(err).To(HaveOccurred())
+
+		b.cfg.CustomQueryNames = []string{"A", "B"}
+		_, err = b.newCustomizedQueries()
+		Expect(err).To(HaveOccurred())
+
+		b.cfg.CustomQueryNames = []string{"A"}
+		queries, err := b.newCustomizedQueries()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queries[0].GetName()).To(Equal("A"))
+	})
+})
@@ -2,6 +2,7 @@ package telematics
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
@@ -39,12 +40,48 @@ ON %[3]s.%[7]s = %[8]s.%[7]s AND %[3]s.%[5]s=%[8]s.max_ts
 type queryMultiLatest struct {
 	format             string
 	multiVinsGenerator metadata.MultiVinsGenerator
+	paramsGenerator    metadata.ParamsGenerator
 }
 
 func (q *queryMultiLatest) GetSQL() string {
 	return fmt.Sprintf(q.format, q.multiVinsGenerator())
 }
 
+func (q *queryMultiLatest) GetPreparedSQL() string {
+	return fmt.Sprintf(q.format, preparedParams(1, _LATEST_QUERY_TAG_NUM))
+}
+
+func (q *queryMultiLatest) GetParams() []interface{} {
+	return q.paramsGenerator()
+}
+
+func (q *queryMultiLatest) GetValidatedSQL() (string, []interface{}) {
+	params := q.GetParams()
+	return q.sqlOfParams(params), params
+}
+
+func (q *queryMultiLatest) sqlOfParams(params []interface{}) string {
+	quoted := make([]string, 0, len(params))
+	for _, p := range quoteParams(params) {
+		quoted = append(quoted, p.(string))
+	}
+	return fmt.Sprintf(q.format, strings.Join(quoted, ", "))
+}
+
+// Validate checks there are the latest rows of each of the vins, which may be drawn more than once.
+func (q *queryMultiLatest) Validate(params []interface{}, rows int64) error {
+	vins := make(map[interface{}]struct{}, len(params))
+	for _, p := range params {
+		if p != "" {
+			vins[p] = struct{}{}
+		}
+	}
+	if rows < int64(len(vins)) {
+		return fmt.Errorf("%d rows of %d vins, expected at least one of each vin", rows, len(vins))
+	}
+	return nil
+}
+
 func (q *queryMultiLatest) GetName() string {
 	return _QUERY_NAME_MULTI_TAG_LATEST_QUERY
 }
@@ -65,6 +102,7 @@ func newQueryMultiLatest(meta *metadata.Metadata, cfg *Config) engine.Query {
 				_RELATION_ALIAS_R2,
 			),
 			multiVinsGenerator: meta.GetRandomVinsGenerator(_LATEST_QUERY_TAG_NUM),
+			paramsGenerator:    meta.GetRandomVinsParamsGenerator(_LATEST_QUERY_TAG_NUM),
 		}
 	}
 	return &queryMultiLatest{
@@ -84,5 +122,6 @@ func newQueryMultiLatest(meta *metadata.Metadata, cfg *Config) engine.Query {
 			meta.Table.ColumnTypeExt,
 		),
 		multiVinsGenerator: meta.GetRandomVinsGenerator(_LATEST_QUERY_TAG_NUM),
+		paramsGenerator:    meta.GetRandomVinsParamsGenerator(_LATEST_QUERY_TAG_NUM),
 	}
 }
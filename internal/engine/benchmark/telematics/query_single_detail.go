@@ -6,6 +6,7 @@ import (
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util"
 )
 
 const (
@@ -31,6 +32,12 @@ type querySingleDetail struct {
 	format             string
 	singleVinGenerator metadata.SingleVinGenerator
 	durationGenerator  metadata.DurationGenerator
+
+	vinParamsGenerator      metadata.ParamsGenerator
+	durationParamsGenerator metadata.ParamsGenerator
+
+	// countTimestamps counts the timestamps of a vin in a range, nil if they are unknown
+	countTimestamps func(start, end time.Time) int64
 }
 
 func (q *querySingleDetail) GetSQL() string {
@@ -38,6 +45,42 @@ func (q *querySingleDetail) GetSQL() string {
 	return fmt.Sprintf(q.format, q.singleVinGenerator(), start, end)
 }
 
+func (q *querySingleDetail) GetPreparedSQL() string {
+	return fmt.Sprintf(q.format, "$1", "$2", "$3")
+}
+
+func (q *querySingleDetail) GetParams() []interface{} {
+	return append(q.vinParamsGenerator(), q.durationParamsGenerator()...)
+}
+
+func (q *querySingleDetail) GetValidatedSQL() (string, []interface{}) {
+	params := q.GetParams()
+	return q.sqlOfParams(params), params
+}
+
+func (q *querySingleDetail) sqlOfParams(params []interface{}) string {
+	return fmt.Sprintf(q.format, quoteParams(params)...)
+}
+
+// Validate checks there is a row of the vin at each timestamp of the range, if the timestamps are known.
+func (q *querySingleDetail) Validate(params []interface{}, rows int64) error {
+	if params[0] == "" || q.countTimestamps == nil {
+		return nil
+	}
+	start, err := time.Parse(util.TIME_FMT, fmt.Sprint(params[1]))
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse(util.TIME_FMT, fmt.Sprint(params[2]))
+	if err != nil {
+		return nil
+	}
+	if expected := q.countTimestamps(start, end); rows < expected {
+		return fmt.Errorf("%d rows of vin %v from %s to %s, expected at least %d", rows, params[0], params[1], params[2], expected)
+	}
+	return nil
+}
+
 func (q *querySingleDetail) GetName() string {
 	return _QUERY_NAME_SINGLE_TAG_DETAIL_QUERY
 }
@@ -45,6 +88,10 @@ func (q *querySingleDetail) GetName() string {
 func newQuerySingleDetail(meta *metadata.Metadata, cfg *Config) engine.Query {
 	tableIdentifier := meta.Table.Identifier()
 	simpleMetricsCount, jsonMetricsCount, durationGenerator := getQueryParams(meta, cfg)
+	var countTimestamps func(start, end time.Time) int64
+	if cfg != nil && cfg.timestampsGenerated {
+		countTimestamps = meta.CountTimestamps
+	}
 	if meta.Table.JSONMetricsCount == 0 {
 		return &querySingleDetail{
 			format: fmt.Sprintf(_SINGLE_TAG_DETAIL_QUERY,
@@ -53,8 +100,11 @@ func newQuerySingleDetail(meta *metadata.Metadata, cfg *Config) engine.Query {
 				meta.Table.ColumnNameVIN,
 				meta.Table.ColumnNameTS,
 			),
-			singleVinGenerator: meta.GetSingleVinGenerator(),
-			durationGenerator:  durationGenerator,
+			singleVinGenerator:      meta.GetSingleVinGenerator(),
+			durationGenerator:       durationGenerator,
+			vinParamsGenerator:      meta.GetRandomVinsParamsGenerator(1),
+			durationParamsGenerator: getDurationParamsGenerator(meta, cfg),
+			countTimestamps:         countTimestamps,
 		}
 	}
 	return &querySingleDetail{
@@ -70,7 +120,10 @@ func newQuerySingleDetail(meta *metadata.Metadata, cfg *Config) engine.Query {
 			meta.Table.ColumnNameTS,
 			meta.Table.ColumnTypeExt,
 		),
-		singleVinGenerator: meta.GetSingleVinGenerator(),
-		durationGenerator:  durationGenerator,
+		singleVinGenerator:      meta.GetSingleVinGenerator(),
+		durationGenerator:       durationGenerator,
+		vinParamsGenerator:      meta.GetRandomVinsParamsGenerator(1),
+		durationParamsGenerator: getDurationParamsGenerator(meta, cfg),
+		countTimestamps:         countTimestamps,
 	}
 }
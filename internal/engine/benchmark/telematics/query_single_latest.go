@@ -26,12 +26,41 @@ ORDER BY %[8]s DESC LIMIT 1`
 type querySingleLatest struct {
 	format             string
 	singleVinGenerator metadata.SingleVinGenerator
+	paramsGenerator    metadata.ParamsGenerator
 }
 
 func (q *querySingleLatest) GetSQL() string {
 	return fmt.Sprintf(q.format, q.singleVinGenerator())
 }
 
+func (q *querySingleLatest) GetPreparedSQL() string {
+	return fmt.Sprintf(q.format, preparedParams(1, 1))
+}
+
+func (q *querySingleLatest) GetParams() []interface{} {
+	return q.paramsGenerator()
+}
+
+func (q *querySingleLatest) GetValidatedSQL() (string, []interface{}) {
+	params := q.GetParams()
+	return q.sqlOfParams(params), params
+}
+
+func (q *querySingleLatest) sqlOfParams(params []interface{}) string {
+	return fmt.Sprintf(q.format, quoteParams(params)...)
+}
+
+// Validate checks there is the latest row of the vin, unless the vins of the table are unknown.
+func (q *querySingleLatest) Validate(params []interface{}, rows int64) error {
+	if params[0] == "" {
+		return nil
+	}
+	if rows != 1 {
+		return fmt.Errorf("%d rows of vin %v, expected 1", rows, params[0])
+	}
+	return nil
+}
+
 func (q *querySingleLatest) GetName() string {
 	return _QUERY_NAME_SINGLE_TAG_LATEST_QUERY
 }
@@ -49,6 +78,7 @@ func newQuerySingleLatest(meta *metadata.Metadata, cfg *Config) engine.Query {
 				meta.Table.ColumnNameTS,
 			),
 			singleVinGenerator: meta.GetSingleVinGenerator(),
+			paramsGenerator:    meta.GetRandomVinsParamsGenerator(1),
 		}
 	}
 	return &querySingleLatest{
@@ -65,5 +95,6 @@ func newQuerySingleLatest(meta *metadata.Metadata, cfg *Config) engine.Query {
 			meta.Table.ColumnTypeExt,
 		),
 		singleVinGenerator: meta.GetSingleVinGenerator(),
+		paramsGenerator:    meta.GetRandomVinsParamsGenerator(1),
 	}
 }
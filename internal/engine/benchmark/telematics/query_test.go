@@ -0,0 +1,108 @@
+package telematics
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util"
+)
+
+var _ = Describe("Prepared Query", func() {
+	var meta *metadata.Metadata
+
+	BeforeEach(func() {
+		startAt, _ := time.Parse(util.TIME_FMT, "2016-01-01 00:00:00")
+		endAt, _ := time.Parse(util.TIME_FMT, "2016-01-02 00:00:00")
+		gcfg := engine.GlobalConfig{
+			SchemaName:            "public",
+			TableName:             "xx",
+			TotalMetricsCount:     2,
+			TimestampStepInSecond: 1,
+			MetricsType:           metadata.MetricsTypeFloat4,
+			StorageType:           metadata.StorageMars2,
+			StartAt:               startAt,
+			EndAt:                 endAt,
+			TagNum:                25000,
+		}
+		var err error
+		meta, err = metadata.New(gcfg.NewMetadataConfig())
+		Expect(err).NotTo(HaveOccurred())
+		meta.Table.VinValues = []string{"v1", "v2"}
+	})
+
+	It("should prepare the single tag latest query with the vin as a parameter", func() {
+		q := newQuerySingleLatest(meta, nil).(engine.PreparedQuery)
+		Expect(q.GetPreparedSQL()).To(ContainSubstring("WHERE vin = $1\n"))
+		Expect(q.GetParams()).To(ConsistOf(BeElementOf("v1", "v2")))
+	})
+
+	It("should prepare the multi tag latest query with the vins as parameters", func() {
+		q := newQueryMultiLatest(meta, nil).(engine.PreparedQuery)
+		Expect(q.GetPreparedSQL()).To(ContainSubstring("WHERE vin IN ( $1, $2, $3, $4, $5, $6, $7, $8, $9, $10 )"))
+		Expect(q.GetParams()).To(HaveLen(_LATEST_QUERY_TAG_NUM))
+	})
+
+	It("should prepare the single tag detail query with the vin and the duration as parameters", func() {
+		q := newQuerySingleDetail(meta, &Config{
+			SimpleMetricsCount: 2,
+			TimestampStart:     "2016-01-01 00:10:00",
+			TimestampEnd:       "2016-01-01 00:11:00",
+		}).(engine.PreparedQuery)
+		Expect(q.GetPreparedSQL()).To(HaveSuffix("WHERE vin = $1\nAND ts >= $2\nAND ts < $3"))
+		params := q.GetParams()
+		Expect(params).To(HaveLen(3))
+		Expect(params[0]).To(BeElementOf("v1", "v2"))
+		Expect(params[1:]).To(Equal([]interface{}{"2016-01-01 00:10:00", "2016-01-01 00:11:00"}))
+	})
+
+	It("should draw a random duration of an hour by default", func() {
+		q := newQuerySingleDetail(meta, nil).(engine.PreparedQuery)
+		params := q.GetParams()
+		start, err := time.Parse(util.TIME_FMT, params[1].(string))
+		Expect(err).NotTo(HaveOccurred())
+		end, err := time.Parse(util.TIME_FMT, params[2].(string))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(end.Sub(start)).To(Equal(_DETAIL_QUERY_DURATION))
+	})
+
+	It("should validate the rows of the latest queries", func() {
+		single := newQuerySingleLatest(meta, nil).(*querySingleLatest)
+		Expect(single.sqlOfParams([]interface{}{"v1"})).To(ContainSubstring("WHERE vin = 'v1'\n"))
+		Expect(single.Validate([]interface{}{"v1"}, 1)).To(Succeed())
+		Expect(single.Validate([]interface{}{"v1"}, 0)).NotTo(Succeed())
+		Expect(single.Validate([]interface{}{""}, 0)).To(Succeed())
+
+		multi := newQueryMultiLatest(meta, nil).(*queryMultiLatest)
+		Expect(multi.sqlOfParams([]interface{}{"v1", "v2"})).To(ContainSubstring("WHERE vin IN ( 'v1', 'v2' )"))
+		Expect(multi.Validate([]interface{}{"v1", "v2", "v1"}, 2)).To(Succeed())
+		Expect(multi.Validate([]interface{}{"v1", "v2", "v1"}, 1)).NotTo(Succeed())
+	})
+
+	It("should validate the SQL text drawn by the parameters returned along with it", func() {
+		q := newQuerySingleDetail(meta, &Config{
+			SimpleMetricsCount: 2,
+			TimestampStart:     "2016-01-01 00:10:00",
+			TimestampEnd:       "2016-01-01 00:11:00",
+		}).(engine.ValidatedQuery)
+		sql, params := q.GetValidatedSQL()
+		Expect(params).To(HaveLen(3))
+		Expect(sql).To(HaveSuffix(fmt.Sprintf("WHERE vin = '%s'\nAND ts >= '2016-01-01 00:10:00'\nAND ts < '2016-01-01 00:11:00'", params[0])))
+	})
+
+	It("should validate the rows of the detail query if the timestamps are generated", func() {
+		params := []interface{}{"v1", "2016-01-01 00:10:00", "2016-01-01 00:11:00"}
+		q := newQuerySingleDetail(meta, &Config{SimpleMetricsCount: 2}).(*querySingleDetail)
+		Expect(q.sqlOfParams(params)).To(HaveSuffix("WHERE vin = 'v1'\nAND ts >= '2016-01-01 00:10:00'\nAND ts < '2016-01-01 00:11:00'"))
+		Expect(q.Validate(params, 0)).To(Succeed())
+
+		q = newQuerySingleDetail(meta, &Config{SimpleMetricsCount: 2, timestampsGenerated: true}).(*querySingleDetail)
+		Expect(q.Validate(params, 60)).To(Succeed())
+		Expect(q.Validate(params, 59)).NotTo(Succeed())
+		Expect(q.Validate([]interface{}{"", "2016-01-01 00:10:00", "2016-01-01 00:11:00"}, 0)).To(Succeed())
+	})
+})
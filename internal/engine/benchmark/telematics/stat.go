@@ -168,7 +168,7 @@ func (s *Stat) GetFormattedSummary() string {
 			if siIndex >= len(s.subStats) {
 				break
 			}
-			queryName := _CUSTOM_QUERY_NAME_PREFIX + strconv.Itoa(i+1)
+			queryName := s.config.customQueryName(i)
 			queryInfo := QueryInfo{
 				QueryName:   queryName,
 				CustomQuery: s.config.CustomQueries[i],
@@ -3,23 +3,38 @@ package telematics
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
+	generator "github.com/ymatrix-data/mxbench/internal/engine/generator/telematics"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
 )
 
 type Config struct {
-	Parallel           []int    `mapstructure:"benchmark-parallel"`
-	RunQueryNames      []string `mapstructure:"benchmark-run-query-names"`
-	CombinationQueries string   `mapstructure:"benchmark-combination-queries"`
-	CustomQueries      []string `mapstructure:"benchmark-custom-queries"`
-	RunTimes           int64    `mapstructure:"benchmark-run-times"`
-	RunTimeInSecond    uint64   `mapstructure:"benchmark-runtime-in-second"`
-	ProgressFormat     string   `mapstructure:"benchmark-progress-format"`
+	Parallel             []int    `mapstructure:"benchmark-parallel"`
+	RunQueryNames        []string `mapstructure:"benchmark-run-query-names"`
+	CombinationQueries   string   `mapstructure:"benchmark-combination-queries"`
+	CustomQueries        []string `mapstructure:"benchmark-custom-queries"`
+	CustomQueryNames     []string `mapstructure:"benchmark-custom-query-names"`
+	RunTimes             int64    `mapstructure:"benchmark-run-times"`
+	RunTimeInSecond      uint64   `mapstructure:"benchmark-runtime-in-second"`
+	ProgressFormat       string   `mapstructure:"benchmark-progress-format"`
+	TargetQPS            float64  `mapstructure:"benchmark-target-qps"`
+	Arrival              string   `mapstructure:"benchmark-arrival-distribution"`
+	PreparedStatements   bool     `mapstructure:"benchmark-prepared-statements"`
+	ValidateResults      bool     `mapstructure:"benchmark-validate-results"`
+	FailOnInvalid        bool     `mapstructure:"benchmark-fail-on-invalid-results"`
+	MixedWorkload        bool     `mapstructure:"benchmark-mixed-workload"`
+	MixedWeights         []string `mapstructure:"benchmark-mixed-weights"`
+	TimeoutInMillisecond uint64   `mapstructure:"benchmark-query-timeout-in-millisecond"`
+	Retries              int      `mapstructure:"benchmark-retries"`
+	ContinueOnError      bool     `mapstructure:"benchmark-continue-on-error"`
 
 	// hidden
 	TimestampStart     string `mapstructure:"benchmark-ts-start"`
@@ -28,6 +43,10 @@ type Config struct {
 	JSONMetricsCount   int64  `mapstructure:"benchmark-json-metrics-count"`
 
 	NumOfParsedCombinationQueries int
+
+	// timestampsGenerated tells the data is generated by time range before querying and in order,
+	// so that the timestamps of each vin are known
+	timestampsGenerated bool
 }
 
 type Benchmark struct {
@@ -57,6 +76,7 @@ func (b *Benchmark) Run(writerFinCh <-chan error, cfg engine.Config, meta *metad
 	b.meta = meta
 	b.execFunc = execFunc
 	b.writerFinCh = writerFinCh
+	b.cfg.timestampsGenerated = timestampsGenerated(cfg)
 	// We are assuming
 	// 1. the first ${len(b.cfg.RunQueryNames)} are named queries
 	queries := b.newNamedQueries()
@@ -64,8 +84,21 @@ func (b *Benchmark) Run(writerFinCh <-chan error, cfg engine.Config, meta *metad
 	queries = append(queries, b.newCombinationQueries()...)
 	// 3. the last ${len(b.cfg.CustomQueries)} are custom queries
 	// The order and the numbers are important in Report Presentation of Benchmark Stat.
-	return b.exec(
-		append(queries, b.newCustomizedQueries()...))
+	customQueries, err := b.newCustomizedQueries()
+	if err != nil {
+		return err
+	}
+	return b.exec(append(queries, customQueries...))
+}
+
+// timestampsGenerated tells each vin has a row at each timestamp of the time range when querying,
+// which is not the case if the data is still being loaded, or some rows are moved to earlier timestamps.
+func timestampsGenerated(cfg engine.Config) bool {
+	if cfg.GeneratorCfg.Plugin != "telematics" || cfg.GlobalCfg.IsRealtimeMode || cfg.GlobalCfg.SimultaneousLoadAndQuery {
+		return false
+	}
+	gCfg, ok := cfg.GeneratorCfg.PluginConfig.(*generator.Config)
+	return ok && gCfg.DisorderRatio == 0
 }
 
 func (b *Benchmark) Close() error {
@@ -99,11 +132,36 @@ func (b *Benchmark) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	p.StringSliceVar(&sCfg.CustomQueries, "benchmark-custom-queries",
 		nil,
 		"custom query SQLs, use \",\" to separate query statements.\n"+
-			"For example, [\"SELECT COUNT(*) from t1\", \"SELECT MAX(ts) from t1\"]")
+			"For example, [\"SELECT COUNT(*) from t1\", \"SELECT MAX(ts) from t1\"]\n"+
+			"Placeholders are substituted on every execution: {{vin}}, {{vins:10}}, {{ts_range:3600}}, {{metric_col}}, {{random_int:1,100}}")
+	p.StringSliceVar(&sCfg.CustomQueryNames, "benchmark-custom-query-names",
+		nil,
+		"names of the custom queries in order, use \",\" to separate names.\n"+
+			"A custom query without a name is named CUSTOM_QUERY_<n>, where n is its number from 1")
 	p.StringVar(&sCfg.CombinationQueries, "benchmark-combination-queries", "", "Queries by combing expressions")
 	p.Int64Var(&sCfg.RunTimes, "benchmark-run-times", 0, "the times of queries with set parallels")
 	p.Uint64Var(&sCfg.RunTimeInSecond, "benchmark-runtime-in-second", 60, "total runtime of queries, only take effect when benchmark-run-times is 0")
 	p.StringVar(&sCfg.ProgressFormat, "benchmark-progress-format", "list", "progress format. support \"list\", \"json\"")
+	p.Float64Var(&sCfg.TargetQPS, "benchmark-target-qps", 0, "queries issued per second in open-loop mode, regardless of how fast they return.\n"+
+		"Latencies are measured from the scheduled start. 0 means closed-loop, running queries back to back")
+	p.StringVar(&sCfg.Arrival, "benchmark-arrival-distribution", engine.ArrivalUniform, "inter-arrival distribution of open-loop mode, \"uniform\" or \"poisson\"")
+	p.BoolVar(&sCfg.PreparedStatements, "benchmark-prepared-statements", false, "prepare each query once per connection and execute it with bound parameters by the extended protocol.\n"+
+		"Only the telematics queries named in benchmark-run-query-names can be prepared, the others are still executed by their SQL text")
+	p.BoolVar(&sCfg.ValidateResults, "benchmark-validate-results", false, "fetch the results of the telematics queries named in benchmark-run-query-names and validate them,\n"+
+		"e.g. the latest queries return a row of each vin, and the detail query returns a row at each timestamp of the range.\n"+
+		"Invalid results are counted in the report")
+	p.BoolVar(&sCfg.FailOnInvalid, "benchmark-fail-on-invalid-results", false, "fail the benchmark if any result is invalid, only take effect when benchmark-validate-results is true")
+	p.BoolVar(&sCfg.MixedWorkload, "benchmark-mixed-workload", false, "run all the queries concurrently by one pool of connections with each of the parallels,\n"+
+		"each execution picking a query by its weight in benchmark-mixed-weights, instead of running the queries one after another.\n"+
+		"The stats are still reported for each query")
+	p.StringSliceVar(&sCfg.MixedWeights, "benchmark-mixed-weights", nil, "weights of the queries of the mixed workload, as name=weight, use \",\" to separate them.\n"+
+		"For example, [\"SINGLE_TAG_LATEST_QUERY=70\", \"SINGLE_TAG_DETAIL_QUERY=25\", \"CUSTOM_QUERY_1=5\"].\n"+
+		"All the queries are weighted equally by default, otherwise each of them should be given a weight")
+	p.Uint64Var(&sCfg.TimeoutInMillisecond, "benchmark-query-timeout-in-millisecond", 0, "cancel an execution of a query running longer than it by statement_timeout, 0 means no timeout")
+	p.IntVar(&sCfg.Retries, "benchmark-retries", 0, "the times to retry an execution failed by a serialization failure, a deadlock or a lost connection,\n"+
+		"reconnecting first if the connection is lost")
+	p.BoolVar(&sCfg.ContinueOnError, "benchmark-continue-on-error", false, "keep executing the queries on a connection after an execution fails, reconnecting if the connection is lost,\n"+
+		"instead of stopping the connection. Either way the errors are counted by class in the report")
 
 	// hidden
 	p.StringVar(&sCfg.TimestampStart, "benchmark-ts-start", "", "the start timestamp of query")
@@ -125,14 +183,33 @@ func (b *Benchmark) IsNil() bool {
 
 func (b *Benchmark) exec(queries []engine.Query) error {
 	opt := engine.ExecBenchOption{
-		RunTimes: b.cfg.RunTimes,
-		Duration: time.Second * time.Duration(b.cfg.RunTimeInSecond)}
+		RunTimes:  b.cfg.RunTimes,
+		Duration:  time.Second * time.Duration(b.cfg.RunTimeInSecond),
+		TargetQPS: b.cfg.TargetQPS,
+		Arrival:   b.cfg.Arrival,
+		Seed:      b.gcfg.GlobalCfg.GetSeed(),
+		Prepared:  b.cfg.PreparedStatements,
+
+		Validate:      b.cfg.ValidateResults,
+		FailOnInvalid: b.cfg.FailOnInvalid,
+
+		Timeout:         time.Millisecond * time.Duration(b.cfg.TimeoutInMillisecond),
+		Retries:         b.cfg.Retries,
+		ContinueOnError: b.cfg.ContinueOnError,
+	}
 
 	queriesNum := len(queries)
 	if queriesNum == 0 || len(b.cfg.Parallel) == 0 {
 		log.Info("No queries need to be run, exiting benchmark")
 		return nil
 	}
+	var mixed *engine.MixedQuery
+	if b.cfg.MixedWorkload {
+		var err error
+		if mixed, err = b.cfg.newMixedQuery(queries); err != nil {
+			return err
+		}
+	}
 	executedRuns := 0
 	for {
 		// if the writer has finished,
@@ -151,6 +228,12 @@ func (b *Benchmark) exec(queries []engine.Query) error {
 		b.stat.Reset()
 		for _, p := range b.cfg.Parallel {
 			opt.Parallel = p
+			if mixed != nil {
+				if err := b.execMixed(opt, mixed); err != nil {
+					return err
+				}
+				continue
+			}
 			log.Info("Begin to exec queries with parallel %d", p)
 			for i, q := range queries {
 				select {
@@ -178,6 +261,69 @@ func (b *Benchmark) exec(queries []engine.Query) error {
 	}
 }
 
+// execMixed executes the queries concurrently as a mixed workload with the parallel of the option,
+// adding the stat of each query as if they were executed one after another.
+func (b *Benchmark) execMixed(opt engine.ExecBenchOption, mixed *engine.MixedQuery) error {
+	select {
+	case <-b.ctx.Done():
+		log.Info("Benchmark canceled before executing mixed queries")
+		return nil
+	default:
+	}
+	log.Info("Begin to exec %d queries mixed: %s", len(mixed.Queries), mixed.GetName())
+	mixedStat := engine.NewMixedBenchStat(opt, mixed)
+	for _, ss := range mixedStat.GetSubStats() {
+		b.stat.AddSubStat(ss)
+	}
+	err := b.execFunc(b.ctx, mixed, mixedStat)
+
+	log.Info("Mixed queries done")
+	fmt.Printf("Sub Stat for mixed queries done\n%s\n", mixedStat.GetSummary())
+	return err
+}
+
+// newMixedQuery returns the mixed workload of the queries, weighted by benchmark-mixed-weights
+func (cfg *Config) newMixedQuery(queries []engine.Query) (*engine.MixedQuery, error) {
+	weights := make(map[string]float64, len(cfg.MixedWeights))
+	for _, w := range cfg.MixedWeights {
+		i := strings.LastIndex(w, "=")
+		if i < 0 {
+			return nil, mxerror.CommonErrorf("invalid weight %s of benchmark-mixed-weights, should be name=weight", w)
+		}
+		name := strings.TrimSpace(w[:i])
+		weight, err := strconv.ParseFloat(strings.TrimSpace(w[i+1:]), 64)
+		if err != nil || weight < 0 {
+			return nil, mxerror.CommonErrorf("invalid weight %s of benchmark-mixed-weights, should be a non-negative number", w)
+		}
+		if _, ok := weights[name]; ok {
+			return nil, mxerror.CommonErrorf("duplicate weight of query %s in benchmark-mixed-weights", name)
+		}
+		weights[name] = weight
+	}
+
+	mixed := &engine.MixedQuery{Queries: queries}
+	var total float64
+	for _, q := range queries {
+		weight := 1.0
+		if len(weights) > 0 {
+			var ok bool
+			if weight, ok = weights[q.GetName()]; !ok {
+				return nil, mxerror.CommonErrorf("query %s is not weighted in benchmark-mixed-weights", q.GetName())
+			}
+			delete(weights, q.GetName())
+		}
+		mixed.Weights = append(mixed.Weights, weight)
+		total += weight
+	}
+	for name := range weights {
+		return nil, mxerror.CommonErrorf("query %s weighted in benchmark-mixed-weights is not run", name)
+	}
+	if total <= 0 {
+		return nil, mxerror.CommonErrorf("none of the queries is weighted above 0 in benchmark-mixed-weights")
+	}
+	return mixed, nil
+}
+
 func (b *Benchmark) newNamedQueries() []engine.Query {
 	queries := make([]engine.Query, 0)
 	for _, queryName := range b.cfg.RunQueryNames {
@@ -203,10 +349,27 @@ func (b *Benchmark) newCombinationQueries() []engine.Query {
 	return transformCombinationQueries(queries, b.meta, b.cfg)
 }
 
-func (b *Benchmark) newCustomizedQueries() []engine.Query {
+func (b *Benchmark) newCustomizedQueries() ([]engine.Query, error) {
+	if len(b.cfg.CustomQueryNames) > len(b.cfg.CustomQueries) {
+		return nil, mxerror.CommonErrorf("%d names are given by benchmark-custom-query-names to %d custom queries",
+			len(b.cfg.CustomQueryNames), len(b.cfg.CustomQueries))
+	}
+	names := make(map[string]bool, len(b.cfg.RunQueryNames)+len(b.cfg.CustomQueries))
+	for _, name := range b.cfg.RunQueryNames {
+		names[name] = true
+	}
 	queries := make([]engine.Query, 0, len(b.cfg.CustomQueries))
-	for i, query := range b.cfg.CustomQueries {
-		queries = append(queries, newQueryCustom(query, i+1))
+	for i, statement := range b.cfg.CustomQueries {
+		name := b.cfg.customQueryName(i)
+		if names[name] {
+			return nil, mxerror.CommonErrorf("duplicate query name %s of custom query %d", name, i+1)
+		}
+		names[name] = true
+		q, err := newQueryCustom(b.meta, b.cfg, statement, name)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
 	}
-	return queries
+	return queries, nil
 }
@@ -0,0 +1,81 @@
+package telematics
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	generator "github.com/ymatrix-data/mxbench/internal/engine/generator/telematics"
+)
+
+var _ = Describe("Mixed Workload", func() {
+	var queries []engine.Query
+
+	BeforeEach(func() {
+		queries = []engine.Query{
+			&queryCustom{name: "SINGLE_TAG_LATEST_QUERY", literals: []string{"SELECT 1"}},
+			&queryCustom{name: "SINGLE_TAG_DETAIL_QUERY", literals: []string{"SELECT 2"}},
+			&queryCustom{name: "CUSTOM_QUERY_1", literals: []string{"SELECT 3"}},
+		}
+	})
+
+	It("should weight the queries equally by default", func() {
+		mixed, err := (&Config{}).newMixedQuery(queries)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mixed.Queries).To(Equal(queries))
+		Expect(mixed.Weights).To(Equal([]float64{1, 1, 1}))
+	})
+
+	It("should weight the queries by benchmark-mixed-weights", func() {
+		cfg := &Config{MixedWeights: []string{"CUSTOM_QUERY_1=5", "SINGLE_TAG_LATEST_QUERY = 70", "SINGLE_TAG_DETAIL_QUERY=25"}}
+		mixed, err := cfg.newMixedQuery(queries)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mixed.Weights).To(Equal([]float64{70, 25, 5}))
+	})
+
+	It("should reject invalid weights", func() {
+		for msg, weights := range map[string][]string{
+			"should be name=weight":                   {"SINGLE_TAG_LATEST_QUERY"},
+			"should be a non-negative number":         {"SINGLE_TAG_LATEST_QUERY=-1"},
+			"duplicate weight":                        {"SINGLE_TAG_LATEST_QUERY=1", "SINGLE_TAG_LATEST_QUERY=2"},
+			"SINGLE_TAG_DETAIL_QUERY is not weighted": {"SINGLE_TAG_LATEST_QUERY=1", "CUSTOM_QUERY_1=1"},
+			"MULTI_TAG_LATEST_QUERY weighted in benchmark-mixed-weights is not run": {
+				"SINGLE_TAG_LATEST_QUERY=1", "SINGLE_TAG_DETAIL_QUERY=1", "CUSTOM_QUERY_1=1", "MULTI_TAG_LATEST_QUERY=1"},
+			"none of the queries": {"SINGLE_TAG_LATEST_QUERY=0", "SINGLE_TAG_DETAIL_QUERY=0", "CUSTOM_QUERY_1=0"},
+		} {
+			_, err := (&Config{MixedWeights: weights}).newMixedQuery(queries)
+			Expect(err).To(MatchError(ContainSubstring(msg)))
+		}
+	})
+})
+
+var _ = Describe("Timestamps generated", func() {
+	var cfg engine.Config
+
+	BeforeEach(func() {
+		cfg = engine.Config{}
+		cfg.GeneratorCfg.Plugin = "telematics"
+		cfg.GeneratorCfg.PluginConfig = &generator.Config{}
+	})
+
+	It("should know the timestamps of the data generated in order before querying", func() {
+		Expect(timestampsGenerated(cfg)).To(BeTrue())
+	})
+
+	It("should not know the timestamps otherwise", func() {
+		cfg.GlobalCfg.SimultaneousLoadAndQuery = true
+		Expect(timestampsGenerated(cfg)).To(BeFalse())
+
+		cfg.GlobalCfg.SimultaneousLoadAndQuery = false
+		cfg.GeneratorCfg.PluginConfig = &generator.Config{DisorderRatio: 1}
+		Expect(timestampsGenerated(cfg)).To(BeFalse())
+
+		cfg.GeneratorCfg.PluginConfig = &generator.Config{}
+		cfg.GlobalCfg.IsRealtimeMode = true
+		Expect(timestampsGenerated(cfg)).To(BeFalse())
+
+		cfg.GlobalCfg.IsRealtimeMode = false
+		cfg.GeneratorCfg.Plugin = "file"
+		Expect(timestampsGenerated(cfg)).To(BeFalse())
+	})
+})
@@ -33,13 +33,17 @@ type NewGeneratorFunc func(cfg GeneratorConfig) IGenerator
 type NewWriterFunc func(cfg WriterConfig) IWriter
 type NewBenchmarkFunc func(cfg BenchmarkConfig) IBenchmark
 
+// SaveResultFunc saves the config and statistics of a run into results-db,
+// and returns the id of the run.
+type SaveResultFunc func(e *Engine) (string, error)
+
 type GlobalConfig struct {
 	SchemaName               string               `mapstructure:"schema-name"`
 	TableName                string               `mapstructure:"table-name"`
 	TagNum                   int64                `mapstructure:"tag-num"`
 	TimestampStart           string               `mapstructure:"ts-start"`
 	TimestampEnd             string               `mapstructure:"ts-end"`
-	PartitionIntervalInHour  int64                `mapstructure:"partition-inteval-in-hour"`
+	PartitionIntervalInHour  int64                `mapstructure:"partition-interval-in-hour"`
 	IsRealtimeMode           bool                 `mapstructure:"realtime"`
 	MetricsType              metadata.MetricsType `mapstructure:"metrics-type"`
 	TotalMetricsCount        int64                `mapstructure:"total-metrics-count"`
@@ -48,11 +52,15 @@ type GlobalConfig struct {
 	MetricsDescriptions      string               `mapstructure:"metrics-descriptions"`
 	Workspace                string               `mapstructure:"workspace"`
 	DDLFilePath              string               `mapstructure:"ddl-file-path"`
+	TSColumnName             string               `mapstructure:"ts-column-name"`
+	VINColumnName            string               `mapstructure:"vin-column-name"`
 	SimultaneousLoadAndQuery bool                 `mapstructure:"simultaneous-loading-and-query"`
 	PreBenchmarkQuery        string               `mapstructure:"pre-benchmark-query"`
 	SkipSetGUCs              bool                 `mapstructure:"skip-set-gucs"`
 	StorageType              string               `mapstructure:"storage-type"`
 	Degrade                  bool                 `mapstructure:"degrade"`
+	Tables                   string               `mapstructure:"tables"`
+	Seed                     int64                `mapstructure:"seed"`
 
 	// misc
 	Command       string
@@ -68,6 +76,21 @@ type GlobalConfig struct {
 
 	ReportPath   string       `mapstructure:"report-path"`
 	ReportFormat ReportFormat `mapstructure:"report-format"`
+
+	ResultsDB     string `mapstructure:"results-db"`
+	CompareRunIDs []string
+
+	MetricsAddr string `mapstructure:"metrics-addr"`
+
+	tables []TableConfig
+	// seed is Seed, or picked by the clock if Seed is 0
+	seed int64
+	// SharedTargets are the identifiers of all the tables in a multi-table run,
+	// which the writers sharing one mxgate write into.
+	SharedTargets []string
+	// TableExists tells the table is created by a previous phase of a scenario,
+	// so that no DDL is executed.
+	TableExists bool
 }
 
 func (cfg *GlobalConfig) NewMetadataConfig() *metadata.Config {
@@ -84,6 +107,9 @@ func (cfg *GlobalConfig) NewMetadataConfig() *metadata.Config {
 		TimestampStepInSecond:   cfg.TimestampStepInSecond,
 		StorageType:             cfg.StorageType,
 		IsDDLFromFile:           cfg.DDLFilePath != "",
+		ColumnNameTS:            cfg.TSColumnName,
+		ColumnNameVIN:           cfg.VINColumnName,
+		Seed:                    cfg.seed,
 	}
 }
 
@@ -102,7 +128,32 @@ func (cfg *GlobalConfig) DoAfterInit() error {
 	if cfg.StartAt.After(cfg.EndAt) {
 		return mxerror.CommonErrorf("ts-start(%s) is after ts-end(%s)", cfg.TimestampStart, cfg.TimestampEnd)
 	}
-	return err
+
+	cfg.tables, err = parseTables(cfg.Tables)
+	if err != nil {
+		return err
+	}
+	if len(cfg.tables) > 0 && cfg.DDLFilePath != "" {
+		return mxerror.CommonError("ddl-file-path should be given in tables for a multi-table run")
+	}
+	if cfg.DDLFilePath == "" && len(cfg.tables) == 0 && (cfg.TSColumnName != "" || cfg.VINColumnName != "") {
+		return mxerror.CommonError("ts-column-name and vin-column-name only map the columns of the table of ddl-file-path")
+	}
+
+	cfg.seed = cfg.Seed
+	if cfg.seed == 0 {
+		cfg.seed = time.Now().UnixNano()
+	}
+	return nil
+}
+
+// GetSeed returns the seed of the generated data and the parameters of benchmark queries,
+// which is Seed before DoAfterInit.
+func (cfg *GlobalConfig) GetSeed() int64 {
+	if cfg.seed == 0 {
+		return cfg.Seed
+	}
+	return cfg.seed
 }
 
 type Config struct {
@@ -112,12 +163,22 @@ type Config struct {
 	WriterCfg    WriterConfig      `mapstructure:"writer"`
 	BenchmarkCfg BenchmarkConfig   `mapstructure:"benchmark"`
 
+	ThresholdsCfg ThresholdsConfig `mapstructure:"thresholds"`
+
+	// the [sweep] section of the config file, run by command sweep
+	SweepCfg SweepConfig `mapstructure:"-"`
+	// the [[scenario]] phases of the config file, run by command scenario
+	ScenarioCfg ScenarioConfig `mapstructure:"-"`
+
 	// print config usage
 	Usage func()
+	// render the config in toml, the same as command config prints
+	RenderConfig func() (string, error)
 
 	NewGeneratorFunc NewGeneratorFunc
 	NewWriterFunc    NewWriterFunc
 	NewBenchmarkFunc NewBenchmarkFunc
+	SaveResultFunc   SaveResultFunc
 }
 
 func NewConfig() (*Config, error) {
@@ -170,10 +231,22 @@ func (cfg *Config) GlobalFlagSet() *pflag.FlagSet {
 		"whether to load data and run benchmark queries simultaneously")
 	set.StringVar(&cfg.GlobalCfg.Workspace, "workspace", "/tmp/mxbench", "the workspace of mxbench, directory to dump or backup")
 	set.StringVar(&cfg.GlobalCfg.DDLFilePath, "ddl-file-path", "", "the file path of ddl")
+	set.StringVar(&cfg.GlobalCfg.TSColumnName, "ts-column-name", "", "the timestamp column of the table of ddl-file-path,\n"+
+		"empty means the first column")
+	set.StringVar(&cfg.GlobalCfg.VINColumnName, "vin-column-name", "", "the device column of the table of ddl-file-path,\n"+
+		"empty means the second column")
 	set.StringVarP(&cfg.GlobalCfg.CfgFile, "config", "C", "", "configuration file to load")
 	set.BoolVar(&cfg.GlobalCfg.SkipSetGUCs, "skip-set-gucs", false, "whether to skip set GUCs")
 	set.StringVar(&cfg.GlobalCfg.PreBenchmarkQuery, "pre-benchmark-query", "", "some specific sql such as analyze and vaccum database before run benchmark queries")
 	set.BoolVar(&cfg.GlobalCfg.Degrade, "degrade", false, "whether do degrade after load")
+	set.StringVar(&cfg.GlobalCfg.Tables, "tables", "", "the tables to create, load and benchmark concurrently in one run, in JSON,\n"+
+		"e.g. '[{\"table-name\": \"t1\", \"tag-num\": 1000}, {\"table-name\": \"t2\", \"generator\": {\"generator-batch-size\": 2}}]'.\n"+
+		"Each table needs a table-name, while tag-num, metrics-type, total-metrics-count, metrics-descriptions,\n"+
+		"storage-type and generator plugin options are inherited from the global ones unless given,\n"+
+		"ddl-file-path, ts-column-name and vin-column-name are not.\n"+
+		"Empty means to run the single table of table-name")
+	set.Int64Var(&cfg.GlobalCfg.Seed, "seed", 0, "the seed of the generated data and the parameters of benchmark queries,\n"+
+		"runs of the same seed and config generate the same data and queries. 0 means a random seed, which is logged")
 
 	// misc
 	set.StringVar(&cfg.GlobalCfg.LogLevel, "log-level", "info", "log level. support \"debug\", \"verbose\", \"info\", \"error\"")
@@ -183,6 +256,10 @@ func (cfg *Config) GlobalFlagSet() *pflag.FlagSet {
 	set.BoolVar(&cfg.GlobalCfg.Watch, "watch", true, "whether to watch progress of each step or not")
 	set.StringVar(&cfg.GlobalCfg.ReportFormat, "report-format", "csv", "the format of report")
 	set.StringVar(&cfg.GlobalCfg.ReportPath, "report-path", "/tmp", "the file path for report to dump")
+	set.StringVar(&cfg.GlobalCfg.ResultsDB, "results-db", "", "the database to save the config and statistics of each run into,\n"+
+		"a PostgreSQL/YMatrix connection string, or \"sqlite://<file>\" for a local SQLite file. Empty means not to save")
+	set.StringVar(&cfg.GlobalCfg.MetricsAddr, "metrics-addr", "", "the address to serve prometheus metrics at /metrics during the run, e.g. \":9187\".\n"+
+		"Empty means not to serve")
 	set.SortFlags = false
 	set.SetOutput(os.Stdout)
 	return set
@@ -2,7 +2,9 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -11,8 +13,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/jmoiron/sqlx"
-
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/internal/util"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
@@ -46,6 +46,8 @@ type IEngine interface {
 	PrintStat()
 	PrintProgress()
 	GetFormattedSummary()
+	SaveResult()
+	CheckThresholds() error
 }
 
 type Engine struct {
@@ -60,6 +62,11 @@ type Engine struct {
 
 	Config *Config
 
+	// StartAt is when Run is called
+	StartAt time.Time
+	// RunID is the ID the results are saved as in the results database
+	RunID string
+
 	ctx            context.Context
 	cancelFunc     context.CancelFunc
 	watchWaitGroup sync.WaitGroup
@@ -80,6 +87,10 @@ type Engine struct {
 
 	oldGUCs metadata.GUCs
 	newGUCs metadata.GUCs
+
+	thresholdReport *ThresholdReport
+
+	metricsServer *http.Server
 }
 
 var _ IEngine = (*Engine)(nil)
@@ -114,7 +125,8 @@ func NewEngineFromConfig(cfg *Config) (*Engine, error) {
 }
 
 func (e *Engine) getVinValuesFromTable() ([]string, error) {
-	vinCol := e.Metadata.Table.Columns[metadata.VINColumnIndex]
+	_, vinIndex := e.Metadata.Table.KeyColumnIndexes()
+	vinCol := e.Metadata.Table.Columns[vinIndex]
 
 	conn, err := util.CreateDBConnection(e.Config.DB)
 	if err != nil {
@@ -134,12 +146,14 @@ func (e *Engine) getVinValuesFromTable() ([]string, error) {
 func (e *Engine) generateVinVals() ([]string, error) {
 	vinVals := make([]string, 0, e.Metadata.Cfg.TagNum)
 
-	if len(e.Metadata.Table.ColumnSpecs) < metadata.VINColumnIndex+1 {
+	_, vinIndex := e.Metadata.Table.KeyColumnIndexes()
+	if len(e.Metadata.Table.ColumnSpecs) < vinIndex+1 {
 		return vinVals, nil
 	}
 
-	columnSpec := e.Metadata.Table.ColumnSpecs[metadata.VINColumnIndex]
+	columnSpec := e.Metadata.Table.ColumnSpecs[vinIndex]
 	if columnSpec != nil && mxmock.IsValidTemplateName(columnSpec.Name) {
+		util.Seed(util.DeriveSeed(e.Config.GlobalCfg.GetSeed(), "vin"))
 		for idx := int64(0); idx < e.Config.GlobalCfg.TagNum; idx++ {
 			vinVal := mxmock.GenerateValByTemplate(columnSpec.Name)
 			vinVals = append(vinVals, vinVal)
@@ -257,6 +271,10 @@ func (e *Engine) GetFormattedSummary() {
 	if e.Config.GlobalCfg.Dump {
 		return
 	}
+	if e.Config.GlobalCfg.ReportFormat == ReportFormatJSON {
+		e.writeJSONReport()
+		return
+	}
 	var row string
 	if stat := e.IWriter.GetStat(); stat != nil {
 		row = stat.GetFormattedSummary()
@@ -273,6 +291,14 @@ func (e *Engine) GetFormattedSummary() {
 		row += strings.Join([]string{degradeStartTime, degradeStopTime}, util.DELIMITER)
 	}
 
+	if e.thresholdReport != nil {
+		b, err := json.Marshal(e.thresholdReport)
+		if err != nil {
+			log.Warn("Marshal threshold report failed: %v", err)
+		}
+		row += util.DELIMITER + string(b)
+	}
+
 	// writer row to file
 	switch e.Config.GlobalCfg.ReportFormat {
 	case ReportFormatCSV:
@@ -291,6 +317,50 @@ func (e *Engine) GetFormattedSummary() {
 	}
 }
 
+// SaveResult saves the config and statistics of this run into results-db, if given.
+func (e *Engine) SaveResult() {
+	if e.Config.GlobalCfg.Dump || e.Config.GlobalCfg.ResultsDB == "" || e.Config.SaveResultFunc == nil {
+		return
+	}
+	runID, err := e.Config.SaveResultFunc(e)
+	if err != nil {
+		log.Warn("Save results failed: %v", err)
+		return
+	}
+	e.RunID = runID
+	log.Info("Results saved as run %s", runID)
+}
+
+// CheckThresholds checks the statistics against the thresholds, if given,
+// and returns an error with ExitCodeThresholdViolated if any is violated.
+func (e *Engine) CheckThresholds() error {
+	thresholds := e.Config.ThresholdsCfg.thresholds
+	if e.Config.GlobalCfg.Dump || len(thresholds) == 0 {
+		return nil
+	}
+
+	var writerSummary *WriterSummary
+	if stat, ok := e.IWriter.GetStat().(WriterStat); ok {
+		summary := stat.GetWriterSummary()
+		writerSummary = &summary
+	}
+	var execBenchStats []*ExecBenchStat
+	if stat := e.IBenchmark.GetStat(); stat != nil {
+		for _, ss := range stat.GetSubStats() {
+			if ebs, ok := ss.(*ExecBenchStat); ok {
+				execBenchStats = append(execBenchStats, ebs)
+			}
+		}
+	}
+
+	e.thresholdReport = checkThresholds(thresholds, writerSummary, execBenchStats)
+	fmt.Println(e.thresholdReport.GetSummary())
+	if !e.thresholdReport.Passed {
+		return mxerror.ThresholdViolatedErrorf("%d of the thresholds violated", e.thresholdReport.violations())
+	}
+	return nil
+}
+
 func (e *Engine) PrintProgress() {
 	if e.Config.GlobalCfg.Dump {
 		return
@@ -324,6 +394,19 @@ func (e *Engine) Watch() {
 }
 
 func (e *Engine) Run() error {
+	e.StartAt = time.Now()
+	if err := e.prepare(); err != nil {
+		return err
+	}
+	return e.execute()
+}
+
+// prepare creates the table and sets GUCs for it.
+func (e *Engine) prepare() error {
+	log.Info("Seed: %d", e.Config.GlobalCfg.GetSeed())
+	if e.writesWithoutDB() {
+		return e.prepareWithoutDB()
+	}
 	err := util.CreateDBIfNotExists(e.Config.DB)
 	if err != nil {
 		return err
@@ -348,7 +431,7 @@ func (e *Engine) Run() error {
 	// no writing data
 	noWirterOrGenerator := e.Config.GeneratorCfg.Plugin == "nil" || e.Config.WriterCfg.Plugin == "nil"
 	qeuryOnlyMode := noWirterOrGenerator && !e.Config.GlobalCfg.Dump
-	if qeuryOnlyMode {
+	if qeuryOnlyMode || e.Config.GlobalCfg.TableExists {
 		e.execDDLFunc = func() error { return nil }
 	}
 
@@ -393,11 +476,55 @@ func (e *Engine) Run() error {
 	}
 	e.VolumeDesc.GetTableSizeFunc = e.getTableSize
 
-	err = e.handleGUCs()
+	return e.handleGUCs()
+}
+
+// writesWithoutDB tells the writer writes without the database, unless the data is dumped.
+func (e *Engine) writesWithoutDB() bool {
+	w, ok := e.IWriter.(DBLessWriter)
+	return ok && w.WritesWithoutDB() && !e.Config.GlobalCfg.Dump
+}
+
+// prepareWithoutDB prepares the table for the writer writing without the database,
+// where the DDL, GUCs and queries are dumped into the workspace instead of executed.
+func (e *Engine) prepareWithoutDB() error {
+	g := e.Config.GlobalCfg
+	switch {
+	case g.DDLFilePath != "":
+		return mxerror.CommonError("ddl-file-path needs the database to read the columns of the table from, which the writer runs without")
+	case g.TableExists:
+		return mxerror.CommonError("the table created by a previous phase is in the database, which the writer runs without")
+	case g.Degrade || g.PreBenchmarkQuery != "":
+		return mxerror.CommonError("degrade and pre-benchmark-query run in the database, which the writer runs without")
+	}
+	log.Info("Writing without the database, the DDL, GUCs and queries are dumped into %s instead of executed", e.workspace)
+
+	metaConfig, err := e.newMetadataConfig()
 	if err != nil {
 		return err
 	}
+	if e.Metadata, err = metadata.New(metaConfig); err != nil {
+		return err
+	}
+	e.Config.GlobalCfg.TotalMetricsCount = metaConfig.TotalMetricsCount
+	if err = e.dumpDDL(); err != nil {
+		return err
+	}
+	if e.Metadata.Table.VinValues, err = e.generateVinVals(); err != nil {
+		return err
+	}
+	if e.VolumeDesc.GeneratorPrediction, err = e.IGenerator.GetPrediction(e.Metadata.Table); err != nil {
+		return err
+	}
+	e.execBenchFunc = e.dumpBench
+	if g.SkipSetGUCs {
+		return nil
+	}
+	return e.dumpSetGUCs()
+}
 
+// execute loads data into the table and runs the benchmark queries.
+func (e *Engine) execute() error {
 	writerFinCh, err := func() (<-chan error, error) {
 		if e.Config.GlobalCfg.Dump {
 			ch := make(chan error)
@@ -410,6 +537,10 @@ func (e *Engine) Run() error {
 		return err
 	}
 
+	if err = e.serveMetrics(); err != nil {
+		return err
+	}
+
 	benchmarkFinCh := make(chan error, 1)
 	go func() {
 		defer close(benchmarkFinCh)
@@ -465,6 +596,9 @@ func (e *Engine) newMetadataConfig() (*metadata.Config, error) {
 	metaConfig := e.Config.GlobalCfg.NewMetadataConfig()
 	e.IGenerator.ModifyMetadataConfig(metaConfig)
 	metaConfig.DB = e.Config.DB
+	if e.writesWithoutDB() {
+		return metaConfig, nil
+	}
 	var err error
 	metaConfig.DBVersion, err = util.GetMXDBVersionFromDB(metaConfig.DB)
 	return metaConfig, err
@@ -520,6 +654,7 @@ func (e *Engine) Close() error {
 	defer func() {
 		e.cancelFunc()
 		e.watchWaitGroup.Wait()
+		stopMetrics(e.metricsServer)
 	}()
 	if err := e.safeCloseGenerator(); err != nil {
 		return err
@@ -572,12 +707,18 @@ func (e *Engine) execBench(gCtx context.Context, query Query, stat Stat) error {
 	if err != nil {
 		return err
 	}
-	ebs, ok := stat.(*ExecBenchStat)
-	if !ok {
+	var stats []*ExecBenchStat
+	var weights []float64
+	switch s := stat.(type) {
+	case *ExecBenchStat:
+		stats = []*ExecBenchStat{s}
+	case *MixedBenchStat:
+		stats, weights = s.stats, s.query.Weights
+	default:
 		return mxerror.CommonErrorf("stat type conversion error")
 	}
 	ctx, cancel := context.WithCancel(gCtx)
-	opt := ebs.opt
+	opt := stats[0].opt
 
 	runTimes := opt.RunTimes
 	if runTimes <= 0 {
@@ -587,14 +728,45 @@ func (e *Engine) execBench(gCtx context.Context, query Query, stat Stat) error {
 	}
 	defer cancel()
 
-	connPool := make([]*sqlx.DB, 0, opt.Parallel)
+	var schedule *arrivalSchedule
+	if opt.TargetQPS > 0 {
+		schedule, err = newArrivalSchedule(opt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, ebs := range stats {
+		if _, ok := ebs.query.(PreparedQuery); opt.Prepared && !ok {
+			log.Warn("Query %s can't be prepared, executing it by its SQL text", ebs.query.GetName())
+		}
+		if _, ok := ebs.query.(ValidatedQuery); opt.Validate && !ok {
+			log.Warn("Results of query %s can't be validated", ebs.query.GetName())
+		}
+	}
+	params := e.Config.DB
+	if opt.Timeout > 0 {
+		// set on connecting, so that it is kept once the connection is reestablished
+		params.Options = append(append([]string{}, params.Options...), fmt.Sprintf("statement_timeout=%d", opt.Timeout.Milliseconds()))
+	}
+	workers := make([]*benchWorker, 0, opt.Parallel)
 	for p := 0; p < opt.Parallel; p++ {
-		conn, err := util.CreateDBConnection(e.Config.DB)
+		conn, err := util.CreateDBConnection(params)
 		if err != nil {
 			log.Error("db connection create error: %v", err)
+			for _, w := range workers {
+				w.close()
+			}
+			return err
+		}
+		w, err := newBenchWorker(conn, stats, weights, util.NewRand(opt.Seed, fmt.Sprintf("mixed-%d", p)))
+		if err != nil {
+			for _, w := range workers {
+				w.close()
+			}
 			return err
 		}
-		connPool = append(connPool, conn)
+		workers = append(workers, w)
 	}
 
 	var wg sync.WaitGroup
@@ -602,11 +774,15 @@ func (e *Engine) execBench(gCtx context.Context, query Query, stat Stat) error {
 	start := time.Now()
 
 	for j := 0; j < opt.Parallel; j++ {
-		go func(ctx context.Context, conn *sqlx.DB) {
+		go func(ctx context.Context, w *benchWorker) {
 			defer func() {
 				wg.Done()
-				conn.Close()
+				w.close()
 			}()
+			if schedule != nil {
+				execOpenLoop(ctx, w, schedule, start)
+				return
+			}
 			var runs int64
 			for {
 				select {
@@ -617,26 +793,90 @@ func (e *Engine) execBench(gCtx context.Context, query Query, stat Stat) error {
 				if runTimes > 0 && runs >= runTimes {
 					break
 				}
+				x := w.pick()
 				singleQueryStart := time.Now()
-				_, err := conn.Exec(query.GetSQL())
-				ebs.addLatency(time.Since(singleQueryStart))
-				atomic.AddInt64(&ebs.runs, 1)
-				atomic.StoreInt64(&ebs.TimeElapsed, int64(time.Since(start)))
-				if err != nil {
-					log.Error("query: %s execute error: %v", query.GetName(), err)
-					return
+				err := w.exec(ctx, x)
+				if err == nil {
+					x.ebs.addLatency(time.Since(singleQueryStart))
 				}
+				x.ebs.addRun()
+				w.elapse(time.Since(start))
 				runs++
+				if err != nil && !w.recover(ctx, x, err) {
+					return
+				}
 			}
-		}(ctx, connPool[j])
+		}(ctx, workers[j])
 	}
 	wg.Wait()
-	atomic.StoreInt64(&ebs.TimeElapsed, int64(time.Since(start)))
 
-	return nil
+	var failure error
+	for _, ebs := range stats {
+		atomic.StoreInt64(&ebs.TimeElapsed, int64(time.Since(start)))
+		if es := ebs.Errors; es.Total() > 0 {
+			log.Warn("query: %s with parallel %d, errors: %s, connections stopped by errors: %d, the first error: %s",
+				ebs.query.GetName(), opt.Parallel, es, atomic.LoadInt64(&es.StoppedConnections), es.FirstError)
+		}
+		if err := ebs.checkValidation(); err != nil {
+			if !ebs.opt.FailOnInvalid {
+				log.Warn("%v", err)
+			} else if failure == nil {
+				failure = err
+			}
+		}
+	}
+	return failure
+}
+
+// execOpenLoop runs the queries at their scheduled starts by the worker of a connection,
+// until all the queries have been scheduled or the context is done.
+func execOpenLoop(ctx context.Context, w *benchWorker, schedule *arrivalSchedule, start time.Time) {
+	for {
+		scheduled, ok := schedule.take()
+		if !ok {
+			return
+		}
+		if d := time.Until(scheduled); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+
+		x := w.pick()
+		singleQueryStart := time.Now()
+		err := w.exec(ctx, x)
+		singleQueryEnd := time.Now()
+		if err == nil {
+			x.ebs.addOpenLoopLatency(singleQueryEnd.Sub(scheduled), singleQueryEnd.Sub(singleQueryStart),
+				singleQueryStart.Sub(scheduled) > _SCHEDULE_TOLERANCE)
+		}
+		x.ebs.addRun()
+		w.elapse(time.Since(start))
+		if err != nil && !w.recover(ctx, x, err) {
+			return
+		}
+	}
 }
 
-func (e *Engine) dumpBench(_ context.Context, query Query, _ Stat) error {
+func (e *Engine) dumpBench(ctx context.Context, query Query, stat Stat) error {
+	if mq, ok := query.(*MixedQuery); ok {
+		for _, q := range mq.Queries {
+			if err := e.dumpBench(ctx, q, stat); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	_, err := e.benchFile.WriteString(fmt.Sprintf("-- query name: %s\n%s;\n", query.GetName(), query.GetSQL()))
 	return err
 }
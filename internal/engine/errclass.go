@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+)
+
+type ErrorClass = string
+
+const (
+	ErrorClassTimeout       ErrorClass = "timeout"
+	ErrorClassCanceled      ErrorClass = "canceled"
+	ErrorClassOOM           ErrorClass = "oom"
+	ErrorClassSerialization ErrorClass = "serialization"
+	ErrorClassConnection    ErrorClass = "connection"
+	ErrorClassOther         ErrorClass = "other"
+)
+
+// ErrorClasses are the classes of the errors of query executions, in the order to report.
+var ErrorClasses = []ErrorClass{
+	ErrorClassTimeout, ErrorClassCanceled, ErrorClassOOM, ErrorClassSerialization, ErrorClassConnection, ErrorClassOther,
+}
+
+const (
+	_SQLSTATE_QUERY_CANCELED         = "57014"
+	_SQLSTATE_OUT_OF_MEMORY          = "53200"
+	_SQLSTATE_SERIALIZATION_FAILURE  = "40001"
+	_SQLSTATE_DEADLOCK_DETECTED      = "40P01"
+	_SQLSTATE_ADMIN_SHUTDOWN         = "57P01"
+	_SQLSTATE_CRASH_SHUTDOWN         = "57P02"
+	_SQLSTATE_CANNOT_CONNECT_NOW     = "57P03"
+	_SQLSTATE_CLASS_CONNECTION_ERROR = "08"
+
+	// the message of 57014 canceled by statement_timeout, instead of by the user
+	_STATEMENT_TIMEOUT_MESSAGE = "statement timeout"
+)
+
+// sqlStateError is implemented by the errors of the server, of both pgx and lib/pq
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// classifyError returns the class of the error of a query execution,
+// by its SQLSTATE if it is returned by the server, or by its type otherwise.
+func classifyError(err error) ErrorClass {
+	var se sqlStateError
+	if errors.As(err, &se) {
+		state := se.SQLState()
+		switch {
+		case state == _SQLSTATE_QUERY_CANCELED:
+			if strings.Contains(se.Error(), _STATEMENT_TIMEOUT_MESSAGE) {
+				return ErrorClassTimeout
+			}
+			return ErrorClassCanceled
+		case state == _SQLSTATE_OUT_OF_MEMORY:
+			return ErrorClassOOM
+		case state == _SQLSTATE_SERIALIZATION_FAILURE, state == _SQLSTATE_DEADLOCK_DETECTED:
+			return ErrorClassSerialization
+		case state == _SQLSTATE_ADMIN_SHUTDOWN, state == _SQLSTATE_CRASH_SHUTDOWN, state == _SQLSTATE_CANNOT_CONNECT_NOW,
+			strings.HasPrefix(state, _SQLSTATE_CLASS_CONNECTION_ERROR):
+			return ErrorClassConnection
+		}
+		return ErrorClassOther
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassConnection
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassConnection
+	}
+	// pgx closes the connection on a fatal error, failing the executions on it later
+	if strings.Contains(err.Error(), "conn closed") {
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
+
+// isRetryable tells an execution failed by an error of the class may succeed once retried
+func isRetryable(class ErrorClass) bool {
+	return class == ErrorClassSerialization || class == ErrorClassConnection
+}
@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeServerError is an error of the server with SQLSTATE, as of pgx and lib/pq
+type fakeServerError struct {
+	state, message string
+}
+
+func (e *fakeServerError) Error() string {
+	return "ERROR: " + e.message + " (SQLSTATE " + e.state + ")"
+}
+func (e *fakeServerError) SQLState() string { return e.state }
+
+var _ = Describe("Error Class", func() {
+	It("should classify the errors of the server by SQLSTATE", func() {
+		for err, class := range map[error]ErrorClass{
+			&fakeServerError{"57014", "canceling statement due to statement timeout"}:        ErrorClassTimeout,
+			&fakeServerError{"57014", "canceling statement due to user request"}:             ErrorClassCanceled,
+			&fakeServerError{"53200", "out of memory"}:                                       ErrorClassOOM,
+			&fakeServerError{"40001", "could not serialize access"}:                          ErrorClassSerialization,
+			&fakeServerError{"40P01", "deadlock detected"}:                                   ErrorClassSerialization,
+			&fakeServerError{"57P01", "terminating connection due to administrator command"}: ErrorClassConnection,
+			&fakeServerError{"08006", "connection failure"}:                                  ErrorClassConnection,
+			&fakeServerError{"42P01", "relation does not exist"}:                             ErrorClassOther,
+		} {
+			Expect(classifyError(fmt.Errorf("query failed: %w", err))).To(Equal(class), err.Error())
+		}
+	})
+
+	It("should classify the errors of the client by type", func() {
+		for err, class := range map[error]ErrorClass{
+			context.DeadlineExceeded: ErrorClassTimeout,
+			context.Canceled:         ErrorClassCanceled,
+			driver.ErrBadConn:        ErrorClassConnection,
+			io.ErrUnexpectedEOF:      ErrorClassConnection,
+			&net.OpError{Op: "read", Err: errors.New("reset")}: ErrorClassConnection,
+			errors.New("conn closed"):                          ErrorClassConnection,
+			errors.New("unknown"):                              ErrorClassOther,
+		} {
+			Expect(classifyError(err)).To(Equal(class), err.Error())
+		}
+	})
+
+	It("should only retry serialization failures and lost connections", func() {
+		Expect(isRetryable(ErrorClassSerialization)).To(BeTrue())
+		Expect(isRetryable(ErrorClassConnection)).To(BeTrue())
+		Expect(isRetryable(ErrorClassTimeout)).To(BeFalse())
+		Expect(isRetryable(ErrorClassOOM)).To(BeFalse())
+	})
+})
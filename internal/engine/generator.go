@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"time"
+
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 )
 
@@ -27,6 +29,36 @@ type GetTableSizeFunc func() (int64, error)
 
 type GeneratorPrediction struct {
 	Count, Size int64
+
+	// RateTarget is nil unless the generator throttles its output.
+	RateTarget RateTarget
+}
+
+type RateUnit = string
+
+const (
+	RateUnitRows RateUnit = "rows"
+	RateUnitMB   RateUnit = "MB"
+)
+
+// RateTarget describes the output rate a generator is throttled to,
+// which may vary over time.
+type RateTarget interface {
+	GetUnit() RateUnit
+	// GetCurrentRate returns the target in units per second at the moment.
+	GetCurrentRate() float64
+}
+
+// GetAchievedRate returns the rate in the unit of the target,
+// given the rows and bytes written in a period.
+func GetAchievedRate(target RateTarget, rows, bytes int64, period time.Duration) float64 {
+	if period <= 0 {
+		return 0
+	}
+	if target.GetUnit() == RateUnitMB {
+		return float64(bytes) / (1024 * 1024) / period.Seconds()
+	}
+	return float64(rows) / period.Seconds()
 }
 
 type VolumeDesc struct {
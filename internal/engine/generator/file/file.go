@@ -3,14 +3,22 @@ package file
 import (
 	"bufio"
 	"bytes"
+	"container/heap"
 	"context"
+	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/parquet"
 )
 
 const (
@@ -19,15 +27,51 @@ const (
 )
 
 type Config struct {
-	FilePaths       []string `mapstructure:"generator-file-paths"`
-	BatchSize       int      `mapstructure:"generator-batch-size"`
-	EmptyValueRatio int      `mapstructure:"generator-empty-value-ratio"`
+	FilePaths       []string   `mapstructure:"generator-file-paths"`
+	FileFormat      FileFormat `mapstructure:"generator-file-format"`
+	CSVDelimiter    string     `mapstructure:"generator-csv-delimiter"`
+	BatchSize       int        `mapstructure:"generator-batch-size"`
+	EmptyValueRatio int        `mapstructure:"generator-empty-value-ratio"`
+	FileConcurrency int        `mapstructure:"generator-file-concurrency"`
+
+	Replay         bool    `mapstructure:"generator-replay"`
+	ReplaySpeed    float64 `mapstructure:"generator-replay-speed"`
+	ReplayRemapVIN bool    `mapstructure:"generator-replay-remap-vin"`
+
+	csvDelimiter rune
+}
+
+func (cfg *Config) init() error {
+	if cfg.FileConcurrency < 0 {
+		return mxerror.CommonErrorf("generator-file-concurrency(%d) should not be negative", cfg.FileConcurrency)
+	}
+	// 0 loads the files one after another, the same as 1
+	if cfg.FileConcurrency == 0 {
+		cfg.FileConcurrency = 1
+	}
+	if cfg.Replay && cfg.ReplaySpeed <= 0 {
+		return mxerror.CommonErrorf("generator-replay-speed(%v) should be positive", cfg.ReplaySpeed)
+	}
+	switch cfg.FileFormat {
+	case "":
+		cfg.FileFormat = _FORMAT_TEXT
+	case _FORMAT_TEXT, _FORMAT_PARQUET:
+	case _FORMAT_CSV:
+		var err error
+		cfg.csvDelimiter, err = parseDelimiter(cfg.CSVDelimiter)
+		return err
+	default:
+		return mxerror.CommonErrorf("unsupported generator-file-format: %s, support \"text\", \"csv\" and \"parquet\"", cfg.FileFormat)
+	}
+	return nil
 }
 
 type Generator struct {
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 	cfg        *Config
+	// err is of the invalid config, returned when the generator runs
+	err error
 }
 
 func NewGenerator(cfg engine.GeneratorConfig) engine.IGenerator {
@@ -38,77 +82,294 @@ func NewGenerator(cfg engine.GeneratorConfig) engine.IGenerator {
 		cfg:        gCfg,
 		ctx:        ctx,
 		cancelFunc: cancel,
+		err:        gCfg.init(),
 	}
 }
 
-func (g *Generator) Run(_ engine.GlobalConfig, _ *metadata.Metadata, writeFunc engine.WriteFunc) error {
+func (g *Generator) Run(gcfg engine.GlobalConfig, meta *metadata.Metadata, writeFunc engine.WriteFunc) error {
+	if g.err != nil {
+		return g.err
+	}
 	if len(g.cfg.FilePaths) == 0 {
 		log.Warn("[Generator.FILE] No path for data files is assigned to 'generator-file-paths', skipping...")
 		return nil
 	}
-	dataFiles := make([]*os.File, 0, len(g.cfg.FilePaths))
-	for _, filePath := range g.cfg.FilePaths {
-		dataFile, err := os.OpenFile(filePath, os.O_RDONLY, 0400)
+	paths, err := expandPaths(g.cfg.FilePaths)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		log.Warn("[Generator.FILE] No data file is found in 'generator-file-paths', skipping...")
+		return nil
+	}
+	if g.cfg.FileFormat != _FORMAT_TEXT || g.cfg.Replay {
+		return g.loadRecords(gcfg, meta.Table, paths, writeFunc)
+	}
+
+	log.Info("[Generator.FILE] Start to load to writer")
+	defer log.Info("[Generator.FILE] Data generating ended")
+
+	return g.loadFiles(len(paths), writeFunc, func(i int, b *batch) error {
+		return loadLines(paths[i], b)
+	})
+}
+
+// loadFiles loads n files by generator-file-concurrency goroutines, each of which takes
+// the next file not loaded yet and adds its rows to a batch of its own by loadFile.
+// The batches are written one at a time, so that there is a batch per goroutine in memory at most.
+func (g *Generator) loadFiles(n int, writeFunc engine.WriteFunc, loadFile func(i int, b *batch) error) error {
+	var mu sync.Mutex
+	write := func(data []byte, lines, size int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return writeFunc(data, lines, size)
+	}
+
+	files := make(chan int, n)
+	for i := 0; i < n; i++ {
+		files <- i
+	}
+	close(files)
+
+	eg, ctx := errgroup.WithContext(g.ctx)
+	for w := 0; w < g.cfg.FileConcurrency && w < n; w++ {
+		eg.Go(func() error {
+			b := &batch{
+				ctx:       ctx,
+				buff:      bytes.NewBuffer(make([]byte, 0, bufferSize)),
+				writeFunc: write,
+			}
+			for i := range files {
+				if err := loadFile(i, b); err != nil {
+					return err
+				}
+			}
+			return b.flush()
+		})
+	}
+	// the others are canceled by the first error, or all by Close
+	if err := eg.Wait(); err != errCanceled {
+		return err
+	}
+	return nil
+}
+
+// loadLines adds the lines of the text file to the batch as they are
+func loadLines(path string, b *batch) error {
+	r, err := openFile(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), batchLimit)
+	for scanner.Scan() {
+		if err = b.addLine(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return mxerror.CommonErrorf("failed to read %s: %v", path, err)
+	}
+	return nil
+}
+
+// loadRecords loads the rows of files with named columns mapped to the columns of the table,
+// or replays the rows of the files. The columns of all the files are checked before loading any.
+func (g *Generator) loadRecords(gcfg engine.GlobalConfig, table *metadata.Table, paths []string, writeFunc engine.WriteFunc) error {
+	indexes := make([][]int, 0, len(paths))
+	for _, filePath := range paths {
+		r, err := openRecordReader(g.cfg, table, filePath)
 		if err != nil {
 			return err
 		}
-		dataFiles = append(dataFiles, dataFile)
+		idx, _, err := mapColumns(table, filePath, r.Columns())
+		r.Close()
+		if err != nil {
+			return err
+		}
+		indexes = append(indexes, idx)
 	}
-	defer func() {
-		for _, file := range dataFiles {
-			file.Close()
+
+	if !g.cfg.Replay {
+		log.Info("[Generator.FILE] Start to load to writer")
+		defer log.Info("[Generator.FILE] Data generating ended")
+
+		return g.loadFiles(len(paths), writeFunc, func(i int, b *batch) error {
+			return g.eachRow(table, paths[i], indexes[i], b.add)
+		})
+	}
+
+	rp, err := newReplayer(g.cfg, gcfg, table)
+	if err != nil {
+		return err
+	}
+	for i, filePath := range paths {
+		if err = g.eachRow(table, filePath, indexes[i], rp.scan); err != nil {
+			return err
 		}
-	}()
+	}
+	rp.start()
+	if n := rp.droppedVINs(); n > 0 {
+		log.Warn("[Generator.FILE] The rows of %d of the %d VINs of the files are not replayed, as there are %d VINs of tag-num to remap to",
+			n, len(rp.vins), len(rp.targets))
+	}
 
-	log.Info("[Generator.FILE] Start to load to writer")
+	log.Info("[Generator.FILE] Start to replay to writer")
 	defer log.Info("[Generator.FILE] Data generating ended")
 
-	for _, dataFile := range dataFiles {
-		scanner := bufio.NewScanner(dataFile)
-		// TODO: resize scanner's capacity for lines over 64K
-		buff := bytes.NewBuffer(make([]byte, 0, bufferSize))
-		var batchLine int64
-		for scanner.Scan() {
-			if buff.Len() > batchLimit {
-				select {
-				case <-g.ctx.Done():
-					return nil
-				default:
-					err := writeFunc(buff.Bytes(), batchLine, int64(buff.Len()))
-					if err != nil {
-						return err
-					}
-					buff.Reset()
-					batchLine = 0
-				}
-			}
-			buff.WriteString(scanner.Text())
-			buff.WriteByte('\n')
-			batchLine++
+	b := &batch{
+		ctx:       g.ctx,
+		buff:      bytes.NewBuffer(make([]byte, 0, bufferSize)),
+		writeFunc: writeFunc,
+	}
+	return g.replayFiles(table, paths, indexes, rp, b)
+}
+
+// replayFiles adds the rows of the files to the batch round after round,
+// merging the rows of all the files in time order, as the rows of each file are,
+// so that the files of overlapping time ranges are replayed side by side.
+func (g *Generator) replayFiles(table *metadata.Table, paths []string, indexes [][]int, rp *replayer, b *batch) error {
+	for round := 0; !rp.isDone(round); round++ {
+		err := g.mergeRows(table, paths, indexes, rp.tsIndex, func(values []*string, t time.Time, layout string) error {
+			return rp.replay(values, t, layout, round, b)
+		})
+		if err == errCanceled {
+			return nil
 		}
-		// last flush
-		err := writeFunc(buff.Bytes(), batchLine, int64(buff.Len()))
 		if err != nil {
 			return err
 		}
-		if err = scanner.Err(); err != nil {
+	}
+	if err := b.flush(); err != errCanceled {
+		return err
+	}
+	return nil
+}
+
+// mergeRows calls fn with the values of each row of the files and its timestamp in time order,
+// keeping all the files open to take the earliest row of them next.
+func (g *Generator) mergeRows(table *metadata.Table, paths []string, indexes [][]int, tsIndex int,
+	fn func(values []*string, t time.Time, layout string) error) error {
+	h := make(rowHeap, 0, len(paths))
+	var readers []recordReader
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+	for i, filePath := range paths {
+		r, err := openRecordReader(g.cfg, table, filePath)
+		if err != nil {
+			return err
+		}
+		readers = append(readers, r)
+		c := &rowCursor{file: i, filePath: filePath, r: r, indexes: indexes[i], values: make([]*string, len(indexes[i]))}
+		ok, err := c.next(tsIndex)
+		if err != nil {
 			return err
 		}
+		if ok {
+			h = append(h, c)
+		}
+	}
+	heap.Init(&h)
+
+	for h.Len() > 0 {
+		c := h[0]
+		if err := fn(c.values, c.t, c.layout); err != nil {
+			return err
+		}
+		ok, err := c.next(tsIndex)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
 	}
 	return nil
 }
 
-func (g *Generator) GetPrediction(_ *metadata.Table) (engine.GeneratorPrediction, error) {
-	var totalSize int64
-	for _, filePath := range g.cfg.FilePaths {
+// eachRow calls fn with the values of each row of the file in the order of the columns of the table,
+// which are mapped by indexes.
+func (g *Generator) eachRow(table *metadata.Table, filePath string, indexes []int, fn func([]*string) error) error {
+	r, err := openRecordReader(g.cfg, table, filePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	values := make([]*string, len(indexes))
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return mxerror.CommonErrorf("failed to read %s: %v", filePath, err)
+		}
+		mapRow(row, indexes, values)
+		if err = fn(values); err != nil {
+			return err
+		}
+	}
+}
+
+// mapRow sets the values in the order of the columns of the table to the values of the row, mapped by indexes
+func mapRow(row []*string, indexes []int, values []*string) {
+	for i, idx := range indexes {
+		values[i] = nil
+		if idx >= 0 && idx < len(row) {
+			values[i] = row[idx]
+		}
+	}
+}
+
+// GetPrediction also checks the columns of the files with named columns,
+// so that mismatches are reported before loading.
+func (g *Generator) GetPrediction(table *metadata.Table) (engine.GeneratorPrediction, error) {
+	if g.err != nil {
+		return engine.GeneratorPrediction{}, g.err
+	}
+	paths, err := expandPaths(g.cfg.FilePaths)
+	if err != nil {
+		return engine.GeneratorPrediction{}, err
+	}
+	// the sizes of compressed files are of the compressed data
+	var totalSize, count int64
+	for _, filePath := range paths {
 		fileInfo, err := os.Stat(filePath)
 		if err != nil {
 			return engine.GeneratorPrediction{}, err
 		}
 
 		totalSize += fileInfo.Size()
+
+		if g.cfg.FileFormat == _FORMAT_TEXT {
+			continue
+		}
+		r, err := openRecordReader(g.cfg, table, filePath)
+		if err != nil {
+			return engine.GeneratorPrediction{}, err
+		}
+		_, missing, err := mapColumns(table, filePath, r.Columns())
+		if pr, ok := r.(*parquet.Reader); ok {
+			count += pr.NumRows()
+		}
+		r.Close()
+		if err != nil {
+			return engine.GeneratorPrediction{}, err
+		}
+		if len(missing) > 0 {
+			log.Warn("[Generator.FILE] Columns %s of table %s are not in %s, which are loaded as null",
+				strings.Join(missing, ", "), table.Identifier(), filePath)
+		}
 	}
-	return engine.GeneratorPrediction{Size: totalSize}, nil
+	return engine.GeneratorPrediction{Size: totalSize, Count: count}, nil
 }
 
 func (g *Generator) ModifyMetadataConfig(metaConfig *metadata.Config) {
@@ -137,7 +398,22 @@ func (g *Generator) CreatePluginConfig() interface{} {
 func (g *Generator) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	gCfg := &Config{}
 	p := pflag.NewFlagSet("generator.file", pflag.ContinueOnError)
-	p.StringSliceVar(&gCfg.FilePaths, "generator-file-paths", []string{}, "the absolute paths of the data file to be loaded")
+	p.StringSliceVar(&gCfg.FilePaths, "generator-file-paths", []string{}, "the absolute paths of the data files to be loaded, or glob patterns or directories of them.\n"+
+		"The files compressed by gzip, zstd or lz4 are decompressed as they are loaded")
+	p.StringVar(&gCfg.FileFormat, "generator-file-format", _FORMAT_TEXT, "the format of the data files, support \"text\", \"csv\" and \"parquet\".\n"+
+		"Lines of \"text\" files are loaded as they are, in CSV delimited by '|' as the writers load.\n"+
+		"\"csv\" files with a header of column names and \"parquet\" files are mapped to the columns of the table by name,\n"+
+		"the columns not in the table, or the timestamp and vin columns missing from a file fail the run before loading")
+	p.StringVar(&gCfg.CSVDelimiter, "generator-csv-delimiter", ",", "the delimiter of \"csv\" files")
+	p.IntVar(&gCfg.FileConcurrency, "generator-file-concurrency", 1, "the number of files loaded at the same time, each buffering a batch of up to 4MB;\n"+
+		"1 loads the files one after another in order, while the rows of different files are loaded in no particular order if it is more")
+	p.BoolVar(&gCfg.Replay, "generator-replay", false, "replay the rows of the files with the timestamps shifted from the earliest one onto ts-start,\n"+
+		"over and over until ts-end; in 'realtime' mode, onto the current time until mxbench gets terminated")
+	p.Float64Var(&gCfg.ReplaySpeed, "generator-replay-speed", 1, "the speed-up of replaying in 'realtime' mode,\n"+
+		"e.g. 10 replays the rows of 10 seconds in 1 second")
+	p.BoolVar(&gCfg.ReplayRemapVIN, "generator-replay-remap-vin", false, "remap the VINs of the files to the tag-num VINs of the run when replaying,\n"+
+		"where the rows of a device are replayed as multiple devices if tag-num is more than the devices of the files,\n"+
+		"and the rows of the devices beyond tag-num are not replayed if it is less")
 	p.IntVar(&gCfg.BatchSize, "generator-batch-size", 1, "The number of lines of data generated for a tag of a given timestamp.\n"+
 		"e.g. It is set to be 5, then for tag \"tag1\" with ts of \"2022-04-02 15:04:03\",\n5 lines of data will be generated and sent into DBMS.\n"+
 		"Eventually, however, they will be merged as 1 row in DBMS.")
@@ -0,0 +1,292 @@
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+)
+
+var _ = Describe("File generator", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "mxbench-file")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	table := &metadata.Table{
+		Columns: metadata.Columns{
+			metadata.NewColumn("ts", metadata.ColumnTypeTimestamp),
+			metadata.NewColumn("vin", metadata.ColumnTypeText),
+			metadata.NewColumn("speed", metadata.MetricsTypeFloat8),
+			metadata.NewColumn("note", metadata.ColumnTypeText),
+		},
+		ColumnNameTS:  "ts",
+		ColumnNameVIN: "vin",
+	}
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+	newGenerator := func(cfg *Config) *Generator {
+		return NewGenerator(engine.GeneratorConfig{PluginConfig: cfg}).(*Generator)
+	}
+
+	It("should map the columns of csv files by name", func() {
+		path := writeFile("a.csv", "note;vin;ts\n"+
+			"\"a|b \"\"c\"\"\";v1;2022-04-19 00:00:00\n"+
+			";v2;2022-04-19 00:00:01\n")
+		cfg := &Config{FilePaths: []string{path}, FileFormat: _FORMAT_CSV, CSVDelimiter: ";"}
+		Expect(cfg.init()).To(Succeed())
+		r, err := openRecordReader(cfg, table, path)
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		indexes, missing, err := mapColumns(table, path, r.Columns())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indexes).To(Equal([]int{2, 1, -1, 0}))
+		Expect(missing).To(Equal([]string{"speed"}))
+
+		buff := &bytes.Buffer{}
+		g := newGenerator(cfg)
+		Expect(g.eachRow(table, path, indexes, func(values []*string) error {
+			writeRow(buff, values)
+			return nil
+		})).To(Succeed())
+		Expect(buff.String()).To(Equal("2022-04-19 00:00:00|v1||\"a|b \"\"c\"\"\"\n" +
+			"2022-04-19 00:00:01|v2||\n"))
+	})
+
+	It("should report mismatched columns before loading", func() {
+		for content, msg := range map[string]string{
+			"ts,vin,speed,unknown\n": "columns unknown of",
+			"ts,speed\n":             "column vin of table",
+			"ts,vin,ts\n":            "column ts of",
+			"":                       "no header",
+		} {
+			path := writeFile("a.csv", content)
+			g := newGenerator(&Config{FilePaths: []string{path}, FileFormat: _FORMAT_CSV, CSVDelimiter: ","})
+			_, err := g.GetPrediction(table)
+			Expect(err).To(MatchError(ContainSubstring(msg)), content)
+		}
+	})
+
+	It("should replay the rows onto the time window with VINs remapped", func() {
+		path := writeFile("a.txt", "2022-01-01 00:00:00|v1|1|\n2022-01-01 00:00:01.5|v2|2|\"a|b\"\n")
+		cfg := &Config{FilePaths: []string{path}, Replay: true, ReplaySpeed: 1, ReplayRemapVIN: true}
+		g := newGenerator(cfg)
+		Expect(g.err).NotTo(HaveOccurred())
+
+		replayTable := *table
+		replayTable.VinValues = []string{"a", "b", "c", "d", "e"}
+		startAt := time.Date(2022, 4, 19, 0, 0, 0, 0, time.UTC)
+		rp, err := newReplayer(cfg, engine.GlobalConfig{
+			StartAt:               startAt,
+			EndAt:                 startAt.Add(5 * time.Second),
+			TimestampStepInSecond: 1,
+		}, &replayTable)
+		Expect(err).NotTo(HaveOccurred())
+		indexes := [][]int{{0, 1, 2, 3}}
+		Expect(g.eachRow(&replayTable, path, indexes[0], rp.scan)).To(Succeed())
+		rp.start()
+
+		var data []byte
+		var lines int64
+		b := &batch{ctx: g.ctx, buff: &bytes.Buffer{}, writeFunc: func(d []byte, l, _ int64) error {
+			data = append(data, d...)
+			lines += l
+			return nil
+		}}
+		Expect(g.replayFiles(&replayTable, []string{path}, indexes, rp, b)).To(Succeed())
+		Expect(b.flush()).To(Succeed())
+
+		// the rounds are 2.5 seconds apart, so that the third one starts at ts-end
+		expected := ""
+		for _, ts := range [][]string{
+			{"2022-04-19 00:00:00", "2022-04-19 00:00:01.5"},
+			{"2022-04-19 00:00:02.5", "2022-04-19 00:00:04"},
+		} {
+			expected += ts[0] + "|a|1|\n" + ts[0] + "|c|1|\n" + ts[0] + "|e|1|\n" +
+				ts[1] + "|b|2|\"a|b\"\n" + ts[1] + "|d|2|\"a|b\"\n"
+		}
+		Expect(string(data)).To(Equal(expected))
+		Expect(lines).To(Equal(int64(10)))
+
+		// onto the current time 10 times faster in realtime mode
+		rp.isRealtime, rp.speed = true, 10
+		t, ok := rp.shift(rp.srcStart.Add(time.Second), 2)
+		Expect(ok).To(BeTrue())
+		Expect(t.Sub(rp.wallTime)).To(Equal(600 * time.Millisecond))
+		Expect(rp.isDone(100)).To(BeFalse())
+	})
+
+	It("should replay the rows of overlapping files in time order", func() {
+		a := writeFile("a.txt", "2022-01-01 00:00:00|v1|1|\n2022-01-01 00:00:02|v1|3|\n2022-01-01 00:00:04|v1|5|\n")
+		b := writeFile("b.txt", "2022-01-01 00:00:01|v2|2|\n2022-01-01 00:00:02|v2|4|\n")
+		cfg := &Config{FilePaths: []string{a, b}, Replay: true, ReplaySpeed: 1}
+		g := newGenerator(cfg)
+		Expect(g.err).NotTo(HaveOccurred())
+
+		startAt := time.Date(2022, 4, 19, 0, 0, 0, 0, time.UTC)
+		rp, err := newReplayer(cfg, engine.GlobalConfig{
+			StartAt:               startAt,
+			EndAt:                 startAt.Add(5 * time.Second),
+			TimestampStepInSecond: 1,
+		}, table)
+		Expect(err).NotTo(HaveOccurred())
+		indexes := [][]int{{0, 1, 2, 3}, {0, 1, 2, 3}}
+		for i, path := range []string{a, b} {
+			Expect(g.eachRow(table, path, indexes[i], rp.scan)).To(Succeed())
+		}
+		rp.start()
+
+		var data []byte
+		batch := &batch{ctx: g.ctx, buff: &bytes.Buffer{}, writeFunc: func(d []byte, _, _ int64) error {
+			data = append(data, d...)
+			return nil
+		}}
+		Expect(g.replayFiles(table, []string{a, b}, indexes, rp, batch)).To(Succeed())
+		Expect(batch.flush()).To(Succeed())
+		Expect(string(data)).To(Equal("2022-04-19 00:00:00|v1|1|\n" +
+			"2022-04-19 00:00:01|v2|2|\n" +
+			"2022-04-19 00:00:02|v1|3|\n" +
+			"2022-04-19 00:00:02|v2|4|\n" +
+			"2022-04-19 00:00:04|v1|5|\n"))
+	})
+
+	It("should tell the VINs not replayed for lack of VINs to remap to", func() {
+		path := writeFile("a.txt", "2022-01-01 00:00:00|v1|1|\n2022-01-01 00:00:00|v2|2|\n2022-01-01 00:00:00|v3|3|\n")
+		cfg := &Config{FilePaths: []string{path}, Replay: true, ReplaySpeed: 1, ReplayRemapVIN: true}
+		g := newGenerator(cfg)
+
+		replayTable := *table
+		replayTable.VinValues = []string{"a", "b"}
+		startAt := time.Date(2022, 4, 19, 0, 0, 0, 0, time.UTC)
+		rp, err := newReplayer(cfg, engine.GlobalConfig{StartAt: startAt, EndAt: startAt.Add(time.Second)}, &replayTable)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(g.eachRow(&replayTable, path, []int{0, 1, 2, 3}, rp.scan)).To(Succeed())
+		rp.start()
+		Expect(rp.droppedVINs()).To(Equal(1))
+
+		replayTable.VinValues = []string{"a", "b", "c"}
+		rp.targets = replayTable.VinValues
+		Expect(rp.droppedVINs()).To(Equal(0))
+	})
+
+	It("should expand globs and directories to the files", func() {
+		Expect(os.MkdirAll(filepath.Join(dir, "d", "sub"), 0755)).To(Succeed())
+		for _, name := range []string{"d/b.csv", "d/a.csv", "d/.hidden", "d/sub/c.csv", "x1.csv", "x2.csv", "y.csv"} {
+			writeFile(name, "")
+		}
+		paths, err := expandPaths([]string{filepath.Join(dir, "d"), filepath.Join(dir, "x*.csv"), filepath.Join(dir, "y.csv")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(Equal([]string{
+			filepath.Join(dir, "d", "a.csv"), filepath.Join(dir, "d", "b.csv"),
+			filepath.Join(dir, "x1.csv"), filepath.Join(dir, "x2.csv"), filepath.Join(dir, "y.csv"),
+		}))
+
+		_, err = expandPaths([]string{filepath.Join(dir, "z*.csv")})
+		Expect(err).To(MatchError(ContainSubstring("no data file matches")))
+		_, err = expandPaths([]string{filepath.Join(dir, "z.csv")})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should decompress the files by extension or magic bytes", func() {
+		content := "2022-04-19 00:00:00|v1|1.5|\n2022-04-19 00:00:01|v2||note\n"
+
+		gz := &bytes.Buffer{}
+		gw := gzip.NewWriter(gz)
+		_, err := gw.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gw.Close()).To(Succeed())
+
+		zw, err := zstd.NewWriter(nil)
+		Expect(err).NotTo(HaveOccurred())
+		zst := zw.EncodeAll([]byte(content), nil)
+
+		// a frame of an uncompressed block
+		lz := append([]byte{0x04, 0x22, 0x4D, 0x18, 0x60, 0x40, 0}, binary.LittleEndian.AppendUint32(nil, uint32(len(content))|0x80000000)...)
+		lz = append(append(lz, content...), 0, 0, 0, 0)
+
+		for name, data := range map[string][]byte{
+			"a.txt": []byte(content), "a.txt.gz": gz.Bytes(), "a.zst": zst, "a.txt.lz4": lz,
+			"gz.dat": gz.Bytes(), "zst.dat": zst, "lz4.dat": lz,
+		} {
+			path := filepath.Join(dir, name)
+			Expect(os.WriteFile(path, data, 0644)).To(Succeed())
+			r, err := openFile(path)
+			Expect(err).NotTo(HaveOccurred(), name)
+			out, err := io.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred(), name)
+			Expect(r.Close()).To(Succeed())
+			Expect(string(out)).To(Equal(content), name)
+		}
+
+		_, err = openFile(writeFile("b.gz", content))
+		Expect(err).To(MatchError(ContainSubstring("as gzip compressed")))
+	})
+
+	It("should load the files concurrently", func() {
+		var paths []string
+		expected := map[string]bool{}
+		for i := 0; i < 10; i++ {
+			content := &strings.Builder{}
+			for j := 0; j < 1000; j++ {
+				line := fmt.Sprintf("2022-04-19 00:00:00|v%d|%d|%s", i, j, strings.Repeat("x", 1000))
+				content.WriteString(line + "\n")
+				expected[line] = true
+			}
+			paths = append(paths, writeFile(fmt.Sprintf("%d.txt", i), content.String()))
+		}
+
+		g := newGenerator(&Config{FilePaths: paths, FileConcurrency: 3})
+		Expect(g.err).NotTo(HaveOccurred())
+		var lines int64
+		loaded := map[string]bool{}
+		writeFunc := func(data []byte, l, size int64) error {
+			Expect(size).To(BeNumerically("<=", bufferSize))
+			lines += l
+			for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+				loaded[line] = true
+			}
+			return nil
+		}
+		load := func(i int, b *batch) error {
+			return loadLines(paths[i], b)
+		}
+		Expect(g.loadFiles(len(paths), writeFunc, load)).To(Succeed())
+		Expect(lines).To(Equal(int64(10000)))
+		Expect(loaded).To(Equal(expected))
+
+		paths[5] = filepath.Join(dir, "missing.txt")
+		Expect(g.loadFiles(len(paths), writeFunc, load)).To(MatchError(ContainSubstring("missing.txt")))
+	})
+
+	It("should reject invalid formats", func() {
+		Expect((&Config{FileFormat: "orc"}).init()).To(HaveOccurred())
+		Expect((&Config{FileFormat: _FORMAT_CSV, CSVDelimiter: ",,"}).init()).To(HaveOccurred())
+		Expect((&Config{FileFormat: _FORMAT_CSV, CSVDelimiter: "\t"}).init()).To(Succeed())
+		Expect((&Config{FileConcurrency: -1}).init()).To(MatchError(ContainSubstring("should not be negative")))
+		cfg := &Config{}
+		Expect(cfg.init()).To(Succeed())
+		Expect(cfg.FileConcurrency).To(Equal(1))
+	})
+})
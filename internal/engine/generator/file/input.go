@@ -0,0 +1,145 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/lz4"
+)
+
+type compression = string
+
+const (
+	_COMPRESSION_NONE compression = ""
+	_COMPRESSION_GZIP compression = "gzip"
+	_COMPRESSION_ZSTD compression = "zstd"
+	_COMPRESSION_LZ4  compression = "lz4"
+)
+
+var (
+	compressionExtensions = map[string]compression{
+		".gz":   _COMPRESSION_GZIP,
+		".gzip": _COMPRESSION_GZIP,
+		".zst":  _COMPRESSION_ZSTD,
+		".zstd": _COMPRESSION_ZSTD,
+		".lz4":  _COMPRESSION_LZ4,
+	}
+	compressionMagics = map[compression][]byte{
+		_COMPRESSION_GZIP: {0x1F, 0x8B},
+		_COMPRESSION_ZSTD: {0x28, 0xB5, 0x2F, 0xFD},
+		_COMPRESSION_LZ4:  {0x04, 0x22, 0x4D, 0x18},
+	}
+)
+
+// expandPaths returns the data files of the paths in order,
+// where a glob pattern is expanded to the files matched in lexical order,
+// and a directory to the files right under it in lexical order.
+func expandPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		matches := []string{path}
+		if strings.ContainsAny(path, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(path); err != nil {
+				return nil, mxerror.CommonErrorf("invalid pattern %s of generator-file-paths: %v", path, err)
+			}
+			if len(matches) == 0 {
+				return nil, mxerror.CommonErrorf("no data file matches %s", path)
+			}
+		}
+		for _, match := range matches {
+			fileInfo, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !fileInfo.IsDir() {
+				files = append(files, match)
+				continue
+			}
+			entries, err := os.ReadDir(match)
+			if err != nil {
+				return nil, err
+			}
+			// os.ReadDir sorts the entries by name
+			for _, entry := range entries {
+				if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+					files = append(files, filepath.Join(match, entry.Name()))
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// openFile opens the data file, decompressing it if it is compressed,
+// which is told by the extension of the file, or by the magic bytes of the data otherwise.
+func openFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReaderSize(f, 64*1024)
+
+	c, ok := compressionExtensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		c = detectCompression(r)
+	}
+	rc, err := decompress(c, r)
+	if err != nil {
+		f.Close()
+		return nil, mxerror.CommonErrorf("failed to open %s as %s compressed: %v", path, c, err)
+	}
+	return &compressedFile{ReadCloser: rc, f: f}, nil
+}
+
+func detectCompression(r *bufio.Reader) compression {
+	// a shorter file is returned as it is
+	head, _ := r.Peek(4)
+	names := make([]string, 0, len(compressionMagics))
+	for c := range compressionMagics {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	for _, c := range names {
+		if bytes.HasPrefix(head, compressionMagics[c]) {
+			return c
+		}
+	}
+	return _COMPRESSION_NONE
+}
+
+func decompress(c compression, r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case _COMPRESSION_GZIP:
+		return gzip.NewReader(r)
+	case _COMPRESSION_ZSTD:
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case _COMPRESSION_LZ4:
+		return io.NopCloser(lz4.NewReader(r)), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+// compressedFile closes the file after its decompressor
+type compressedFile struct {
+	io.ReadCloser
+	f *os.File
+}
+
+func (c *compressedFile) Close() error {
+	c.ReadCloser.Close()
+	return c.f.Close()
+}
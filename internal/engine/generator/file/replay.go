@@ -0,0 +1,227 @@
+package file
+
+import (
+	"io"
+	"time"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+// timestamps of the files are parsed by the first layout matched,
+// and formatted in the same layout after replayed
+var tsLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+func parseTimestamp(v string) (time.Time, string, error) {
+	for _, layout := range tsLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", mxerror.CommonErrorf("invalid timestamp %q to replay", v)
+}
+
+// replayer replays the rows of the files round after round, shifting their timestamps
+// from the earliest one of the files onto [ts-start, ts-end), or onto the current time
+// in realtime mode, and remapping their VINs to the VINs of the run.
+type replayer struct {
+	tsIndex, vinIndex int
+
+	// srcStart is the earliest timestamp of the files,
+	// span is from it to the latest one plus a step, by which the rounds are apart
+	srcStart, srcEnd time.Time
+	span             time.Duration
+
+	isRealtime bool
+	speed      float64
+	// the rows are replayed from startAt until endAt, or from wallTime in realtime mode
+	startAt, endAt time.Time
+	wallTime       time.Time
+
+	// vins are the indexes of the VINs of the files in order of their first rows,
+	// which are remapped to the VINs of the run, as many copies as targets can take
+	vins    map[string]int
+	targets []string
+}
+
+func newReplayer(cfg *Config, gcfg engine.GlobalConfig, table *metadata.Table) (*replayer, error) {
+	rp := &replayer{
+		tsIndex:    -1,
+		vinIndex:   -1,
+		isRealtime: gcfg.IsRealtimeMode,
+		speed:      cfg.ReplaySpeed,
+		startAt:    gcfg.StartAt,
+		endAt:      gcfg.EndAt,
+	}
+	for i, col := range table.Columns {
+		switch col.Name {
+		case table.ColumnNameTS:
+			rp.tsIndex = i
+		case table.ColumnNameVIN:
+			rp.vinIndex = i
+		}
+	}
+	if rp.tsIndex < 0 {
+		return nil, mxerror.CommonError("no timestamp column in the table to replay")
+	}
+	if cfg.ReplayRemapVIN {
+		if rp.vinIndex < 0 || len(table.VinValues) == 0 {
+			return nil, mxerror.CommonError("no vin column or VINs of the table to remap to")
+		}
+		rp.vins = map[string]int{}
+		rp.targets = table.VinValues
+	}
+	rp.span = time.Duration(gcfg.TimestampStepInSecond) * time.Second
+	if rp.span <= 0 {
+		rp.span = time.Second
+	}
+	return rp, nil
+}
+
+// scan collects the time range and the VINs of the files
+func (rp *replayer) scan(values []*string) error {
+	if values[rp.tsIndex] == nil {
+		return mxerror.CommonError("null timestamp to replay")
+	}
+	t, _, err := parseTimestamp(*values[rp.tsIndex])
+	if err != nil {
+		return err
+	}
+	if rp.srcStart.IsZero() || t.Before(rp.srcStart) {
+		rp.srcStart = t
+	}
+	if t.After(rp.srcEnd) {
+		rp.srcEnd = t
+	}
+	if rp.vins != nil && values[rp.vinIndex] != nil {
+		if _, ok := rp.vins[*values[rp.vinIndex]]; !ok {
+			rp.vins[*values[rp.vinIndex]] = len(rp.vins)
+		}
+	}
+	return nil
+}
+
+// start begins replaying after the files are scanned
+func (rp *replayer) start() {
+	rp.span += rp.srcEnd.Sub(rp.srcStart)
+	rp.wallTime = time.Now()
+}
+
+// isDone tells whether the rows of the round are all beyond the window
+func (rp *replayer) isDone(round int) bool {
+	if rp.srcStart.IsZero() {
+		// no row at all
+		return true
+	}
+	if rp.isRealtime {
+		return false
+	}
+	return !rp.startAt.Add(time.Duration(round) * rp.span).Before(rp.endAt)
+}
+
+// shift returns the timestamp of t in the round, false if it is beyond the window
+func (rp *replayer) shift(t time.Time, round int) (time.Time, bool) {
+	elapsed := t.Sub(rp.srcStart) + time.Duration(round)*rp.span
+	if rp.isRealtime {
+		return rp.wallTime.Add(time.Duration(float64(elapsed) / rp.speed)), true
+	}
+	shifted := rp.startAt.Add(elapsed)
+	return shifted, shifted.Before(rp.endAt)
+}
+
+// droppedVINs returns the number of the VINs of the files beyond the VINs of the run,
+// whose rows are not replayed as there is no VIN left to remap them to.
+func (rp *replayer) droppedVINs() int {
+	if len(rp.vins) > len(rp.targets) {
+		return len(rp.vins) - len(rp.targets)
+	}
+	return 0
+}
+
+// replay adds the row of the timestamp t shifted into the round to the batch, as copies of the VINs remapped.
+// In realtime mode, it waits until the timestamp of the row.
+func (rp *replayer) replay(values []*string, t time.Time, layout string, round int, b *batch) error {
+	t, ok := rp.shift(t, round)
+	if !ok {
+		return nil
+	}
+	if rp.isRealtime {
+		if err := b.waitUntil(t); err != nil {
+			return err
+		}
+	}
+	ts := t.Format(layout)
+	values[rp.tsIndex] = &ts
+
+	if rp.vins == nil || values[rp.vinIndex] == nil {
+		return b.add(values)
+	}
+	for n := rp.vins[*values[rp.vinIndex]]; n < len(rp.targets); n += len(rp.vins) {
+		values[rp.vinIndex] = &rp.targets[n]
+		if err := b.add(values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowCursor is at the current row of a file of the files merged
+type rowCursor struct {
+	file     int
+	filePath string
+	r        recordReader
+	indexes  []int
+
+	values []*string
+	t      time.Time
+	layout string
+}
+
+// next moves the cursor to the next row, false at the end of the file
+func (c *rowCursor) next(tsIndex int) (bool, error) {
+	row, err := c.r.Next()
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, mxerror.CommonErrorf("failed to read %s: %v", c.filePath, err)
+	}
+	mapRow(row, c.indexes, c.values)
+	if c.values[tsIndex] == nil {
+		return false, mxerror.CommonError("null timestamp to replay")
+	}
+	if c.t, c.layout, err = parseTimestamp(*c.values[tsIndex]); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rowHeap orders the cursors by the timestamps of their rows, and then by the order of the files
+type rowHeap []*rowCursor
+
+func (h rowHeap) Len() int { return len(h) }
+
+func (h rowHeap) Less(i, j int) bool {
+	if !h[i].t.Equal(h[j].t) {
+		return h[i].t.Before(h[j].t)
+	}
+	return h[i].file < h[j].file
+}
+
+func (h rowHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *rowHeap) Push(x interface{}) { *h = append(*h, x.(*rowCursor)) }
+
+func (h *rowHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,254 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/parquet"
+)
+
+type FileFormat = string
+
+const (
+	// _FORMAT_TEXT files are loaded line by line as they are, unless replayed
+	_FORMAT_TEXT FileFormat = "text"
+	// _FORMAT_CSV files have a header of the column names
+	_FORMAT_CSV     FileFormat = "csv"
+	_FORMAT_PARQUET FileFormat = "parquet"
+)
+
+// recordReader reads the rows of a data file whose columns are named,
+// by a header or by the schema.
+type recordReader interface {
+	Columns() []string
+	// Next returns the values of the next row, where nulls are nil, or io.EOF
+	Next() ([]*string, error)
+	Close() error
+}
+
+func openRecordReader(cfg *Config, table *metadata.Table, path string) (recordReader, error) {
+	switch cfg.FileFormat {
+	case _FORMAT_TEXT:
+		// the lines are of the columns of the table
+		columns := make([]string, 0, len(table.Columns))
+		for _, col := range table.Columns {
+			columns = append(columns, col.Name)
+		}
+		return openCSV(path, []rune(util.DELIMITER)[0], columns)
+	case _FORMAT_CSV:
+		return openCSV(path, cfg.csvDelimiter, nil)
+	case _FORMAT_PARQUET:
+		return parquet.Open(path)
+	}
+	return nil, mxerror.CommonErrorf("unsupported generator-file-format: %s", cfg.FileFormat)
+}
+
+type csvReader struct {
+	f       io.ReadCloser
+	r       *csv.Reader
+	columns []string
+}
+
+// openCSV opens the CSV file, whose columns are given, or in the header if not.
+func openCSV(path string, delimiter rune, columns []string) (*csvReader, error) {
+	f, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(f)
+	r.Comma = delimiter
+	r.ReuseRecord = true
+	if columns != nil {
+		r.FieldsPerRecord = len(columns)
+		return &csvReader{f: f, r: r, columns: columns}, nil
+	}
+	header, err := r.Read()
+	if err != nil {
+		f.Close()
+		if err == io.EOF {
+			return nil, mxerror.CommonErrorf("no header in %s", path)
+		}
+		return nil, err
+	}
+	return &csvReader{f: f, r: r, columns: append([]string(nil), header...)}, nil
+}
+
+func (c *csvReader) Columns() []string {
+	return c.columns
+}
+
+// Next returns the fields of the next line, where empty fields are nulls
+func (c *csvReader) Next() ([]*string, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]*string, len(record))
+	for i := range record {
+		if record[i] != "" {
+			v := record[i]
+			row[i] = &v
+		}
+	}
+	return row, nil
+}
+
+func (c *csvReader) Close() error {
+	return c.f.Close()
+}
+
+// mapColumns returns the index of the source column of each column of the table,
+// -1 if the table column is not in the source and loaded as null, and those missing columns.
+// The source columns should all be in the table, as well as the timestamp and vin columns in the source.
+func mapColumns(table *metadata.Table, path string, sourceColumns []string) ([]int, []string, error) {
+	sourceIndexes := make(map[string]int, len(sourceColumns))
+	for i, name := range sourceColumns {
+		if _, ok := sourceIndexes[name]; ok {
+			return nil, nil, mxerror.CommonErrorf("column %s of %s is duplicated", name, path)
+		}
+		sourceIndexes[name] = i
+	}
+
+	indexes := make([]int, len(table.Columns))
+	tableColumns := make(map[string]struct{}, len(table.Columns))
+	var missing []string
+	for i, col := range table.Columns {
+		tableColumns[col.Name] = struct{}{}
+		idx, ok := sourceIndexes[col.Name]
+		if !ok {
+			idx = -1
+			missing = append(missing, col.Name)
+		}
+		indexes[i] = idx
+	}
+
+	var unknown []string
+	for _, name := range sourceColumns {
+		if _, ok := tableColumns[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, nil, mxerror.CommonErrorf("columns %s of %s are not in table %s",
+			strings.Join(unknown, ", "), path, table.Identifier())
+	}
+	for _, name := range []string{table.ColumnNameTS, table.ColumnNameVIN} {
+		if _, ok := sourceIndexes[name]; name != "" && !ok {
+			return nil, nil, mxerror.CommonErrorf("column %s of table %s is not in %s", name, table.Identifier(), path)
+		}
+	}
+	return indexes, missing, nil
+}
+
+var errCanceled = errors.New("generator is closed")
+
+// batch buffers the rows to write, and flushes them once over batchLimit
+type batch struct {
+	ctx       context.Context
+	buff      *bytes.Buffer
+	lines     int64
+	writeFunc engine.WriteFunc
+}
+
+func (b *batch) add(values []*string) error {
+	if b.buff.Len() > batchLimit {
+		if err := b.flush(); err != nil {
+			return err
+		}
+	}
+	writeRow(b.buff, values)
+	b.lines++
+	return nil
+}
+
+// addLine adds the line as it is
+func (b *batch) addLine(line []byte) error {
+	if b.buff.Len() > batchLimit {
+		if err := b.flush(); err != nil {
+			return err
+		}
+	}
+	b.buff.Write(line)
+	b.buff.WriteByte('\n')
+	b.lines++
+	return nil
+}
+
+func (b *batch) flush() error {
+	select {
+	case <-b.ctx.Done():
+		return errCanceled
+	default:
+	}
+	if b.lines == 0 {
+		return nil
+	}
+	if err := b.writeFunc(b.buff.Bytes(), b.lines, int64(b.buff.Len())); err != nil {
+		return err
+	}
+	b.buff.Reset()
+	b.lines = 0
+	return nil
+}
+
+// waitUntil flushes the rows before t, and waits until t
+func (b *batch) waitUntil(t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+	if err := b.flush(); err != nil {
+		return err
+	}
+	select {
+	case <-b.ctx.Done():
+		return errCanceled
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// writeRow writes the values of the columns of the table,
+// in the CSV format delimited by util.DELIMITER that the writers load.
+func writeRow(buff *bytes.Buffer, values []*string) {
+	for i, v := range values {
+		if i > 0 {
+			buff.WriteString(util.DELIMITER)
+		}
+		if v == nil {
+			// an unquoted empty value is null
+			continue
+		}
+		writeValue(buff, *v)
+	}
+	buff.WriteByte('\n')
+}
+
+func writeValue(buff *bytes.Buffer, v string) {
+	if v != "" && !strings.ContainsAny(v, util.DELIMITER+"\"\r\n") {
+		buff.WriteString(v)
+		return
+	}
+	buff.WriteByte('"')
+	buff.WriteString(strings.ReplaceAll(v, "\"", "\"\""))
+	buff.WriteByte('"')
+}
+
+// parseDelimiter returns the only character of the delimiter
+func parseDelimiter(delimiter string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || r == '"' || r == '\n' || r == '\r' {
+		return 0, mxerror.CommonErrorf("invalid generator-csv-delimiter: %q", delimiter)
+	}
+	return r, nil
+}
@@ -21,6 +21,9 @@ const (
 	_RANDOMNESS_LEVEL_SMALL  RandomnessLevel = "S"
 	_RANDOMNESS_LEVEL_MEDIUM RandomnessLevel = "M"
 	_RANDOMNESS_LEVEL_LARGE  RandomnessLevel = "L"
+	// _RANDOMNESS_LEVEL_PER_VIN generates the rows of each device on its own,
+	// instead of copying them from the template
+	_RANDOMNESS_LEVEL_PER_VIN RandomnessLevel = "PER-VIN"
 )
 
 type Config struct {
@@ -32,6 +35,13 @@ type Config struct {
 	NumGoRoutine    int             `mapstructure:"generator-num-goroutine"`
 	AddComment      bool            `mapstructure:"generator-add-comment"`
 
+	RateLimit          float64     `mapstructure:"generator-rate-limit"`
+	RateUnit           string      `mapstructure:"generator-rate-unit"`
+	RateProfile        RateProfile `mapstructure:"generator-rate-profile"`
+	RatePeak           float64     `mapstructure:"generator-rate-peak"`
+	RatePeriodInSecond int         `mapstructure:"generator-rate-period-in-second"`
+	RateSteps          int         `mapstructure:"generator-rate-steps"`
+
 	templateSize      int64
 	percentOfOutOrder int
 	batchLine         int
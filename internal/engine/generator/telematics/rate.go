@@ -0,0 +1,176 @@
+package telematics
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+type RateProfile = string
+
+const (
+	_RATE_PROFILE_CONSTANT RateProfile = "constant"
+	_RATE_PROFILE_STEP     RateProfile = "step"
+	_RATE_PROFILE_RAMP     RateProfile = "ramp"
+	_RATE_PROFILE_SINE     RateProfile = "sine"
+
+	// the longest time to sleep before the target rate is re-evaluated
+	_MAX_RATE_WAIT = 100 * time.Millisecond
+)
+
+func (cfg *Config) validateRate() error {
+	if cfg.RateLimit < 0 {
+		return mxerror.CommonErrorf("generator-rate-limit should not be negative, got %f", cfg.RateLimit)
+	}
+	if cfg.RateLimit == 0 {
+		return nil
+	}
+	if !strings.EqualFold(cfg.RateUnit, engine.RateUnitRows) && !strings.EqualFold(cfg.RateUnit, engine.RateUnitMB) {
+		return mxerror.CommonErrorf("generator-rate-unit should be %s or %s, got %s", engine.RateUnitRows, engine.RateUnitMB, cfg.RateUnit)
+	}
+	switch cfg.RateProfile {
+	case _RATE_PROFILE_CONSTANT:
+		return nil
+	case _RATE_PROFILE_STEP, _RATE_PROFILE_RAMP, _RATE_PROFILE_SINE:
+	default:
+		return mxerror.CommonErrorf("generator-rate-profile should be one of constant/step/ramp/sine, got %s", cfg.RateProfile)
+	}
+	// the rate stays at the peak after a period, a peak of 0 would stop the generator for good
+	if cfg.RatePeak <= 0 {
+		return mxerror.CommonErrorf("generator-rate-peak should be positive with profile %s, got %f", cfg.RateProfile, cfg.RatePeak)
+	}
+	if cfg.RatePeriodInSecond <= 0 {
+		return mxerror.CommonErrorf("generator-rate-period-in-second should be positive, got %d", cfg.RatePeriodInSecond)
+	}
+	if cfg.RateProfile == _RATE_PROFILE_STEP && cfg.RateSteps < 2 {
+		return mxerror.CommonErrorf("generator-rate-steps should be at least 2, got %d", cfg.RateSteps)
+	}
+	return nil
+}
+
+// rateLimiter throttles the output of the generator to the rate of the profile.
+// It is a token bucket whose refilling speed varies over time,
+// at most one second of output is allowed to accumulate.
+type rateLimiter struct {
+	cfg  *Config
+	unit engine.RateUnit
+
+	mu        sync.Mutex
+	startAt   time.Time
+	lastAt    time.Time
+	allowance float64
+}
+
+func newRateLimiter(cfg *Config) *rateLimiter {
+	if cfg.RateLimit <= 0 {
+		return nil
+	}
+	unit := engine.RateUnitRows
+	if strings.EqualFold(cfg.RateUnit, engine.RateUnitMB) {
+		unit = engine.RateUnitMB
+	}
+	return &rateLimiter{cfg: cfg, unit: unit}
+}
+
+func (l *rateLimiter) GetUnit() engine.RateUnit {
+	return l.unit
+}
+
+func (l *rateLimiter) GetCurrentRate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.startAt.IsZero() {
+		return l.rateAt(0)
+	}
+	return l.rateAt(time.Since(l.startAt))
+}
+
+// rateAt returns the target rate after the generating has lasted for elapsed:
+//   - constant: always generator-rate-limit;
+//   - step: climbs from generator-rate-limit to generator-rate-peak
+//     in generator-rate-steps equal steps within the period, then stays at the peak;
+//   - ramp: climbs linearly from generator-rate-limit to generator-rate-peak
+//     within the period, then stays at the peak;
+//   - sine: oscillates between generator-rate-limit and generator-rate-peak,
+//     starting from generator-rate-limit, one cycle per period.
+func (l *rateLimiter) rateAt(elapsed time.Duration) float64 {
+	base, peak := l.cfg.RateLimit, l.cfg.RatePeak
+	period := time.Duration(l.cfg.RatePeriodInSecond) * time.Second
+
+	switch l.cfg.RateProfile {
+	case _RATE_PROFILE_STEP:
+		if elapsed >= period {
+			return peak
+		}
+		step := int64(elapsed) * int64(l.cfg.RateSteps) / int64(period)
+		return base + (peak-base)*float64(step)/float64(l.cfg.RateSteps-1)
+	case _RATE_PROFILE_RAMP:
+		if elapsed >= period {
+			return peak
+		}
+		return base + (peak-base)*float64(elapsed)/float64(period)
+	case _RATE_PROFILE_SINE:
+		mid, amplitude := (base+peak)/2, (peak-base)/2
+		return mid - amplitude*math.Cos(2*math.Pi*float64(elapsed)/float64(period))
+	default:
+		return base
+	}
+}
+
+// wait blocks until the given output is allowed by the target rate.
+// A batch larger than one second of output is let go as soon as the bucket is full,
+// and the debt is paid by the batches after it.
+func (l *rateLimiter) wait(ctx context.Context, rows, bytes int64) {
+	if l == nil {
+		return
+	}
+
+	amount := float64(rows)
+	if l.unit == engine.RateUnitMB {
+		amount = float64(bytes) / _MEGA_BYTES
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		if l.startAt.IsZero() {
+			// start with a full bucket
+			l.startAt, l.lastAt = now, now
+			l.allowance = l.rateAt(0)
+		}
+		rate := l.rateAt(now.Sub(l.startAt))
+		l.allowance += rate * now.Sub(l.lastAt).Seconds()
+		l.lastAt = now
+		if l.allowance > rate {
+			l.allowance = rate
+		}
+
+		need := math.Min(amount, rate)
+		if rate > 0 && l.allowance >= need {
+			l.allowance -= amount
+			l.mu.Unlock()
+			return
+		}
+
+		waitFor := _MAX_RATE_WAIT
+		if rate > 0 {
+			if d := time.Duration((need - l.allowance) / rate * float64(time.Second)); d < waitFor {
+				waitFor = d
+			}
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(waitFor)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package telematics
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate Limiter", func() {
+	It("should not be created without rate limit", func() {
+		Expect(newRateLimiter(&Config{})).To(BeNil())
+	})
+
+	It("constant profile", func() {
+		l := newRateLimiter(&Config{RateLimit: 100, RateUnit: "rows", RateProfile: _RATE_PROFILE_CONSTANT})
+		Expect(l.GetUnit()).To(Equal("rows"))
+		Expect(l.rateAt(0)).To(Equal(float64(100)))
+		Expect(l.rateAt(time.Hour)).To(Equal(float64(100)))
+	})
+
+	It("step profile", func() {
+		l := newRateLimiter(&Config{RateLimit: 100, RatePeak: 400, RateUnit: "MB", RateProfile: _RATE_PROFILE_STEP,
+			RatePeriodInSecond: 40, RateSteps: 4})
+		Expect(l.GetUnit()).To(Equal("MB"))
+		Expect(l.rateAt(0)).To(Equal(float64(100)))
+		Expect(l.rateAt(9 * time.Second)).To(Equal(float64(100)))
+		Expect(l.rateAt(10 * time.Second)).To(Equal(float64(200)))
+		Expect(l.rateAt(35 * time.Second)).To(Equal(float64(400)))
+		Expect(l.rateAt(time.Hour)).To(Equal(float64(400)))
+	})
+
+	It("ramp profile", func() {
+		l := newRateLimiter(&Config{RateLimit: 100, RatePeak: 300, RateProfile: _RATE_PROFILE_RAMP, RatePeriodInSecond: 10})
+		Expect(l.rateAt(0)).To(Equal(float64(100)))
+		Expect(l.rateAt(5 * time.Second)).To(Equal(float64(200)))
+		Expect(l.rateAt(time.Minute)).To(Equal(float64(300)))
+	})
+
+	It("sine profile", func() {
+		l := newRateLimiter(&Config{RateLimit: 100, RatePeak: 300, RateProfile: _RATE_PROFILE_SINE, RatePeriodInSecond: 10})
+		Expect(l.rateAt(0)).To(BeNumerically("~", 100, 1e-9))
+		Expect(l.rateAt(5 * time.Second)).To(BeNumerically("~", 300, 1e-9))
+		Expect(l.rateAt(10 * time.Second)).To(BeNumerically("~", 100, 1e-9))
+	})
+
+	It("should throttle the output", func() {
+		l := newRateLimiter(&Config{RateLimit: 1000, RateUnit: "rows", RateProfile: _RATE_PROFILE_CONSTANT})
+		st := time.Now()
+		// the first second is allowed by the full bucket
+		for i := 0; i < 15; i++ {
+			l.wait(context.Background(), 100, 0)
+		}
+		Expect(time.Since(st)).To(BeNumerically(">=", 400*time.Millisecond))
+	})
+
+	It("should validate the config", func() {
+		Expect((&Config{}).validateRate()).To(Succeed())
+		Expect((&Config{RateLimit: -1}).validateRate()).NotTo(Succeed())
+		Expect((&Config{RateLimit: 1, RateUnit: "GB", RateProfile: _RATE_PROFILE_CONSTANT}).validateRate()).NotTo(Succeed())
+		Expect((&Config{RateLimit: 1, RateUnit: "rows", RateProfile: "square"}).validateRate()).NotTo(Succeed())
+		Expect((&Config{RateLimit: 1, RatePeak: 2, RateUnit: "rows", RateProfile: _RATE_PROFILE_RAMP}).validateRate()).NotTo(Succeed())
+		Expect((&Config{RateLimit: 1, RatePeak: 2, RateUnit: "rows", RateProfile: _RATE_PROFILE_STEP, RatePeriodInSecond: 10, RateSteps: 1}).validateRate()).NotTo(Succeed())
+		Expect((&Config{RateLimit: 1, RatePeak: 2, RateUnit: "mb", RateProfile: _RATE_PROFILE_SINE, RatePeriodInSecond: 10}).validateRate()).To(Succeed())
+	})
+
+	It("should require a positive peak of the profiles varying the rate", func() {
+		for _, profile := range []RateProfile{_RATE_PROFILE_STEP, _RATE_PROFILE_RAMP, _RATE_PROFILE_SINE} {
+			cfg := &Config{RateLimit: 1, RateUnit: "rows", RateProfile: profile, RatePeriodInSecond: 10, RateSteps: 2}
+			Expect(cfg.validateRate()).To(MatchError(ContainSubstring("generator-rate-peak should be positive")), profile)
+			cfg.RatePeak = 2
+			Expect(cfg.validateRate()).To(Succeed(), profile)
+		}
+		Expect((&Config{RateLimit: 1, RateUnit: "rows", RateProfile: _RATE_PROFILE_CONSTANT}).validateRate()).To(Succeed())
+	})
+})
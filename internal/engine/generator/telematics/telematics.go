@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -32,9 +33,28 @@ type Generator struct {
 	writeFunc  engine.WriteFunc
 	wg         sync.WaitGroup
 
+	rateLimiter *rateLimiter
+
 	cacheBuff []*bytes.Buffer
+
+	// devices and mocker generate the rows of each device in PER-VIN mode
+	devices *typ.Devices
+	mocker  *mxmock.MXMocker
+
+	// rnd generates the rows, and disorderRnd picks the rows out of order,
+	// both derived from the seed of the run
+	rnd         *rand.Rand
+	disorderRnd *rand.Rand
+
+	// genTime and writeTime accumulate the nanoseconds spent in generating data and calling write function,
+	// of the table of the generator only
+	genTime   int64
+	writeTime int64
 }
 
+// typMu guards mxmock.TypMap, which is shared by the tables of a multi-table run
+var typMu sync.Mutex
+
 func NewGenerator(cfg engine.GeneratorConfig) engine.IGenerator {
 	gCfg := cfg.PluginConfig.(*Config)
 	gCfg.init()
@@ -44,13 +64,17 @@ func NewGenerator(cfg engine.GeneratorConfig) engine.IGenerator {
 		cacheBuff[i] = bytes.NewBuffer(make([]byte, 0, gCfg.WriteBatchSize*_MEGA_BYTES/gCfg.NumGoRoutine))
 	}
 
+	seed := cfg.GlobalConfig.GetSeed()
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Generator{
-		gcfg:       *cfg.GlobalConfig,
-		cfg:        gCfg,
-		ctx:        ctx,
-		cancelFunc: cancel,
-		cacheBuff:  cacheBuff,
+		gcfg:        *cfg.GlobalConfig,
+		cfg:         gCfg,
+		ctx:         ctx,
+		cancelFunc:  cancel,
+		cacheBuff:   cacheBuff,
+		rateLimiter: newRateLimiter(gCfg),
+		rnd:         util.NewRand(seed, "generator"),
+		disorderRnd: util.NewRand(seed, "disorder"),
 	}
 }
 
@@ -63,10 +87,14 @@ func (g *Generator) GetPrediction(table *metadata.Table) (engine.GeneratorPredic
 	amountSize = amountSize * linesInTable *
 		(1 - float64(g.cfg.emptyValueRatio)/100)
 
-	return engine.GeneratorPrediction{
+	prediction := engine.GeneratorPrediction{
 		Count: int64(linesPerRow * linesInTable),
 		Size:  int64(amountSize),
-	}, nil
+	}
+	if g.rateLimiter != nil {
+		prediction.RateTarget = g.rateLimiter
+	}
+	return prediction, nil
 }
 
 func (g *Generator) ModifyMetadataConfig(metaConfig *metadata.Config) {
@@ -92,15 +120,21 @@ func (g *Generator) Run(cfg engine.GlobalConfig, meta *metadata.Metadata, writeF
 
 	log.Info("[Generator.TELEMATICS] Start to load to writer")
 
-	typ.Init(meta.Table)
-
-	tpl, err := g.genTpl()
-	if err != nil {
-		return err
-	}
-	if len(tpl) == 0 || len(tpl[0]) == 0 {
-		log.Info("[Generator.TELEMATICS] No data generated")
-		return nil
+	var tpl [][]string
+	if g.cfg.Randomness == _RANDOMNESS_LEVEL_PER_VIN {
+		g.devices = typ.NewDevices(int(g.gcfg.TagNum))
+		if g.mocker, err = g.newMocker(g.devices); err != nil {
+			return err
+		}
+	} else {
+		tpl, err = g.genTpl()
+		if err != nil {
+			return err
+		}
+		if len(tpl) == 0 || len(tpl[0]) == 0 {
+			log.Info("[Generator.TELEMATICS] No data generated")
+			return nil
+		}
 	}
 
 	err = g.write(tpl)
@@ -193,8 +227,8 @@ func (g *Generator) Close() error {
 	g.cancelFunc()
 	g.wg.Wait()
 
-	log.Verbose("[Generator.TELEMATICS] Gen time: %s", time.Duration(accGenTime))
-	log.Verbose("[Generator.TELEMATICS] Write time: %s", time.Duration(accWriteTime))
+	log.Verbose("[Generator.TELEMATICS] Gen time: %s", time.Duration(atomic.LoadInt64(&g.genTime)))
+	log.Verbose("[Generator.TELEMATICS] Write time: %s", time.Duration(atomic.LoadInt64(&g.writeTime)))
 	log.Verbose("[Generator.TELEMATICS] Misc time1: %s", time.Duration(accMiscTime1))
 	log.Verbose("[Generator.TELEMATICS] Write Cnt: %d", accWriteCnt)
 	log.Verbose("[Generator.TELEMATICS] Acc Size: %d", accWriteSize)
@@ -203,6 +237,15 @@ func (g *Generator) Close() error {
 	return nil
 }
 
+// CollectMetrics publishes the time accumulated in generating data and calling write function.
+func (g *Generator) CollectMetrics(mw *engine.MetricsWriter) {
+	label := engine.MetricLabel{Name: "generator", Value: "telematics"}
+	mw.Counter("mxbench_generator_gen_seconds_total", "The time spent in generating data",
+		time.Duration(atomic.LoadInt64(&g.genTime)).Seconds(), label)
+	mw.Counter("mxbench_generator_write_seconds_total", "The time spent in writing data",
+		time.Duration(atomic.LoadInt64(&g.writeTime)).Seconds(), label)
+}
+
 func (g *Generator) CreatePluginConfig() interface{} {
 	return &Config{}
 }
@@ -215,7 +258,9 @@ func (g *Generator) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	p.IntVar(&gCfg.BatchSize, "generator-batch-size", 1, "The number of lines of data generated for a tag of a given timestamp.\n"+
 		"e.g. It is set to be 5, then for tag \"tag1\" with ts of \"2022-04-02 15:04:03\",\n5 lines of data will be generated and sent into DBMS.\n"+
 		"Eventually, however, they will be merged as 1 row in DBMS.")
-	p.StringVar(&gCfg.Randomness, "generator-randomness", _RANDOMNESS_LEVEL_OFF, "The randomness of metrics, OFF/S/M/L")
+	p.StringVar(&gCfg.Randomness, "generator-randomness", _RANDOMNESS_LEVEL_OFF, "The randomness of metrics, OFF/S/M/L/PER-VIN.\n"+
+		"OFF/S/M/L copy the rows of all the devices from a template of 1/1200/3600/7200 rows,\n"+
+		"while PER-VIN keeps the metrics of each device evolving continuously across timestamps.")
 	p.IntVar(&gCfg.EmptyValueRatio, "generator-empty-value-ratio", 90, "the ratio of empty metrics value in one line.\n"+
 		"Expected to be an integer ranging from 0 to 100 (included).")
 
@@ -223,23 +268,38 @@ func (g *Generator) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	p.IntVar(&gCfg.WriteBatchSize, "generator-write-batch-size", 4, "the estimated mega bytes of batch size to call write function")
 	p.BoolVar(&gCfg.AddComment, "generator-add-comment", false, "add comment on columns, including min/max value of columns")
 
+	p.Float64Var(&gCfg.RateLimit, "generator-rate-limit", 0, "The target rate to throttle the output to, in the unit of generator-rate-unit per second.\n"+
+		"It is the start rate for step, ramp and sine profiles. 0 means generating as fast as possible.")
+	p.StringVar(&gCfg.RateUnit, "generator-rate-unit", engine.RateUnitRows, "The unit of the rate, rows/MB")
+	p.StringVar(&gCfg.RateProfile, "generator-rate-profile", _RATE_PROFILE_CONSTANT, "How the target rate varies over time, constant/step/ramp/sine")
+	p.Float64Var(&gCfg.RatePeak, "generator-rate-peak", 0, "The peak rate of step, ramp and sine profiles, which should be positive with them")
+	p.IntVar(&gCfg.RatePeriodInSecond, "generator-rate-period-in-second", 60, "The seconds that step and ramp profiles take to reach the peak,\n"+
+		"or the seconds of one cycle of sine profile")
+	p.IntVar(&gCfg.RateSteps, "generator-rate-steps", 5, "The number of steps of step profile")
+
 	_ = p.MarkHidden("generator-num-goroutine")
 	_ = p.MarkHidden("generator-write-batch-size")
 	return p, gCfg
 }
 
-var accMiscTime1, accGenTime, accWriteTime, accWriteSize int64
+var accMiscTime1, accWriteSize int64
 var accWriteCnt, maxWriteSize int
 
 func (g *Generator) generateAndWriteBatch(batches [][]string, tpl [][]string, ts time.Time) error {
 	st := time.Now()
-	startIdx := ts.Unix() % g.cfg.templateSize
-	copy(batches, tpl[startIdx:])
-	for i := g.cfg.templateSize - startIdx; i < g.gcfg.TagNum; i += g.cfg.templateSize {
-		copy(batches[i:], tpl)
+	if g.devices != nil {
+		if err := g.mockDevices(batches); err != nil {
+			return err
+		}
+		atomic.AddInt64(&g.genTime, time.Since(st).Nanoseconds())
+	} else {
+		startIdx := ts.Unix() % g.cfg.templateSize
+		copy(batches, tpl[startIdx:])
+		for i := g.cfg.templateSize - startIdx; i < g.gcfg.TagNum; i += g.cfg.templateSize {
+			copy(batches[i:], tpl)
+		}
+		accMiscTime1 += time.Since(st).Nanoseconds()
 	}
-	tt := time.Now()
-	accMiscTime1 += tt.Sub(st).Nanoseconds()
 
 	// judge the number of tag num to form a batch
 	// TODO: extract to the caller and save the results in generator
@@ -249,11 +309,11 @@ func (g *Generator) generateAndWriteBatch(batches [][]string, tpl [][]string, ts
 	for _, index := range tagIndexRanges {
 		// TODO: batchRowSize is not important or necessary
 		st = time.Now()
-		batchData, batchLines, batchRowSize := g.generateBatch(g.meta.Table.VinValues[lastIndex:index], ts, batches)
+		batchData, batchLines, batchRowSize := g.generateBatch(g.meta.Table.VinValues[lastIndex:index], ts, batches[lastIndex:index])
 		numOfDataBatch := len(batchData)
 
-		tt = time.Now()
-		accGenTime += tt.Sub(st).Nanoseconds()
+		tt := time.Now()
+		atomic.AddInt64(&g.genTime, tt.Sub(st).Nanoseconds())
 
 		// log.Info("Gen %d batches for %s: %d~%d\n", len(batchData), ts, lastIndex, index)
 
@@ -269,6 +329,7 @@ func (g *Generator) generateAndWriteBatch(batches [][]string, tpl [][]string, ts
 				}
 				iInside := i
 				eg.Go(func() error {
+					g.rateLimiter.wait(g.ctx, batchLines[iInside], batchRowSize[iInside])
 					// log.Info("    %d batches len = %d\n", iInside, len(batchData[iInside]))
 					// if iInside == 0 {
 					// 	log.Info("    %d batches %s\n", iInside, batchData[iInside])
@@ -285,12 +346,13 @@ func (g *Generator) generateAndWriteBatch(batches [][]string, tpl [][]string, ts
 			if maxWriteSize < size {
 				maxWriteSize = size
 			}
+			g.rateLimiter.wait(g.ctx, batchLines[0], batchRowSize[0])
 			err := g.writeFunc(batchData[0], batchLines[0], batchRowSize[0])
 			if err != nil {
 				return err
 			}
 		}
-		accWriteTime += time.Since(tt).Nanoseconds()
+		atomic.AddInt64(&g.writeTime, time.Since(tt).Nanoseconds())
 		lastIndex = index
 	}
 	return nil
@@ -344,9 +406,30 @@ func (g *Generator) write(tpl [][]string) (err error) {
 	return err
 }
 
-func (g *Generator) genTpl() ([][]string, error) {
+// newMocker creates a mocker of the metrics columns of the table,
+// devices are given in PER-VIN mode.
+func (g *Generator) newMocker(devices *typ.Devices) (*mxmock.MXMocker, error) {
+	typMu.Lock()
+	defer typMu.Unlock()
+
 	table := g.meta.Table
-	mocker, err := mxmock.NewMXMockerFromColumns(table.Columns)
+	typ.Init(table, g.rnd, devices)
+
+	// the types unknown to mxmock are enums of the table from DDL file,
+	// whose labels are read from database
+	var conn *sqlx.DB
+	for _, col := range table.Columns {
+		if _, ok := mxmock.TypMap[col.TypeName]; ok {
+			continue
+		}
+		var err error
+		if conn, err = util.CreateDBConnection(g.meta.Cfg.DB); err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		break
+	}
+	mocker, err := mxmock.NewMXMockerFromColumnsWithConn(conn, table.Columns, g.rnd)
 	if err != nil {
 		return nil, err
 	}
@@ -354,16 +437,25 @@ func (g *Generator) genTpl() ([][]string, error) {
 	// exclude timestamp columns and vin columns,
 	// because they are not to be generated by mxmock
 	mocker.ExcludeColumn(table.ColumnNameTS, table.ColumnNameVIN)
+	return mocker, nil
+}
 
-	// batchSize is how many non-null metrics are there in a tuple in DBMS,
-	// which might be inserted/merged in multiple rows
-	batchSize := (100 - g.cfg.emptyValueRatio) *
-		int(table.TotalMetricsCount) / 100
+// valuesPerTag is how many non-null metrics are there in a tuple in DBMS,
+// which might be inserted/merged in multiple rows
+func (g *Generator) valuesPerTag() int {
+	return (100 - g.cfg.emptyValueRatio) * int(g.meta.Table.TotalMetricsCount) / 100
+}
+
+func (g *Generator) genTpl() ([][]string, error) {
+	mocker, err := g.newMocker(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := g.valuesPerTag()
 
 	tpl := make([][]string, 0, g.cfg.templateSize)
 	for s := int64(0); s < g.cfg.templateSize; s++ {
-		rand.Seed(time.Now().UnixNano())
-
 		rows, err := mocker.MockBatchWithTotalValues(g.cfg.batchLine, batchSize)
 		if err != nil {
 			return nil, err
@@ -381,7 +473,29 @@ func (g *Generator) genTpl() ([][]string, error) {
 	return tpl, nil
 }
 
+// mockDevices mocks the rows of each device in PER-VIN mode,
+// following the values of its previous rows.
+func (g *Generator) mockDevices(batches [][]string) error {
+	batchSize := g.valuesPerTag()
+	for i := range batches {
+		g.devices.Select(i)
+		rows, err := g.mocker.MockBatchWithTotalValues(g.cfg.batchLine, batchSize)
+		if err != nil {
+			return err
+		}
+		batch := batches[i][:0]
+		for _, row := range rows {
+			batch = append(batch, strings.Join(row, util.DELIMITER))
+		}
+		batches[i] = batch
+	}
+	return nil
+}
+
 func (g *Generator) validate() error {
+	if err := g.cfg.validateRate(); err != nil {
+		return err
+	}
 	if int64(g.cfg.BatchSize) > g.meta.Table.TotalMetricsCount {
 		return mxerror.CommonErrorf(
 			"batch size(%d) is greater than metrics number(%d)",
@@ -480,12 +594,22 @@ func (g *Generator) generateBatch(vins []string, ts time.Time, batches [][]strin
 		tagBounds = append(tagBounds, i)
 	}
 
+	var table *metadata.Table
+	if g.meta != nil {
+		table = g.meta.Table
+	}
 	tsString := ts.Format(util.TIME_FMT)
 	tsOutOfOrderString := ts.Add(-g.cfg.outOrderDuration).Format(util.TIME_FMT)
 	lastIndex := 0
 
 	var wg sync.WaitGroup
 	for idx, tagBound := range tagBounds {
+		// each goroutine picks the rows out of order by its own source,
+		// seeded in order, so that the picks are reproducible
+		var disorderRnd *rand.Rand
+		if g.cfg.percentOfOutOrder > 0 {
+			disorderRnd = rand.New(rand.NewSource(g.disorderRnd.Int63()))
+		}
 		wg.Add(1)
 		go func(idx, tagBound, lastIndex int) {
 			defer wg.Done()
@@ -496,16 +620,11 @@ func (g *Generator) generateBatch(vins []string, ts time.Time, batches [][]strin
 
 			for i := lastIndex; i < tagBound; i++ {
 				ts := tsString
-				if g.cfg.percentOfOutOrder > 0 && rand.Intn(100) < g.cfg.percentOfOutOrder {
+				if disorderRnd != nil && disorderRnd.Intn(100) < g.cfg.percentOfOutOrder {
 					ts = tsOutOfOrderString
 				}
 				for _, row := range batches[i] {
-					buffer.WriteString(ts)
-					buffer.WriteString(util.DELIMITER)
-					buffer.WriteString(vins[i])
-					buffer.WriteString(util.DELIMITER)
-					buffer.WriteString(row)
-					buffer.WriteByte('\n')
+					writeLine(buffer, table, ts, vins[i], row)
 					lines++
 				}
 			}
@@ -520,3 +639,50 @@ func (g *Generator) generateBatch(vins []string, ts time.Time, batches [][]strin
 	wg.Wait()
 	return batchData, batchDataLines, batchDataSize
 }
+
+// writeLine writes the line of a device at the timestamp, where row is of the columns
+// other than the timestamp and vin columns, delimited in the order of the table.
+// The timestamp and vin lead the line if the table is nil.
+func writeLine(buffer *bytes.Buffer, table *metadata.Table, ts, vin, row string) {
+	if table == nil || table.HasLeadingKeys() {
+		buffer.WriteString(ts)
+		buffer.WriteString(util.DELIMITER)
+		buffer.WriteString(vin)
+		buffer.WriteString(util.DELIMITER)
+		buffer.WriteString(row)
+		buffer.WriteByte('\n')
+		return
+	}
+	tsIndex, vinIndex := table.KeyColumnIndexes()
+	var field string
+	for i := range table.Columns {
+		if i > 0 {
+			buffer.WriteString(util.DELIMITER)
+		}
+		switch i {
+		case tsIndex:
+			buffer.WriteString(ts)
+		case vinIndex:
+			buffer.WriteString(vin)
+		default:
+			field, row = nextField(row)
+			buffer.WriteString(field)
+		}
+	}
+	buffer.WriteByte('\n')
+}
+
+// nextField splits the first field off the delimited row, where the delimiters quoted are not counted
+func nextField(row string) (string, string) {
+	inQuote := false
+	for i := 0; i < len(row); i++ {
+		if row[i] == '"' {
+			inQuote = !inQuote
+			continue
+		}
+		if !inQuote && strings.HasPrefix(row[i:], util.DELIMITER) {
+			return row[:i], row[i+len(util.DELIMITER):]
+		}
+	}
+	return row, ""
+}
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/engine/generator/telematics/typ"
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/internal/util"
 )
@@ -467,4 +469,129 @@ var _ = Describe("Telematics Generator", func() {
 			Expect(length).To(Equal(totalSize))
 		})
 	})
+	Context("writeLine", func() {
+		It("writes the timestamp and vin among the other columns in the order of the table", func() {
+			table := &metadata.Table{
+				Columns: metadata.Columns{
+					metadata.NewColumn("reading", metadata.MetricsTypeFloat8),
+					metadata.NewColumn("device_id", metadata.ColumnTypeText),
+					metadata.NewColumn("attrs", metadata.ColumnTypeJSON),
+					metadata.NewColumn("collected_at", metadata.ColumnTypeTimestamp),
+					metadata.NewColumn("site", "inet"),
+				},
+				ColumnIndexTS:  3,
+				ColumnIndexVIN: 1,
+			}
+			buff := &bytes.Buffer{}
+			writeLine(buff, table, "2022-07-26 09:39:59", "v1", `1.5|"{""a"": ""x|y""}"|10.0.0.1`)
+			writeLine(buff, table, "2022-07-26 09:39:59", "v2", "||")
+			Expect(buff.String()).To(Equal(`1.5|v1|"{""a"": ""x|y""}"|2022-07-26 09:39:59|10.0.0.1` + "\n" +
+				"|v2||2022-07-26 09:39:59|\n"))
+
+			buff.Reset()
+			table.ColumnIndexTS, table.ColumnIndexVIN = 0, 0
+			writeLine(buff, table, "2022-07-26 09:39:59", "v1", "1|2|3")
+			Expect(buff.String()).To(Equal("2022-07-26 09:39:59|v1|1|2|3\n"))
+		})
+	})
+	Context("mockDevices", func() {
+		newPerVinGenerator := func(tagNum int, seed int64) *Generator {
+			generator := NewGenerator(engine.GeneratorConfig{
+				GlobalConfig: &engine.GlobalConfig{
+					TagNum: int64(tagNum),
+					Seed:   seed,
+				},
+				PluginConfig: &Config{
+					BatchSize:    1,
+					Randomness:   _RANDOMNESS_LEVEL_PER_VIN,
+					NumGoRoutine: 1,
+				},
+			}).(*Generator)
+			generator.meta = &metadata.Metadata{
+				Table: &metadata.Table{
+					Columns: metadata.Columns{
+						metadata.NewColumn("ts", metadata.ColumnTypeTimestamp),
+						metadata.NewColumn("vin", metadata.ColumnTypeText),
+						metadata.NewColumn("odometer", metadata.MetricsTypeFloat8),
+						metadata.NewColumn("temperature", metadata.MetricsTypeFloat8),
+					},
+					ColumnSpecs: metadata.ColumnSpecs{
+						nil,
+						nil,
+						{Distribution: metadata.DistributionCounter, Min: 0, Step: 1},
+						{Min: 20, Max: 30},
+					},
+					TotalMetricsCount: 2,
+					ColumnNameTS:      "ts",
+					ColumnNameVIN:     "vin",
+				},
+			}
+			generator.devices = typ.NewDevices(tagNum)
+			var err error
+			generator.mocker, err = generator.newMocker(generator.devices)
+			Expect(err).NotTo(HaveOccurred())
+			return generator
+		}
+		parseRow := func(row string) (float64, float64) {
+			var odometer, temperature float64
+			_, err := fmt.Sscanf(row, "%f|%f", &odometer, &temperature)
+			Expect(err).NotTo(HaveOccurred())
+			return odometer, temperature
+		}
+
+		It("keeps the metrics of each device evolving continuously", func() {
+			generator := newPerVinGenerator(3, 0)
+			batches := make([][]string, 3)
+			lastOdometers, lastTemperatures := make([]float64, 3), make([]float64, 3)
+			for step := 0; step < 50; step++ {
+				Expect(generator.mockDevices(batches)).To(Succeed())
+				for i, batch := range batches {
+					Expect(batch).To(HaveLen(1))
+					odometer, temperature := parseRow(batch[0])
+					Expect(temperature).To(BeNumerically(">=", 20))
+					Expect(temperature).To(BeNumerically("<=", 30))
+					if step == 0 {
+						Expect(odometer).To(Equal(0.0))
+					} else {
+						Expect(odometer - lastOdometers[i]).To(BeNumerically(">=", 0))
+						Expect(odometer - lastOdometers[i]).To(BeNumerically("<=", 1))
+						Expect(temperature - lastTemperatures[i]).To(BeNumerically("~", 0, 0.1+1e-6))
+					}
+					lastOdometers[i], lastTemperatures[i] = odometer, temperature
+				}
+			}
+		})
+
+		It("generates the same rows by the same seed", func() {
+			mock := func() [][]string {
+				generator := newPerVinGenerator(2, 42)
+				var rows [][]string
+				for step := 0; step < 3; step++ {
+					batches := make([][]string, 2)
+					Expect(generator.mockDevices(batches)).To(Succeed())
+					rows = append(rows, batches...)
+				}
+				return rows
+			}
+			Expect(mock()).To(Equal(mock()))
+		})
+	})
+
+	Context("CollectMetrics", func() {
+		It("reports the time of its own table only", func() {
+			collect := func(g *Generator) string {
+				mw := engine.NewMetricsWriter()
+				g.CollectMetrics(mw)
+				var out strings.Builder
+				_, _ = mw.WriteTo(&out)
+				return out.String()
+			}
+			g1 := &Generator{genTime: int64(2 * time.Second), writeTime: int64(3 * time.Second)}
+			g2 := &Generator{}
+			Expect(collect(g1)).To(ContainSubstring(`mxbench_generator_gen_seconds_total{generator="telematics"} 2`))
+			Expect(collect(g1)).To(ContainSubstring(`mxbench_generator_write_seconds_total{generator="telematics"} 3`))
+			Expect(collect(g2)).To(ContainSubstring(`mxbench_generator_gen_seconds_total{generator="telematics"} 0`))
+			Expect(collect(g2)).To(ContainSubstring(`mxbench_generator_write_seconds_total{generator="telematics"} 0`))
+		})
+	})
 })
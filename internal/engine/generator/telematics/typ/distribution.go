@@ -0,0 +1,257 @@
+package typ
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+)
+
+const (
+	_DEFAULT_STEP = 1.0
+	// in PER-VIN mode, metrics without a distribution walk randomly
+	// by at most 1/_DEFAULT_WALK_STEPS of their range each value
+	_DEFAULT_WALK_STEPS = 100
+)
+
+// distState is where a distribution is at, kept per metric,
+// and per device in PER-VIN mode.
+type distState struct {
+	value   float64
+	i       int64
+	started bool
+}
+
+// distribution generates the values of a metric one after another,
+// so that the stateful ones, e.g. counter, evolve along the generated rows.
+type distribution interface {
+	// start initializes the state before the first value
+	start(rnd *rand.Rand, st *distState)
+	next(rnd *rand.Rand, st *distState) float64
+}
+
+// newDistribution returns the distribution given by the spec,
+// nil if the values are uniformly distributed.
+func newDistribution(spec *metadata.ColumnSpec, rnd *rand.Rand) distribution {
+	if spec == nil {
+		return nil
+	}
+	switch spec.GetDistribution() {
+	case metadata.DistributionNormal:
+		return &normal{spec: spec}
+	case metadata.DistributionLogNormal:
+		return &normal{spec: spec, isLog: true}
+	case metadata.DistributionZipf:
+		return &zipf{
+			spec: spec,
+			zipf: rand.NewZipf(rnd, spec.ZipfS, spec.ZipfV, uint64(spec.Max-spec.Min)),
+		}
+	case metadata.DistributionRandomWalk:
+		return &randomWalk{spec: spec}
+	case metadata.DistributionCounter:
+		return &counter{spec: spec}
+	case metadata.DistributionSine:
+		return &sine{spec: spec}
+	case metadata.DistributionStep:
+		return &step{spec: spec}
+	}
+	return nil
+}
+
+// Devices tells which device the metrics are generated for in PER-VIN mode,
+// where every metric keeps a state per device, so that the metrics of
+// a device evolve continuously across timestamps.
+// It is not safe for concurrent use, neither are the types sharing it.
+type Devices struct {
+	num     int
+	current int
+}
+
+func NewDevices(num int) *Devices {
+	return &Devices{num: num}
+}
+
+// Select makes the values generated afterwards belong to the i-th device.
+func (d *Devices) Select(i int) {
+	d.current = i
+}
+
+// source draws the values of a metric from its distribution.
+type source struct {
+	dist    distribution
+	rnd     *rand.Rand
+	devices *Devices
+	states  []distState
+}
+
+// newSource returns the source of a metric of the spec and type, nil if its values are
+// uniformly distributed and not per device. In PER-VIN mode, such metrics
+// walk randomly within [min, max) of the spec, or the default range of the type.
+func newSource(spec *metadata.ColumnSpec, rnd *rand.Rand, devices *Devices, tp metadata.MetricsType) *source {
+	dist := newDistribution(spec, rnd)
+	if dist == nil {
+		if devices == nil {
+			return nil
+		}
+		lo, hi := defaultRange(tp)
+		if spec != nil && spec.HasRange() {
+			lo, hi = spec.Min, spec.Max
+		}
+		dist = &randomWalk{
+			spec:        &metadata.ColumnSpec{Min: lo, Max: hi, Step: (hi - lo) / _DEFAULT_WALK_STEPS},
+			randomStart: true,
+		}
+	}
+	numStates := 1
+	if devices != nil {
+		numStates = devices.num
+	}
+	return &source{dist: dist, rnd: rnd, devices: devices, states: make([]distState, numStates)}
+}
+
+func (s *source) next() float64 {
+	st := &s.states[0]
+	if s.devices != nil {
+		st = &s.states[s.devices.current]
+	}
+	if !st.started {
+		st.started = true
+		if s.devices != nil {
+			// so that the sine and step of devices are out of phase
+			st.i = s.rnd.Int63n(math.MaxInt32)
+		}
+		s.dist.start(s.rnd, st)
+	}
+	return s.dist.next(s.rnd, st)
+}
+
+// defaultRange is the range that the metrics of the type walk within in PER-VIN mode,
+// unless min and max are given.
+func defaultRange(tp metadata.MetricsType) (float64, float64) {
+	switch tp {
+	case metadata.MetricsTypeInt4, metadata.MetricsTypeInt8:
+		return 0, math.MaxInt32
+	}
+	return 0, 1
+}
+
+// clamp limits v within [min, max] if the range is given
+func clamp(spec *metadata.ColumnSpec, v float64) float64 {
+	if !spec.HasRange() {
+		return v
+	}
+	return math.Max(spec.Min, math.Min(spec.Max, v))
+}
+
+func stepOf(spec *metadata.ColumnSpec) float64 {
+	if spec.Step == 0 {
+		return _DEFAULT_STEP
+	}
+	return spec.Step
+}
+
+type normal struct {
+	spec  *metadata.ColumnSpec
+	isLog bool
+}
+
+func (n *normal) start(*rand.Rand, *distState) {}
+
+func (n *normal) next(rnd *rand.Rand, _ *distState) float64 {
+	v := n.spec.Mean + n.spec.Stddev*rnd.NormFloat64()
+	if n.isLog {
+		v = math.Exp(v)
+	}
+	return clamp(n.spec, v)
+}
+
+type zipf struct {
+	spec *metadata.ColumnSpec
+	zipf *rand.Zipf
+}
+
+func (z *zipf) start(*rand.Rand, *distState) {}
+
+func (z *zipf) next(*rand.Rand, *distState) float64 {
+	return z.spec.Min + float64(z.zipf.Uint64())
+}
+
+type randomWalk struct {
+	spec *metadata.ColumnSpec
+	// randomStart starts from a random value within range instead of mean
+	randomStart bool
+}
+
+func (w *randomWalk) start(rnd *rand.Rand, st *distState) {
+	st.value = w.spec.Mean
+	if w.randomStart {
+		st.value = w.spec.Min + rnd.Float64()*(w.spec.Max-w.spec.Min)
+	}
+	st.value = clamp(w.spec, st.value)
+}
+
+// next moves by at most step, bouncing back from min and max
+func (w *randomWalk) next(rnd *rand.Rand, st *distState) float64 {
+	v := st.value + (2*rnd.Float64()-1)*stepOf(w.spec)
+	if w.spec.HasRange() {
+		if v > w.spec.Max {
+			v = 2*w.spec.Max - v
+		}
+		if v < w.spec.Min {
+			v = 2*w.spec.Min - v
+		}
+	}
+	st.value = clamp(w.spec, v)
+	return st.value
+}
+
+type counter struct {
+	spec *metadata.ColumnSpec
+}
+
+func (c *counter) start(_ *rand.Rand, st *distState) {
+	st.value = c.spec.Min
+}
+
+// next increases by at most step, and rolls over to min once beyond max
+func (c *counter) next(rnd *rand.Rand, st *distState) float64 {
+	v := st.value
+	st.value += rnd.Float64() * stepOf(c.spec)
+	if c.spec.HasRange() && st.value > c.spec.Max {
+		st.value = c.spec.Min
+	}
+	return v
+}
+
+type sine struct {
+	spec *metadata.ColumnSpec
+}
+
+func (s *sine) start(*rand.Rand, *distState) {}
+
+func (s *sine) next(rnd *rand.Rand, st *distState) float64 {
+	phase := 2 * math.Pi * float64(st.i%s.spec.Period) / float64(s.spec.Period)
+	st.i++
+	v := s.spec.Mean + s.spec.Amplitude*math.Sin(phase) + s.spec.Noise*rnd.NormFloat64()
+	return clamp(s.spec, v)
+}
+
+type step struct {
+	spec *metadata.ColumnSpec
+}
+
+func (s *step) start(rnd *rand.Rand, st *distState) {
+	st.value = s.level(rnd)
+}
+
+func (s *step) next(rnd *rand.Rand, st *distState) float64 {
+	if st.i%s.spec.Period == 0 {
+		st.value = s.level(rnd)
+	}
+	st.i++
+	return st.value
+}
+
+func (s *step) level(rnd *rand.Rand) float64 {
+	return s.spec.Min + rnd.Float64()*(s.spec.Max-s.spec.Min)
+}
@@ -0,0 +1,153 @@
+package typ
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+)
+
+var _ = Describe("Distribution", func() {
+	newRand := func() *rand.Rand { return rand.New(rand.NewSource(1)) }
+	draw := func(spec *metadata.ColumnSpec, n int) []float64 {
+		src := newSource(spec, newRand(), nil, metadata.MetricsTypeFloat8)
+		Expect(src).NotTo(BeNil())
+		values := make([]float64, n)
+		for i := range values {
+			values[i] = src.next()
+		}
+		return values
+	}
+
+	It("should leave uniform values to the types", func() {
+		Expect(newSource(nil, newRand(), nil, metadata.MetricsTypeFloat8)).To(BeNil())
+		Expect(newSource(&metadata.ColumnSpec{Min: 1, Max: 2}, newRand(), nil, metadata.MetricsTypeFloat8)).To(BeNil())
+		Expect(newSource(&metadata.ColumnSpec{Distribution: metadata.DistributionUniform}, newRand(), nil, metadata.MetricsTypeFloat8)).To(BeNil())
+	})
+
+	It("should draw normal values around mean within range", func() {
+		values := draw(&metadata.ColumnSpec{Distribution: metadata.DistributionNormal, Mean: 50, Stddev: 5, Min: 40, Max: 60}, 10000)
+		var sum float64
+		for _, v := range values {
+			Expect(v).To(BeNumerically(">=", 40))
+			Expect(v).To(BeNumerically("<=", 60))
+			sum += v
+		}
+		Expect(sum / float64(len(values))).To(BeNumerically("~", 50, 0.5))
+	})
+
+	It("should draw positive lognormal values", func() {
+		for _, v := range draw(&metadata.ColumnSpec{Distribution: metadata.DistributionLogNormal, Stddev: 1}, 1000) {
+			Expect(v).To(BeNumerically(">", 0))
+		}
+	})
+
+	It("should draw zipf values skewed to min", func() {
+		var atMin int
+		for _, v := range draw(&metadata.ColumnSpec{Distribution: metadata.DistributionZipf, ZipfS: 2, ZipfV: 1, Min: 10, Max: 20}, 1000) {
+			Expect(v).To(BeNumerically(">=", 10))
+			Expect(v).To(BeNumerically("<=", 20))
+			Expect(v).To(Equal(math.Trunc(v)))
+			if v == 10 {
+				atMin++
+			}
+		}
+		Expect(atMin).To(BeNumerically(">", 500))
+	})
+
+	It("should walk by at most step within range", func() {
+		values := draw(&metadata.ColumnSpec{Distribution: metadata.DistributionRandomWalk, Mean: 20, Step: 0.5, Min: 19, Max: 21}, 1000)
+		last := 20.0
+		for _, v := range values {
+			Expect(math.Abs(v - last)).To(BeNumerically("<=", 0.5))
+			Expect(v).To(BeNumerically(">=", 19))
+			Expect(v).To(BeNumerically("<=", 21))
+			last = v
+		}
+	})
+
+	It("should count up from min and roll over beyond max", func() {
+		values := draw(&metadata.ColumnSpec{Distribution: metadata.DistributionCounter, Step: 2, Min: 100, Max: 1000}, 1000)
+		Expect(values[0]).To(Equal(100.0))
+		var rollovers int
+		for i := 1; i < len(values); i++ {
+			if values[i] < values[i-1] {
+				rollovers++
+				Expect(values[i]).To(Equal(100.0))
+				continue
+			}
+			Expect(values[i] - values[i-1]).To(BeNumerically("<=", 2))
+		}
+		Expect(rollovers).To(BeNumerically(">=", 1))
+
+		values = draw(&metadata.ColumnSpec{Distribution: metadata.DistributionCounter}, 100)
+		for i := 1; i < len(values); i++ {
+			Expect(values[i]).To(BeNumerically(">=", values[i-1]))
+		}
+	})
+
+	It("should oscillate by period", func() {
+		values := draw(&metadata.ColumnSpec{Distribution: metadata.DistributionSine, Mean: 10, Amplitude: 2, Period: 4}, 8)
+		Expect(values[0]).To(BeNumerically("~", 10, 1e-9))
+		Expect(values[1]).To(BeNumerically("~", 12, 1e-9))
+		Expect(values[3]).To(BeNumerically("~", 8, 1e-9))
+		Expect(values[5]).To(BeNumerically("~", 12, 1e-9))
+	})
+
+	It("should hold a level for period values", func() {
+		values := draw(&metadata.ColumnSpec{Distribution: metadata.DistributionStep, Period: 3, Min: 0, Max: 100}, 9)
+		for i := 0; i < len(values); i += 3 {
+			Expect(values[i+1]).To(Equal(values[i]))
+			Expect(values[i+2]).To(Equal(values[i]))
+		}
+	})
+
+	It("should generate values of the types by distribution", func() {
+		spec := &metadata.ColumnSpec{Distribution: metadata.DistributionCounter, Min: 5, Step: 1}
+		table := &metadata.Table{
+			Columns:     metadata.Columns{{Name: "c0", TypeName: "int8"}},
+			ColumnSpecs: metadata.ColumnSpecs{spec},
+		}
+		i8 := GetNewInt8(table, newRand(), nil)("c0")
+		Expect(i8.Random("c0")).To(Equal("5"))
+	})
+
+	It("should keep a state per device", func() {
+		spec := &metadata.ColumnSpec{Distribution: metadata.DistributionCounter, Min: 100, Step: 10}
+		devices := NewDevices(2)
+		src := newSource(spec, newRand(), devices, metadata.MetricsTypeFloat8)
+		Expect(src.next()).To(Equal(100.0))
+		second := src.next()
+		devices.Select(1)
+		Expect(src.next()).To(Equal(100.0))
+		devices.Select(0)
+		Expect(src.next()).To(BeNumerically(">=", second))
+	})
+
+	It("should walk within the range of metrics without distribution per device", func() {
+		devices := NewDevices(3)
+		src := newSource(&metadata.ColumnSpec{Min: 10, Max: 20}, newRand(), devices, metadata.MetricsTypeInt4)
+		Expect(src).NotTo(BeNil())
+		last := make([]float64, 3)
+		for step := 0; step < 100; step++ {
+			for i := range last {
+				devices.Select(i)
+				v := src.next()
+				Expect(v).To(BeNumerically(">=", 10))
+				Expect(v).To(BeNumerically("<=", 20))
+				if step > 0 {
+					Expect(math.Abs(v - last[i])).To(BeNumerically("<=", 0.1+1e-9))
+				}
+				last[i] = v
+			}
+		}
+
+		src = newSource(nil, newRand(), devices, metadata.MetricsTypeFloat4)
+		for i := 0; i < 100; i++ {
+			Expect(src.next()).To(BeNumerically("<=", 1))
+		}
+	})
+})
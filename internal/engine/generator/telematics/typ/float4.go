@@ -12,15 +12,18 @@ import (
 type Float4 struct {
 	mxmock.BaseType
 	columnSpec *metadata.ColumnSpec
+	src        *source
+	rnd        *rand.Rand
 
 	valueRanges *mxmock.ValueRange
 	mu          sync.RWMutex
 }
 
-func GetNewFloat4(table *metadata.Table) func(string) mxmock.Type {
+func GetNewFloat4(table *metadata.Table, rnd *rand.Rand, devices *Devices) func(string) mxmock.Type {
 	return func(colName string) mxmock.Type {
 		f4 := &Float4{
 			BaseType: mxmock.NewBaseType(colName),
+			rnd:      rnd,
 		}
 		for colInd, col := range table.Columns {
 			if col.Name == colName {
@@ -28,6 +31,7 @@ func GetNewFloat4(table *metadata.Table) func(string) mxmock.Type {
 				break
 			}
 		}
+		f4.src = newSource(f4.columnSpec, rnd, devices, metadata.MetricsTypeFloat4)
 		return f4
 	}
 }
@@ -47,8 +51,14 @@ func (f4 *Float4) generateValue() string {
 	var value float32
 
 	//TODO: performance issue
-	if f4.columnSpec == nil || (int(f4.columnSpec.Min) == 0 && int(f4.columnSpec.Max) == 0) {
-		value = rand.Float32()
+	if f4.src != nil {
+		value64 := f4.src.next()
+		if f4.columnSpec.IsRounded {
+			value64 = roundFloat(value64, f4.columnSpec.DecimalPlaces)
+		}
+		value = float32(value64)
+	} else if f4.columnSpec == nil || (int(f4.columnSpec.Min) == 0 && int(f4.columnSpec.Max) == 0) {
+		value = f4.rnd.Float32()
 	} else {
 		switch f4.columnSpec.Name {
 		case _NULL:
@@ -56,7 +66,7 @@ func (f4 *Float4) generateValue() string {
 		default:
 		}
 
-		value64 := rand.Float64()*(f4.columnSpec.Max-f4.columnSpec.Min) + f4.columnSpec.Min
+		value64 := f4.rnd.Float64()*(f4.columnSpec.Max-f4.columnSpec.Min) + f4.columnSpec.Min
 		if f4.columnSpec.IsRounded {
 			return fmt.Sprintf("%f", roundFloat(value64, f4.columnSpec.DecimalPlaces))
 		}
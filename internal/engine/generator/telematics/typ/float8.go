@@ -13,15 +13,18 @@ import (
 type Float8 struct {
 	mxmock.BaseType
 	columnSpec *metadata.ColumnSpec
+	src        *source
+	rnd        *rand.Rand
 
 	valueRanges *mxmock.ValueRange
 	mu          sync.RWMutex
 }
 
-func GetNewFloat8(table *metadata.Table) func(string) mxmock.Type {
+func GetNewFloat8(table *metadata.Table, rnd *rand.Rand, devices *Devices) func(string) mxmock.Type {
 	return func(colName string) mxmock.Type {
 		f8 := &Float8{
 			BaseType: mxmock.NewBaseType(colName),
+			rnd:      rnd,
 		}
 		for colInd, col := range table.Columns {
 			if col.Name == colName {
@@ -29,6 +32,7 @@ func GetNewFloat8(table *metadata.Table) func(string) mxmock.Type {
 				break
 			}
 		}
+		f8.src = newSource(f8.columnSpec, rnd, devices, metadata.MetricsTypeFloat8)
 		return f8
 	}
 }
@@ -49,8 +53,13 @@ func (f8 *Float8) generateValue() string {
 	var value float64
 
 	//TODO: performance issue
-	if f8.columnSpec == nil || (int(f8.columnSpec.Min) == 0 && int(f8.columnSpec.Max) == 0) {
-		value = rand.Float64()
+	if f8.src != nil {
+		value = f8.src.next()
+		if f8.columnSpec.IsRounded {
+			value = roundFloat(value, f8.columnSpec.DecimalPlaces)
+		}
+	} else if f8.columnSpec == nil || (int(f8.columnSpec.Min) == 0 && int(f8.columnSpec.Max) == 0) {
+		value = f8.rnd.Float64()
 	} else {
 		switch f8.columnSpec.Name {
 		case _NULL:
@@ -58,7 +67,7 @@ func (f8 *Float8) generateValue() string {
 		default:
 		}
 
-		value = rand.Float64()*(f8.columnSpec.Max-f8.columnSpec.Min) + f8.columnSpec.Min
+		value = f8.rnd.Float64()*(f8.columnSpec.Max-f8.columnSpec.Min) + f8.columnSpec.Min
 		if f8.columnSpec.IsRounded {
 			return fmt.Sprintf("%f", roundFloat(value, f8.columnSpec.DecimalPlaces))
 		}
@@ -2,6 +2,7 @@ package typ
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"sync"
 
@@ -12,15 +13,18 @@ import (
 type Int4 struct {
 	mxmock.BaseType
 	columnSpec *metadata.ColumnSpec
+	src        *source
+	rnd        *rand.Rand
 
 	valueRanges *mxmock.ValueRange
 	mu          sync.RWMutex
 }
 
-func GetNewInt4(table *metadata.Table) func(string) mxmock.Type {
+func GetNewInt4(table *metadata.Table, rnd *rand.Rand, devices *Devices) func(string) mxmock.Type {
 	return func(colName string) mxmock.Type {
 		i4 := &Int4{
 			BaseType: mxmock.NewBaseType(colName),
+			rnd:      rnd,
 		}
 		for colInd, col := range table.Columns {
 			if col.Name == colName {
@@ -28,6 +32,7 @@ func GetNewInt4(table *metadata.Table) func(string) mxmock.Type {
 				break
 			}
 		}
+		i4.src = newSource(i4.columnSpec, rnd, devices, metadata.MetricsTypeInt4)
 		return i4
 	}
 }
@@ -55,10 +60,12 @@ func (i4 *Int4) generateValue() string {
 	var value int32
 	//TODO: performance issue
 	//tolerate the case that the user didn't set comment on the column at all
-	if i4.columnSpec == nil || (int32(i4.columnSpec.Min) == 0 && int32(i4.columnSpec.Max) == 0) {
-		value = rand.Int31()
+	if i4.src != nil {
+		value = int32(math.Round(i4.src.next()))
+	} else if i4.columnSpec == nil || (int32(i4.columnSpec.Min) == 0 && int32(i4.columnSpec.Max) == 0) {
+		value = i4.rnd.Int31()
 	} else {
-		value = rand.Int31n(int32(i4.columnSpec.Max)-int32(i4.columnSpec.Min)) + int32(i4.columnSpec.Min)
+		value = i4.rnd.Int31n(int32(i4.columnSpec.Max)-int32(i4.columnSpec.Min)) + int32(i4.columnSpec.Min)
 	}
 
 	i4.updateValueRange(value)
@@ -2,6 +2,7 @@ package typ
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"sync"
 
@@ -12,15 +13,18 @@ import (
 type Int8 struct {
 	mxmock.BaseType
 	columnSpec *metadata.ColumnSpec
+	src        *source
+	rnd        *rand.Rand
 
 	valueRanges *mxmock.ValueRange
 	mu          sync.RWMutex
 }
 
-func GetNewInt8(table *metadata.Table) func(string) mxmock.Type {
+func GetNewInt8(table *metadata.Table, rnd *rand.Rand, devices *Devices) func(string) mxmock.Type {
 	return func(colName string) mxmock.Type {
 		i8 := &Int8{
 			BaseType: mxmock.NewBaseType(colName),
+			rnd:      rnd,
 		}
 		for colInd, col := range table.Columns {
 			if col.Name == colName {
@@ -28,6 +32,7 @@ func GetNewInt8(table *metadata.Table) func(string) mxmock.Type {
 				break
 			}
 		}
+		i8.src = newSource(i8.columnSpec, rnd, devices, metadata.MetricsTypeInt8)
 		return i8
 	}
 }
@@ -55,10 +60,12 @@ func (i8 *Int8) generateValue() string {
 	var value int64
 	//TODO: performance issue
 	//tolerate the case that the user didn't set comment on the column at all
-	if i8.columnSpec == nil || (int64(i8.columnSpec.Min) == 0 && int64(i8.columnSpec.Max) == 0) {
-		value = rand.Int63()
+	if i8.src != nil {
+		value = int64(math.Round(i8.src.next()))
+	} else if i8.columnSpec == nil || (int64(i8.columnSpec.Min) == 0 && int64(i8.columnSpec.Max) == 0) {
+		value = i8.rnd.Int63()
 	} else {
-		value = rand.Int63n(int64(i8.columnSpec.Max) - int64(i8.columnSpec.Min) + int64(i8.columnSpec.Min))
+		value = i8.rnd.Int63n(int64(i8.columnSpec.Max) - int64(i8.columnSpec.Min) + int64(i8.columnSpec.Min))
 	}
 
 	i8.updateValueRange(value)
@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/rand"
 	"strings"
 	"sync"
 
-	"github.com/brianvoe/gofakeit/v6"
-
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
 	"github.com/ymatrix-data/mxbench/pkg/mxmock"
@@ -24,6 +23,9 @@ type JSON struct {
 	keys       []string
 	kdMap      map[string]*metadata.MetricsDescription
 	columnSpec *metadata.ColumnSpec
+	// sources are of the keys not uniformly distributed, or of all the keys in PER-VIN mode
+	sources map[string]*source
+	rnd     *rand.Rand
 
 	Name string `json:"name"`
 	Age  int    `json:"number" fake:"{number:1,100}"`
@@ -32,10 +34,11 @@ type JSON struct {
 	mu          sync.RWMutex
 }
 
-func GetNewJSON(table *metadata.Table) func(string) mxmock.Type {
+func GetNewJSON(table *metadata.Table, rnd *rand.Rand, devices *Devices) func(string) mxmock.Type {
 	return func(colName string) mxmock.Type {
 		var keys []string
 		var kdMap map[string]*metadata.MetricsDescription
+		sources := map[string]*source{}
 		var columnSpec *metadata.ColumnSpec
 		for colInd, col := range table.Columns {
 			if col.Name == colName {
@@ -52,9 +55,19 @@ func GetNewJSON(table *metadata.Table) func(string) mxmock.Type {
 					key := fmt.Sprintf("k%d_%s_%d", cdI, colsDesc.MetricsType, i)
 					keys = append(keys, key)
 					kdMap[key] = colsDesc
+					if src := newSource(&colsDesc.Spec, rnd, devices, colsDesc.MetricsType); src != nil {
+						sources[key] = src
+					}
 				}
 			}
 		}
+		if devices != nil && colName == table.ColumnNameExt && (columnSpec == nil || len(columnSpec.ColumnsDescriptions) == 0) {
+			// the keys follow metricsType and metricsNum configuration
+			for i := int64(0); i < table.JSONMetricsCount; i++ {
+				key := fmt.Sprintf("k%d_%s", i, table.JSONMetricsCandidateType)
+				sources[key] = newSource(nil, rnd, devices, table.JSONMetricsCandidateType)
+			}
+		}
 		return &JSON{
 			metricsType:    table.JSONMetricsCandidateType,
 			metricsCount:   table.JSONMetricsCount,
@@ -64,6 +77,8 @@ func GetNewJSON(table *metadata.Table) func(string) mxmock.Type {
 			keys:           keys,
 			kdMap:          kdMap,
 			columnSpec:     columnSpec,
+			sources:        sources,
+			rnd:            rnd,
 			valueRanges:    make(map[string]*mxmock.ValueRange),
 		}
 	}
@@ -78,7 +93,7 @@ func (j *JSON) Random(keys ...string) string {
 			if key != j.columnName {
 				continue
 			}
-			_ = gofakeit.Struct(j)
+			_ = j.Faker().Struct(j)
 			b, _ := json.Marshal(j)
 			return string(b)
 		}
@@ -100,7 +115,12 @@ func (j *JSON) Random(keys ...string) string {
 				continue
 			}
 
-			_, kvStr := j.generateValue(j.metricsType, key, nil, nil)
+			var kvStr string
+			if src, ok := j.sources[key]; ok {
+				_, kvStr = j.generateDistributedValue(j.metricsType, key, src.next())
+			} else {
+				_, kvStr = j.generateValue(j.metricsType, key, nil, nil)
+			}
 			vs = append(vs, kvStr)
 		}
 		buff.WriteString(strings.Join(vs, ","))
@@ -123,7 +143,10 @@ func (j *JSON) Random(keys ...string) string {
 			continue
 		}
 
-		if int(columnsDesc.Spec.Min) == 0 && int(columnsDesc.Spec.Max) == 0 {
+		if src, ok := j.sources[key]; ok {
+			_, kvStr := j.generateDistributedValue(columnsDesc.MetricsType, key, src.next())
+			vs = append(vs, kvStr)
+		} else if int(columnsDesc.Spec.Min) == 0 && int(columnsDesc.Spec.Max) == 0 {
 			_, kvStr := j.generateValue(columnsDesc.MetricsType, key, nil, nil)
 			vs = append(vs, kvStr)
 
@@ -148,32 +171,32 @@ func (j *JSON) generateValue(tp metadata.MetricsType, key string, min, max inter
 		// generate random value
 		switch tp {
 		case metadata.MetricsTypeInt4:
-			value = rand.Int31()
+			value = j.rnd.Int31()
 			kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
 		case metadata.MetricsTypeInt8:
-			value = rand.Int63()
+			value = j.rnd.Int63()
 			kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
 		case metadata.MetricsTypeFloat4:
-			value = rand.Float32()
+			value = j.rnd.Float32()
 			kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
 		case metadata.MetricsTypeFloat8:
-			value = rand.Float64()
+			value = j.rnd.Float64()
 			kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
 		}
 	} else {
 		// generate random value within range
 		switch tp {
 		case metadata.MetricsTypeInt4:
-			value = rand.Int31n(max.(int32)-min.(int32)) + min.(int32)
+			value = j.rnd.Int31n(max.(int32)-min.(int32)) + min.(int32)
 			kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
 		case metadata.MetricsTypeInt8:
-			value = rand.Int63n(max.(int64)-min.(int64)) + min.(int64)
+			value = j.rnd.Int63n(max.(int64)-min.(int64)) + min.(int64)
 			kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
 		case metadata.MetricsTypeFloat4:
-			value = rand.Float32()*(max.(float32)-min.(float32)) + min.(float32)
+			value = j.rnd.Float32()*(max.(float32)-min.(float32)) + min.(float32)
 			kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
 		case metadata.MetricsTypeFloat8:
-			value = rand.Float64()*(max.(float64)-min.(float64)) + min.(float64)
+			value = j.rnd.Float64()*(max.(float64)-min.(float64)) + min.(float64)
 			kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
 		}
 	}
@@ -188,6 +211,36 @@ func (j *JSON) generateValue(tp metadata.MetricsType, key string, min, max inter
 	return value, kvStr
 }
 
+// generate kv string of the value drawn from a distribution
+func (j *JSON) generateDistributedValue(tp metadata.MetricsType, key string, v float64) (interface{}, string) {
+	var value interface{}
+	var kvStr string
+
+	switch tp {
+	case metadata.MetricsTypeInt4:
+		value = int32(math.Round(v))
+		kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
+	case metadata.MetricsTypeInt8:
+		value = int64(math.Round(v))
+		kvStr = fmt.Sprintf("\"\"%s\"\":%d", key, value)
+	case metadata.MetricsTypeFloat4:
+		value = float32(v)
+		kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
+	case metadata.MetricsTypeFloat8:
+		value = v
+		kvStr = fmt.Sprintf("\"\"%s\"\":%f", key, value)
+	}
+
+	if value == nil {
+		// should not happen
+		return value, kvStr
+	}
+
+	j.updateRange(tp, value)
+
+	return value, kvStr
+}
+
 // update value range based on type
 func (j *JSON) updateRange(tp metadata.MetricsType, value interface{}) {
 	j.mu.Lock()
@@ -2,6 +2,7 @@ package typ
 
 import (
 	"fmt"
+	"math/rand"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -37,7 +38,7 @@ var _ = Describe("JSON type", func() {
 
 	Context("JSON type", func() {
 		It("get random value and then get value range", func() {
-			jsonType := GetNewJSON(table)("ext")
+			jsonType := GetNewJSON(table, rand.New(rand.NewSource(1)), nil)("ext")
 			Expect(jsonType).ToNot(BeNil())
 
 			keys := jsonType.Keys()
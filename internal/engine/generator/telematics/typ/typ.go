@@ -1,6 +1,8 @@
 package typ
 
 import (
+	"math/rand"
+
 	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
 	"github.com/ymatrix-data/mxbench/pkg/mxmock"
 )
@@ -9,14 +11,16 @@ const (
 	_NULL = "null"
 )
 
-func Init(table *metadata.Table) {
-	mxmock.TypMap["float4"] = GetNewFloat4(table)
-	mxmock.TypMap["float8"] = GetNewFloat8(table)
+// Init registers the types generating the metrics of the table from rnd,
+// devices are given in PER-VIN mode, nil otherwise.
+func Init(table *metadata.Table, rnd *rand.Rand, devices *Devices) {
+	mxmock.TypMap["float4"] = GetNewFloat4(table, rnd, devices)
+	mxmock.TypMap["float8"] = GetNewFloat8(table, rnd, devices)
 
-	mxmock.TypMap["int4"] = GetNewInt4(table)
-	mxmock.TypMap["int8"] = GetNewInt8(table)
+	mxmock.TypMap["int4"] = GetNewInt4(table, rnd, devices)
+	mxmock.TypMap["int8"] = GetNewInt8(table, rnd, devices)
 
 	mxmock.TypMap["varchar"] = GetNewVarChar(table)
-	mxmock.TypMap["json"] = GetNewJSON(table)
+	mxmock.TypMap["json"] = GetNewJSON(table, rnd, devices)
 	mxmock.TypMap["jsonb"] = mxmock.TypMap["json"]
 }
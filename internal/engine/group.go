@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+)
+
+// Group runs the tables of a multi-table run, each by an Engine of its own config.
+// The tables are prepared one by one, as setting GUCs may restart the database,
+// and then loaded and benchmarked concurrently.
+type Group struct {
+	Config  *Config
+	Engines []*Engine
+
+	// StartAt is when Run is called
+	StartAt time.Time
+
+	metricsServer *http.Server
+}
+
+var _ IEngine = (*Group)(nil)
+
+// New creates a Group for a multi-table run, an Engine otherwise.
+func New(cfg *Config) (IEngine, error) {
+	if len(cfg.GlobalCfg.GetTables()) == 0 {
+		return NewEngineFromConfig(cfg)
+	}
+	return NewGroupFromConfig(cfg)
+}
+
+func NewGroupFromConfig(cfg *Config) (*Group, error) {
+	tables := cfg.GlobalCfg.GetTables()
+	targets := make([]string, 0, len(tables))
+	for _, t := range tables {
+		targets = append(targets, fmt.Sprintf("%s.%s", cfg.GlobalCfg.SchemaName, t.TableName))
+	}
+
+	g := &Group{Config: cfg}
+	for _, t := range tables {
+		tableCfg, err := cfg.ForTable(t)
+		if err != nil {
+			return g, err
+		}
+		tableCfg.GlobalCfg.SharedTargets = targets
+		e, err := NewEngineFromConfig(tableCfg)
+		if e != nil {
+			g.Engines = append(g.Engines, e)
+		}
+		if err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+func (g *Group) Run() error {
+	g.StartAt = time.Now()
+	if addr := g.Config.GlobalCfg.MetricsAddr; addr != "" && !g.Config.GlobalCfg.Dump {
+		var err error
+		if g.metricsServer, err = serveMetrics(addr, g.collectMetrics); err != nil {
+			return err
+		}
+	}
+
+	// the writers share what is created for this run only, released once any of the tables fails
+	shared := &WriterShared{}
+	defer shared.Close()
+	for _, e := range g.Engines {
+		e.Config.WriterCfg.Shared = shared
+	}
+
+	for _, e := range g.Engines {
+		log.Info("Begin to prepare table %s", e.Config.GlobalCfg.TableName)
+		e.StartAt = g.StartAt
+		if err := e.prepare(); err != nil {
+			return err
+		}
+	}
+
+	var eg errgroup.Group
+	for _, e := range g.Engines {
+		e := e
+		eg.Go(func() error {
+			if err := e.execute(); err != nil {
+				_ = shared.Close()
+				return fmt.Errorf("table %s: %w", e.Config.GlobalCfg.TableName, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+func (g *Group) collectMetrics(mw *MetricsWriter) {
+	for _, e := range g.Engines {
+		mw.SetConstLabels(MetricLabel{"table", e.Config.GlobalCfg.TableName})
+		e.collectMetrics(mw)
+	}
+}
+
+func (g *Group) Close() error {
+	defer stopMetrics(g.metricsServer)
+	var firstErr error
+	for _, e := range g.Engines {
+		if err := e.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (g *Group) PrintStat() {
+	for _, e := range g.Engines {
+		if !e.Config.GlobalCfg.Dump {
+			fmt.Printf("Table %s:\n", e.Config.GlobalCfg.TableName)
+		}
+		e.PrintStat()
+	}
+}
+
+func (g *Group) PrintProgress() {
+	for _, e := range g.Engines {
+		e.PrintProgress()
+	}
+}
+
+// GetFormattedSummary appends a row to the report for each table.
+func (g *Group) GetFormattedSummary() {
+	for _, e := range g.Engines {
+		e.GetFormattedSummary()
+	}
+}
+
+// SaveResult saves each table as a run of its own into results-db.
+func (g *Group) SaveResult() {
+	for _, e := range g.Engines {
+		e.SaveResult()
+	}
+}
+
+// CheckThresholds checks the thresholds against each table,
+// and returns the error of the last table violating them.
+func (g *Group) CheckThresholds() error {
+	var violated error
+	for _, e := range g.Engines {
+		if len(e.Config.ThresholdsCfg.thresholds) > 0 && !e.Config.GlobalCfg.Dump {
+			fmt.Printf("Table %s:\n", e.Config.GlobalCfg.TableName)
+		}
+		if err := e.CheckThresholds(); err != nil {
+			log.Error("Thresholds of table %s violated", e.Config.GlobalCfg.TableName)
+			violated = err
+		}
+	}
+	return violated
+}
+
+func (g *Group) IsNil() bool {
+	return g == nil
+}
@@ -113,6 +113,11 @@ func parseColumnsDescriptions(colsDescs string) (MetricsDescriptions, error) {
 	if err != nil {
 		return columnsDescriptions, err
 	}
+	for _, desc := range columnsDescriptions {
+		if err = desc.Spec.validate(); err != nil {
+			return columnsDescriptions, err
+		}
+	}
 	return columnsDescriptions, nil
 }
 
@@ -126,8 +131,9 @@ func parseColumnComment(colComment string) (*ColumnSpec, error) {
 	if err != nil {
 		return nil, err
 	}
-	// TODO: check semantics of  columnSpec
-	// for example, max should be bigger than min etc.
+	if err = columnSpec.validate(); err != nil {
+		return nil, err
+	}
 	return &columnSpec, nil
 }
 
@@ -239,6 +245,18 @@ type ColumnSpec struct {
 	Max           float64 `json:"max"`
 	IsRounded     bool    `json:"is-rounded"`
 	DecimalPlaces uint    `json:"decimal-places"`
+
+	// Distribution is how the values are distributed, uniform by default,
+	// the fields below are the parameters of the distributions
+	Distribution DistributionKind `json:"distribution"`
+	Mean         float64          `json:"mean"`
+	Stddev       float64          `json:"stddev"`
+	ZipfS        float64          `json:"zipf-s"`
+	ZipfV        float64          `json:"zipf-v"`
+	Step         float64          `json:"step"`
+	Amplitude    float64          `json:"amplitude"`
+	Noise        float64          `json:"noise"`
+	Period       int64            `json:"period"`
 }
 
 type ColumnSpecs []*ColumnSpec
@@ -25,4 +25,32 @@ var _ = Describe("Column", func() {
 		}
 		Expect(cls.ToSQLStr()).To(Equal("\tc1 float64\n  , c2 float64\n  , c3 float64"))
 	})
+
+	It("should parse distributions in column comments", func() {
+		spec, err := parseColumnComment(`{"distribution": "normal", "mean": 20, "stddev": 2}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.GetDistribution()).To(Equal(DistributionNormal))
+		Expect(spec.Stddev).To(Equal(2.0))
+
+		spec, err = parseColumnComment(`{"min": 1, "max": 2}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.GetDistribution()).To(Equal(DistributionUniform))
+
+		for _, comment := range []string{
+			`{"min": 2, "max": 1}`,
+			`{"distribution": "gamma"}`,
+			`{"distribution": "zipf", "zipf-s": 1, "zipf-v": 1, "max": 10}`,
+			`{"distribution": "zipf", "zipf-s": 2, "zipf-v": 1}`,
+			`{"distribution": "sine", "amplitude": 1}`,
+			`{"distribution": "step", "period": 10}`,
+			`{"distribution": "normal", "stddev": -1}`,
+			`{"is-ext": true, "columns-descriptions": [{"type": "float8", "count": 1, "comment": {"distribution": "gamma"}}]}`,
+		} {
+			_, err = parseColumnComment(comment)
+			Expect(err).To(HaveOccurred(), comment)
+		}
+
+		_, err = parseColumnsDescriptions(`[{"type": "float8", "count": 1, "comment": {"distribution": "sine"}}]`)
+		Expect(err).To(HaveOccurred())
+	})
 })
@@ -24,6 +24,10 @@ type Config struct {
 	IsDDLFromFile           bool
 	DB                      util.DBConnParams
 	DBVersion               util.DBVersion
+	// Seed seeds the parameters of benchmark queries, 0 means a random seed
+	Seed int64
+	// ColumnNameTS and ColumnNameVIN map the timestamp and vin columns of the table from DDL file
+	ColumnNameTS, ColumnNameVIN string
 }
 
 func (cfg *Config) validate() error {
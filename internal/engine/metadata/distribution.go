@@ -0,0 +1,86 @@
+package metadata
+
+import (
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+type DistributionKind = string
+
+const (
+	// DistributionUniform draws values uniformly within [min, max)
+	DistributionUniform DistributionKind = "uniform"
+	// DistributionNormal draws values from N(mean, stddev)
+	DistributionNormal DistributionKind = "normal"
+	// DistributionLogNormal draws values whose logarithm is N(mean, stddev)
+	DistributionLogNormal DistributionKind = "lognormal"
+	// DistributionZipf draws min + k, where k within [0, max-min] follows Zipf(zipf-s, zipf-v)
+	DistributionZipf DistributionKind = "zipf"
+	// DistributionRandomWalk starts from mean and moves by at most step each value
+	DistributionRandomWalk DistributionKind = "random-walk"
+	// DistributionCounter starts from min and increases by at most step each value
+	DistributionCounter DistributionKind = "counter"
+	// DistributionSine oscillates around mean by amplitude every period values, plus N(0, noise)
+	DistributionSine DistributionKind = "sine"
+	// DistributionStep holds a level drawn within [min, max) for period values
+	DistributionStep DistributionKind = "step"
+)
+
+var supportedDistributions = map[DistributionKind]struct{}{
+	DistributionUniform:    {},
+	DistributionNormal:     {},
+	DistributionLogNormal:  {},
+	DistributionZipf:       {},
+	DistributionRandomWalk: {},
+	DistributionCounter:    {},
+	DistributionSine:       {},
+	DistributionStep:       {},
+}
+
+// HasRange tells whether min and max are given.
+func (spec *ColumnSpec) HasRange() bool {
+	return spec.Max > spec.Min
+}
+
+// GetDistribution returns the distribution kind, uniform if not given.
+func (spec *ColumnSpec) GetDistribution() DistributionKind {
+	if spec == nil || spec.Distribution == "" {
+		return DistributionUniform
+	}
+	return spec.Distribution
+}
+
+func (spec *ColumnSpec) validate() error {
+	if spec.Min > spec.Max {
+		return mxerror.CommonErrorf("min(%v) is greater than max(%v)", spec.Min, spec.Max)
+	}
+	dist := spec.GetDistribution()
+	if _, ok := supportedDistributions[dist]; !ok {
+		return mxerror.CommonErrorf("unsupported distribution %q", dist)
+	}
+	if spec.Stddev < 0 || spec.Noise < 0 || spec.Step < 0 || spec.Period < 0 {
+		return mxerror.CommonErrorf("stddev, noise, step and period of distribution %s should not be negative", dist)
+	}
+	switch dist {
+	case DistributionZipf:
+		if spec.ZipfS <= 1 || spec.ZipfV < 1 {
+			return mxerror.CommonError("zipf distribution requires zipf-s > 1 and zipf-v >= 1")
+		}
+		if !spec.HasRange() {
+			return mxerror.CommonError("zipf distribution requires max > min")
+		}
+	case DistributionSine:
+		if spec.Period == 0 {
+			return mxerror.CommonError("sine distribution requires a positive period")
+		}
+	case DistributionStep:
+		if spec.Period == 0 || !spec.HasRange() {
+			return mxerror.CommonError("step distribution requires a positive period and max > min")
+		}
+	}
+	for _, desc := range spec.ColumnsDescriptions {
+		if err := desc.Spec.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
@@ -129,6 +131,10 @@ type Metadata struct {
 	GUCs  GUCs
 	Table *Table
 	Cfg   *Config
+
+	// rnd draws the parameters of benchmark queries
+	rnd   *rand.Rand
+	rndMu sync.Mutex
 }
 
 func New(cfg *Config) (*Metadata, error) {
@@ -255,6 +261,10 @@ type SingleVinGenerator func() string
 type MultiVinsGenerator func() string
 type DurationGenerator func() (string, string)
 
+// ParamsGenerator generates the parameters of an execution of a prepared statement,
+// which are the values the generators above return without quotes.
+type ParamsGenerator func() []interface{}
+
 func (meta *Metadata) GetSingleVinGenerator() SingleVinGenerator {
 	return func() string {
 		return meta.GetRandomVinsGenerator(1)()
@@ -267,24 +277,103 @@ func (meta *Metadata) GetRandomVinsGenerator(num int) func() string {
 			return "''"
 		}
 		quotedSelectedTags := make([]string, 0, num)
-		for i := 0; i < num; i++ {
-			vinIdx := rand.Int63n(int64(len(meta.Table.VinValues)))
-			quotedSelectedTags = append(quotedSelectedTags, pq.QuoteLiteral(meta.Table.VinValues[vinIdx]))
+		for _, vin := range meta.randomVins(num) {
+			quotedSelectedTags = append(quotedSelectedTags, pq.QuoteLiteral(vin))
 		}
 		return strings.Join(quotedSelectedTags, ", ")
 	}
 }
 
+// GetRandomVinsParamsGenerator returns a generator of num random vins as the parameters,
+// which are all empty if the vins of the table are unknown.
+func (meta *Metadata) GetRandomVinsParamsGenerator(num int) ParamsGenerator {
+	return func() []interface{} {
+		params := make([]interface{}, num)
+		if len(meta.Table.VinValues) == 0 {
+			for i := range params {
+				params[i] = ""
+			}
+			return params
+		}
+		for i, vin := range meta.randomVins(num) {
+			params[i] = vin
+		}
+		return params
+	}
+}
+
+func (meta *Metadata) randomVins(num int) []string {
+	vins := make([]string, 0, num)
+	for i := 0; i < num; i++ {
+		vinIdx := meta.int63n(int64(len(meta.Table.VinValues)))
+		vins = append(vins, meta.Table.VinValues[vinIdx])
+	}
+	return vins
+}
+
 func (meta *Metadata) GetRandomStartEndTSArgGenerator(duration time.Duration) DurationGenerator {
 	return func() (string, string) {
-		randomStartTime := meta.Cfg.StartAt
-		endTime := meta.Cfg.EndAt
-		if endTime.Sub(randomStartTime) > duration {
-			randomStartTime = randomStartTime.Add(time.Duration(rand.Int63n(int64(endTime.Add(-duration).Sub(randomStartTime)))))
-			endTime = randomStartTime.Add(duration)
+		start, end := meta.randomStartEnd(duration)
+		return pq.QuoteLiteral(start), pq.QuoteLiteral(end)
+	}
+}
+
+// GetRandomStartEndTSParamsGenerator returns a generator of the start and end of a random duration as the parameters.
+func (meta *Metadata) GetRandomStartEndTSParamsGenerator(duration time.Duration) ParamsGenerator {
+	return func() []interface{} {
+		start, end := meta.randomStartEnd(duration)
+		return []interface{}{start, end}
+	}
+}
+
+func (meta *Metadata) randomStartEnd(duration time.Duration) (string, string) {
+	randomStartTime := meta.Cfg.StartAt
+	endTime := meta.Cfg.EndAt
+	if endTime.Sub(randomStartTime) > duration {
+		randomStartTime = randomStartTime.Add(time.Duration(meta.int63n(int64(endTime.Add(-duration).Sub(randomStartTime)))))
+		endTime = randomStartTime.Add(duration)
+	}
+	return randomStartTime.Format(util.TIME_FMT), endTime.Format(util.TIME_FMT)
+}
+
+// GetRandomMetricsColumnGenerator returns a generator of the name of a random metrics column,
+// which is neither the timestamp, vin nor ext column. It returns nil if the table has no such column.
+func (meta *Metadata) GetRandomMetricsColumnGenerator() func() string {
+	var names []string
+	for i, c := range meta.Table.Columns {
+		if meta.Table.IsKeyColumn(i) || (meta.Table.ColumnNameExt != "" && c.Name == meta.Table.ColumnNameExt) {
+			continue
 		}
-		return pq.QuoteLiteral(randomStartTime.Format(util.TIME_FMT)), pq.QuoteLiteral(endTime.Format(util.TIME_FMT))
+		names = append(names, c.Name)
 	}
+	if len(names) == 0 {
+		return nil
+	}
+	return func() string {
+		return names[meta.int63n(int64(len(names)))]
+	}
+}
+
+// GetRandomIntGenerator returns a generator of a random integer in [min, max].
+func (meta *Metadata) GetRandomIntGenerator(min, max int64) func() string {
+	return func() string {
+		return strconv.FormatInt(min+meta.int63n(max-min+1), 10)
+	}
+}
+
+// int63n is shared by the benchmark queries running in parallel,
+// which draw from the seed of the config.
+func (meta *Metadata) int63n(n int64) int64 {
+	meta.rndMu.Lock()
+	defer meta.rndMu.Unlock()
+	if meta.rnd == nil {
+		var seed int64
+		if meta.Cfg != nil {
+			seed = meta.Cfg.Seed
+		}
+		meta.rnd = util.NewRand(seed, "benchmark")
+	}
+	return meta.rnd.Int63n(n)
 }
 
 func (meta *Metadata) GetFixedStartEndTSArgGenerator(startTime, endTime string) DurationGenerator {
@@ -293,6 +382,34 @@ func (meta *Metadata) GetFixedStartEndTSArgGenerator(startTime, endTime string)
 	}
 }
 
+// CountTimestamps returns the number of timestamps in [start, end) of the data generated by time range,
+// which are every ts-step-in-second from ts-start until ts-end.
+func (meta *Metadata) CountTimestamps(start, end time.Time) int64 {
+	step := time.Duration(meta.Cfg.TimestampStepInSecond) * time.Second
+	if step <= 0 {
+		return 0
+	}
+	if start.Before(meta.Cfg.StartAt) {
+		start = meta.Cfg.StartAt
+	}
+	if end.After(meta.Cfg.EndAt) {
+		end = meta.Cfg.EndAt
+	}
+	if !start.Before(end) {
+		return 0
+	}
+	// the index of the first timestamp not before start, and of the first one not before end
+	first := (start.Sub(meta.Cfg.StartAt) + step - 1) / step
+	last := (end.Sub(meta.Cfg.StartAt) + step - 1) / step
+	return int64(last - first)
+}
+
+func (meta *Metadata) GetFixedStartEndTSParamsGenerator(startTime, endTime string) ParamsGenerator {
+	return func() []interface{} {
+		return []interface{}{startTime, endTime}
+	}
+}
+
 func (meta *Metadata) ToJSONSelectStr(jsonColumnsDescs MetricsDescriptions, tableAlias string, jsonMetricsNum int64) string {
 	if jsonMetricsNum > meta.Table.JSONMetricsCount {
 		jsonMetricsNum = meta.Table.JSONMetricsCount
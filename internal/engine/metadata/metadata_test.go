@@ -2298,4 +2298,22 @@ var _ = Describe("Metadata test", func() {
 			Expect(result).To(Equal("''"))
 		})
 	})
+
+	Describe("CountTimestamps", func() {
+		startAt, _ := time.Parse(util.TIME_FMT, "2016-01-01 00:00:00")
+		endAt, _ := time.Parse(util.TIME_FMT, "2016-01-01 01:00:00")
+		meta := &Metadata{Cfg: &Config{StartAt: startAt, EndAt: endAt, TimestampStepInSecond: 10}}
+
+		It("counts the timestamps of the steps in the range", func() {
+			Expect(meta.CountTimestamps(startAt, endAt)).To(Equal(int64(360)))
+			Expect(meta.CountTimestamps(startAt.Add(5*time.Second), startAt.Add(time.Minute))).To(Equal(int64(5)))
+			Expect(meta.CountTimestamps(startAt.Add(10*time.Second), startAt.Add(61*time.Second))).To(Equal(int64(6)))
+		})
+
+		It("counts the timestamps of the data only", func() {
+			Expect(meta.CountTimestamps(startAt.Add(-time.Hour), startAt.Add(time.Minute))).To(Equal(int64(6)))
+			Expect(meta.CountTimestamps(endAt.Add(-time.Minute), endAt.Add(time.Hour))).To(Equal(int64(6)))
+			Expect(meta.CountTimestamps(endAt, endAt.Add(time.Hour))).To(BeZero())
+		})
+	})
 })
@@ -72,6 +72,10 @@ type Table struct {
 
 	ColumnNameTS, ColumnNameVIN, ColumnNameExt string
 	ColumnTypeTS, ColumnTypeVIN, ColumnTypeExt ColumnType
+	// ColumnIndexTS and ColumnIndexVIN are where the timestamp and vin columns are in Columns,
+	// the leading two unless they are mapped by ts-column-name and vin-column-name.
+	// Both of zero, which can't be, also mean the leading two.
+	ColumnIndexTS, ColumnIndexVIN int
 
 	ExtColumn       *mxmock.Column
 	ColumnsDescsExt MetricsDescriptions
@@ -92,12 +96,35 @@ func (t *Table) Identifier() string {
 
 func (t *Table) SingleRowMetricsSize() int64 {
 	var singleRowMetricsSize int64
-	for i := NON_METRICS_COLUMN_NUM; i < len(t.Columns); i++ {
+	for i := range t.Columns {
+		if t.IsKeyColumn(i) {
+			continue
+		}
 		singleRowMetricsSize += Size(t.Columns[i].TypeName)
 	}
 	return singleRowMetricsSize
 }
 
+// KeyColumnIndexes returns the indexes of the timestamp and vin columns
+func (t *Table) KeyColumnIndexes() (int, int) {
+	if t.ColumnIndexTS == t.ColumnIndexVIN {
+		return TSColumnIndex, VINColumnIndex
+	}
+	return t.ColumnIndexTS, t.ColumnIndexVIN
+}
+
+// IsKeyColumn tells whether the i-th column is the timestamp or vin column
+func (t *Table) IsKeyColumn(i int) bool {
+	tsIndex, vinIndex := t.KeyColumnIndexes()
+	return i == tsIndex || i == vinIndex
+}
+
+// HasLeadingKeys tells whether the timestamp and vin columns are the leading two
+func (t *Table) HasLeadingKeys() bool {
+	tsIndex, vinIndex := t.KeyColumnIndexes()
+	return tsIndex == TSColumnIndex && vinIndex == VINColumnIndex
+}
+
 // NewMarsTable creates a mars2 or mars3 table with an index according to config.
 func NewMarsTable(cfg *Config, st StorageType) (*Table, error) {
 	var err error
@@ -243,6 +270,9 @@ func NewTable(cfg *Config, st StorageType) (*Table, error) {
 		ColumnTypeVIN: ColumnTypeText,
 		ColumnTypeExt: ColumnTypeJSON,
 
+		ColumnIndexTS:  TSColumnIndex,
+		ColumnIndexVIN: VINColumnIndex,
+
 		JSONMetricsCandidateType: cfg.MetricsType,
 		// Set distribution key is as default: vin
 		DistKey:    ColumnNameVIN,
@@ -294,7 +324,7 @@ func NewTableFromDB(cfg *Config) (*Table, error) {
 		return nil, err
 	}
 
-	table, err := NewTableFromColumns(cfg.SchemaName, cfg.TableName, columns)
+	table, err := NewTableFromColumns(cfg.SchemaName, cfg.TableName, columns, cfg.ColumnNameTS, cfg.ColumnNameVIN)
 	if err != nil {
 		return nil, err
 	}
@@ -311,21 +341,41 @@ func NewTableFromDB(cfg *Config) (*Table, error) {
 	return table, nil
 }
 
-func NewTableFromColumns(schemaName, tableName string, columns Columns) (*Table, error) {
+// NewTableFromColumns creates the table of the columns read from database,
+// whose timestamp and vin columns are of the names given, or the leading two if not.
+func NewTableFromColumns(schemaName, tableName string, columns Columns, tsName, vinName ColumnName) (*Table, error) {
 	// check the columns
 	// the count of the should be greater than 2, which are left for timestamp and vin
 	if len(columns) <= NON_METRICS_COLUMN_NUM {
 		return nil, mxerror.CommonErrorf("the count of columns %d is not bigger than %d", len(columns), NON_METRICS_COLUMN_NUM)
 	}
 
-	// check the leading 2 columns of columns:
-	// They must be： the 1st for timestamp, and the 2nd for vin
-	firstCol, secondCol := columns[TSColumnIndex], columns[VINColumnIndex]
-	if !isSupportedTSType(firstCol.TypeName) {
-		return nil, mxerror.CommonErrorf("an unsupported column is designated as \"timestamp\" column: %s of %s", firstCol.Name, firstCol.TypeName)
+	// find the timestamp and vin columns:
+	// by default, the 1st for timestamp, and the 2nd for vin
+	tsIndex, vinIndex := TSColumnIndex, VINColumnIndex
+	for i, col := range columns {
+		switch col.Name {
+		case tsName:
+			tsIndex = i
+		case vinName:
+			vinIndex = i
+		}
+	}
+	if tsName != "" && columns[tsIndex].Name != tsName {
+		return nil, mxerror.CommonErrorf("ts-column-name %s is not a column of table %s", tsName, tableName)
+	}
+	if vinName != "" && columns[vinIndex].Name != vinName {
+		return nil, mxerror.CommonErrorf("vin-column-name %s is not a column of table %s", vinName, tableName)
 	}
-	if !isSupportedVinType(secondCol.TypeName) {
-		return nil, mxerror.CommonErrorf("an unsupported column is designated as \"vin\" column: %s of %s", firstCol.Name, firstCol.TypeName)
+	if tsIndex == vinIndex {
+		return nil, mxerror.CommonErrorf("column %s is designated as both \"timestamp\" and \"vin\" column", columns[tsIndex].Name)
+	}
+	tsCol, vinCol := columns[tsIndex], columns[vinIndex]
+	if !isSupportedTSType(tsCol.TypeName) {
+		return nil, mxerror.CommonErrorf("an unsupported column is designated as \"timestamp\" column: %s of %s", tsCol.Name, tsCol.TypeName)
+	}
+	if !isSupportedVinType(vinCol.TypeName) {
+		return nil, mxerror.CommonErrorf("an unsupported column is designated as \"vin\" column: %s of %s", vinCol.Name, vinCol.TypeName)
 	}
 	table := &Table{
 		schemaName:        schemaName,
@@ -333,24 +383,29 @@ func NewTableFromColumns(schemaName, tableName string, columns Columns) (*Table,
 		Columns:           columns,
 		ColumnSpecs:       make(ColumnSpecs, len(columns)),
 		TotalMetricsCount: int64(len(columns) - NON_METRICS_COLUMN_NUM),
-		ColumnNameTS:      firstCol.Name,
-		ColumnNameVIN:     secondCol.Name,
-		ColumnTypeTS:      firstCol.TypeName,
-		ColumnTypeVIN:     secondCol.Name,
+		ColumnNameTS:      tsCol.Name,
+		ColumnNameVIN:     vinCol.Name,
+		ColumnTypeTS:      tsCol.TypeName,
+		ColumnTypeVIN:     vinCol.TypeName,
+		ColumnIndexTS:     tsIndex,
+		ColumnIndexVIN:    vinIndex,
 	}
 	// traverse the columns, and check:
 	var extCol *mxmock.Column
 	var extColSpec *ColumnSpec
 	var err error
 	// Only the 'ts' column dosen't using ColumnSpec
-	for i := 1; i < len(columns); i++ {
+	for i := 0; i < len(columns); i++ {
+		if i == tsIndex {
+			continue
+		}
 		col := columns[i]
 		// if there is a json/jsonb column with "is-ext": true
 		// assure it's the only one, or let error occurs
 		var colSpec *ColumnSpec
 		colSpec, err = parseColumnComment(col.Comment)
 		if err != nil {
-			return nil, mxerror.CommonErrorf("the comment: %s of column: %s is not a legal JSON-formatted string: %v", col.Name, col.Comment, err)
+			return nil, mxerror.CommonErrorf("the comment: %s of column: %s is invalid: %v", col.Comment, col.Name, err)
 		}
 		table.ColumnSpecs[i] = colSpec
 		if colSpec != nil && colSpec.IsExt {
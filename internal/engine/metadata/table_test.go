@@ -194,3 +194,52 @@ var _ = Describe("Mars3 Table", func() {
 	It("should ...", func() {
 	})
 })
+
+var _ = Describe("Table from DB", func() {
+	columns := func() Columns {
+		return Columns{
+			NewColumn("device_id", ColumnTypeInt8),
+			NewColumn("reading", MetricsTypeFloat8),
+			NewColumn("collected_at", ColumnTypeTimestampTZ),
+			NewColumn("site", "inet"),
+		}
+	}
+
+	It("should map the timestamp and vin columns by name", func() {
+		t, err := NewTableFromColumns("public", "t", columns(), "collected_at", "device_id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ColumnNameTS).To(Equal("collected_at"))
+		Expect(t.ColumnNameVIN).To(Equal("device_id"))
+		Expect(t.ColumnTypeVIN).To(Equal(ColumnTypeInt8))
+		tsIndex, vinIndex := t.KeyColumnIndexes()
+		Expect([]int{tsIndex, vinIndex}).To(Equal([]int{2, 0}))
+		Expect(t.HasLeadingKeys()).To(BeFalse())
+		Expect(t.TotalMetricsCount).To(Equal(int64(2)))
+		Expect(t.SingleRowMetricsSize()).To(Equal(Size(MetricsTypeFloat8) + Size("inet")))
+	})
+
+	It("should take the leading two columns by default", func() {
+		cs := columns()
+		cs[0], cs[2] = cs[2], cs[0]
+		cs[1], cs[2] = cs[2], cs[1]
+		t, err := NewTableFromColumns("public", "t", cs, "", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.ColumnNameTS).To(Equal("collected_at"))
+		Expect(t.ColumnNameVIN).To(Equal("device_id"))
+		Expect(t.HasLeadingKeys()).To(BeTrue())
+		Expect((&Table{}).HasLeadingKeys()).To(BeTrue())
+	})
+
+	It("should reject the columns mapped wrong", func() {
+		_, err := NewTableFromColumns("public", "t", columns(), "ts", "device_id")
+		Expect(err).To(MatchError(ContainSubstring("ts-column-name ts is not a column")))
+		_, err = NewTableFromColumns("public", "t", columns(), "collected_at", "vin")
+		Expect(err).To(MatchError(ContainSubstring("vin-column-name vin is not a column")))
+		_, err = NewTableFromColumns("public", "t", columns(), "reading", "device_id")
+		Expect(err).To(MatchError(ContainSubstring("\"timestamp\" column: reading of float8")))
+		_, err = NewTableFromColumns("public", "t", columns(), "collected_at", "site")
+		Expect(err).To(MatchError(ContainSubstring("\"vin\" column: site of inet")))
+		_, err = NewTableFromColumns("public", "t", columns(), "", "device_id")
+		Expect(err).To(MatchError(ContainSubstring("both")))
+	})
+})
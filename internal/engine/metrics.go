@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/pkg/histogram"
+)
+
+const (
+	_METRICS_PATH         = "/metrics"
+	_METRICS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+	MetricTypeCounter   = "counter"
+	MetricTypeGauge     = "gauge"
+	MetricTypeHistogram = "histogram"
+)
+
+// the upper bounds of the buckets of latency histograms, in seconds
+var _LATENCY_BUCKETS = []float64{
+	0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60,
+}
+
+// MetricsCollector is implemented by the generators and stats
+// publishing their metrics on the /metrics endpoint.
+type MetricsCollector interface {
+	CollectMetrics(mw *MetricsWriter)
+}
+
+type MetricLabel struct {
+	Name, Value string
+}
+
+type metricFamily struct {
+	name, help, typ string
+	samples         []string
+}
+
+// MetricsWriter renders metrics in the Prometheus text exposition format.
+// Samples of the same metric may be added by different collectors,
+// they are grouped together when rendered.
+type MetricsWriter struct {
+	families []*metricFamily
+	index    map[string]*metricFamily
+
+	// constLabels are added to all the samples before the labels given
+	constLabels []MetricLabel
+}
+
+func NewMetricsWriter() *MetricsWriter {
+	return &MetricsWriter{index: make(map[string]*metricFamily)}
+}
+
+func (mw *MetricsWriter) family(name, help, typ string) *metricFamily {
+	f, ok := mw.index[name]
+	if !ok {
+		f = &metricFamily{name: name, help: help, typ: typ}
+		mw.index[name] = f
+		mw.families = append(mw.families, f)
+	}
+	return f
+}
+
+// SetConstLabels sets the labels added to all the samples added afterwards.
+func (mw *MetricsWriter) SetConstLabels(labels ...MetricLabel) {
+	mw.constLabels = labels
+}
+
+func (mw *MetricsWriter) add(f *metricFamily, suffix string, value float64, labels []MetricLabel) {
+	if len(mw.constLabels) > 0 {
+		labels = append(append(make([]MetricLabel, 0, len(mw.constLabels)+len(labels)), mw.constLabels...), labels...)
+	}
+	var b strings.Builder
+	b.WriteString(f.name)
+	b.WriteString(suffix)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l.Name)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabelValue(l.Value))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatMetricValue(value))
+	f.samples = append(f.samples, b.String())
+}
+
+func (mw *MetricsWriter) Counter(name, help string, value float64, labels ...MetricLabel) {
+	mw.add(mw.family(name, help, MetricTypeCounter), "", value, labels)
+}
+
+func (mw *MetricsWriter) Gauge(name, help string, value float64, labels ...MetricLabel) {
+	mw.add(mw.family(name, help, MetricTypeGauge), "", value, labels)
+}
+
+// LatencyHistogram adds a histogram in seconds of latencies recorded in nanoseconds.
+func (mw *MetricsWriter) LatencyHistogram(name, help string, h *histogram.Histogram, labels ...MetricLabel) {
+	f := mw.family(name, help, MetricTypeHistogram)
+	bucketLabels := append(append(make([]MetricLabel, 0, len(labels)+1), labels...), MetricLabel{})
+	for _, le := range _LATENCY_BUCKETS {
+		bucketLabels[len(labels)] = MetricLabel{"le", formatMetricValue(le)}
+		mw.add(f, "_bucket", float64(h.CountAtOrBelow(int64(le*float64(time.Second)))), bucketLabels)
+	}
+	bucketLabels[len(labels)] = MetricLabel{"le", "+Inf"}
+	count := float64(h.Count())
+	mw.add(f, "_bucket", count, bucketLabels)
+	mw.add(f, "_sum", float64(h.Sum())/float64(time.Second), labels)
+	mw.add(f, "_count", count, labels)
+}
+
+func (mw *MetricsWriter) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, f := range mw.families {
+		fmt.Fprintf(&buf, "# HELP %s %s\n", f.name, strings.ReplaceAll(f.help, "\n", " "))
+		fmt.Fprintf(&buf, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range f.samples {
+			buf.WriteString(s)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// collectStatMetrics collects the metrics of the stat and its sub stats.
+func collectStatMetrics(mw *MetricsWriter, stat Stat) {
+	if stat == nil {
+		return
+	}
+	if c, ok := stat.(MetricsCollector); ok {
+		c.CollectMetrics(mw)
+	}
+	for _, ss := range stat.GetSubStats() {
+		collectStatMetrics(mw, ss)
+	}
+}
+
+func (e *Engine) collectMetrics(mw *MetricsWriter) {
+	if c, ok := e.IGenerator.(MetricsCollector); ok {
+		c.CollectMetrics(mw)
+	}
+	collectStatMetrics(mw, e.IWriter.GetStat())
+	collectStatMetrics(mw, e.IBenchmark.GetStat())
+}
+
+// serveMetrics serves the metrics collected by collect at addr/metrics, until the server is closed.
+func serveMetrics(addr string, collect func(*MetricsWriter)) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(_METRICS_PATH, func(w http.ResponseWriter, _ *http.Request) {
+		mw := NewMetricsWriter()
+		collect(mw)
+		w.Header().Set("Content-Type", _METRICS_CONTENT_TYPE)
+		if _, err := mw.WriteTo(w); err != nil {
+			log.Warn("Write metrics failed: %v", err)
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Metrics server exited: %v", err)
+		}
+	}()
+	log.Info("Serving metrics at http://%s%s", listener.Addr(), _METRICS_PATH)
+	return server, nil
+}
+
+func stopMetrics(server *http.Server) {
+	if server == nil {
+		return
+	}
+	if err := server.Close(); err != nil {
+		log.Warn("Stop metrics server failed: %v", err)
+	}
+}
+
+// serveMetrics serves the /metrics endpoint at --metrics-addr until the engine is closed.
+func (e *Engine) serveMetrics() error {
+	addr := e.Config.GlobalCfg.MetricsAddr
+	if e.Config.GlobalCfg.Dump || addr == "" {
+		return nil
+	}
+	var err error
+	e.metricsServer, err = serveMetrics(addr, e.collectMetrics)
+	return err
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetricsWriter", func() {
+	It("should group samples of the same metric", func() {
+		mw := NewMetricsWriter()
+		mw.Counter("runs_total", "runs", 1, MetricLabel{"query", "a"})
+		mw.Gauge("up", "up", 1)
+		mw.Counter("runs_total", "runs", 2, MetricLabel{"query", "b"})
+
+		var out strings.Builder
+		_, err := mw.WriteTo(&out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal(`# HELP runs_total runs
+# TYPE runs_total counter
+runs_total{query="a"} 1
+runs_total{query="b"} 2
+# HELP up up
+# TYPE up gauge
+up 1
+`))
+	})
+
+	It("should add const labels before the labels given", func() {
+		mw := NewMetricsWriter()
+		mw.SetConstLabels(MetricLabel{"table", "t1"})
+		mw.Counter("runs_total", "runs", 1, MetricLabel{"query", "a"})
+		mw.SetConstLabels(MetricLabel{"table", "t2"})
+		mw.Counter("runs_total", "runs", 2)
+
+		var out strings.Builder
+		_, _ = mw.WriteTo(&out)
+		Expect(out.String()).To(ContainSubstring("runs_total{table=\"t1\",query=\"a\"} 1\nruns_total{table=\"t2\"} 2\n"))
+	})
+
+	It("should escape label values", func() {
+		mw := NewMetricsWriter()
+		mw.Gauge("g", "g", 0.5, MetricLabel{"v", "a\"b\\c\nd"})
+		var out strings.Builder
+		_, _ = mw.WriteTo(&out)
+		Expect(out.String()).To(ContainSubstring(`g{v="a\"b\\c\nd"} 0.5`))
+	})
+
+	It("should write latency histograms in seconds", func() {
+		ebs := newFakeExecBenchStat("Q", 8, 2*time.Millisecond, 20*time.Millisecond, 2*time.Second)
+		ebs.runs = 3
+		mw := NewMetricsWriter()
+		ebs.CollectMetrics(mw)
+
+		var out strings.Builder
+		_, _ = mw.WriteTo(&out)
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_runs_total{query="Q",parallel="8"} 3`))
+		Expect(out.String()).To(ContainSubstring("# TYPE mxbench_query_latency_seconds histogram"))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_bucket{query="Q",parallel="8",le="0.001"} 0`))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_bucket{query="Q",parallel="8",le="0.0025"} 1`))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_bucket{query="Q",parallel="8",le="1"} 2`))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_bucket{query="Q",parallel="8",le="+Inf"} 3`))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_sum{query="Q",parallel="8"} 2.022`))
+		Expect(out.String()).To(ContainSubstring(`mxbench_query_latency_seconds_count{query="Q",parallel="8"} 3`))
+	})
+})
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mixed Query", func() {
+	var mixed *MixedQuery
+
+	BeforeEach(func() {
+		mixed = &MixedQuery{
+			Queries: []Query{fakeQuery("A"), fakeQuery("B"), fakeQuery("C"), fakeQuery("D")},
+			Weights: []float64{70, 25, 5, 0},
+		}
+	})
+
+	It("should be named after its queries", func() {
+		Expect(mixed.GetName()).To(Equal("MIXED(A+B+C+D)"))
+	})
+
+	It("should pick the queries by their weights", func() {
+		ms := NewMixedBenchStat(ExecBenchOption{Parallel: 1}, mixed)
+		w, err := newBenchWorker(nil, ms.stats, mixed.Weights, rand.New(rand.NewSource(1)))
+		Expect(err).NotTo(HaveOccurred())
+
+		picked := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			picked[w.pick().query.GetName()]++
+		}
+		Expect(picked["A"]).To(BeNumerically("~", 7000, 300))
+		Expect(picked["B"]).To(BeNumerically("~", 2500, 300))
+		Expect(picked["C"]).To(BeNumerically("~", 500, 150))
+		Expect(picked).NotTo(HaveKey("D"))
+	})
+
+	It("should break the stat down by the queries and share the progress", func() {
+		ms := NewMixedBenchStat(ExecBenchOption{Parallel: 2, RunTimes: 5}, mixed)
+		Expect(ms.GetSubStats()).To(HaveLen(4))
+		for i, ss := range ms.GetSubStats() {
+			Expect(ss.(*ExecBenchStat).GetQuery()).To(Equal(mixed.Queries[i]))
+		}
+
+		ms.stats[0].addRun()
+		ms.stats[0].addRun()
+		ms.stats[1].addRun()
+		Expect(ms.stats[0].runs).To(Equal(int64(2)))
+		Expect(ms.stats[1].runs).To(Equal(int64(1)))
+		// 3 of the 5 runs with parallel 2
+		for _, ebs := range ms.stats {
+			Expect(ebs.GetProgress()).To(Equal("30"))
+		}
+	})
+})
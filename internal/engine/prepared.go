@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+const (
+	// _TIMEOUT_GRACE is how much later than the timeout the client stops waiting for an execution
+	_TIMEOUT_GRACE = time.Second
+
+	_RECONNECT_BACKOFF_MIN = 100 * time.Millisecond
+	_RECONNECT_BACKOFF_MAX = 5 * time.Second
+)
+
+// queryExecutor executes a query on a connection of the benchmark,
+// as a statement prepared on the connection if the query is prepared,
+// and validates its results if they are to be validated.
+type queryExecutor struct {
+	conn  *sqlx.DB
+	query Query
+	ebs   *ExecBenchStat
+
+	prepared  PreparedQuery
+	stmt      *sqlx.Stmt
+	validated ValidatedQuery
+}
+
+// newQueryExecutor prepares the query on the connection, if the option of the stat tells so and the query is a PreparedQuery.
+func newQueryExecutor(conn *sqlx.DB, query Query, ebs *ExecBenchStat) (*queryExecutor, error) {
+	x := &queryExecutor{conn: conn, query: query, ebs: ebs}
+	if vq, ok := query.(ValidatedQuery); ok && ebs.opt.Validate {
+		x.validated = vq
+	}
+	if !ebs.opt.Prepared {
+		return x, nil
+	}
+	pq, ok := query.(PreparedQuery)
+	if !ok {
+		return x, nil
+	}
+	stmt, err := conn.Preparex(pq.GetPreparedSQL())
+	if err != nil {
+		return nil, mxerror.CommonErrorf("failed to prepare query %s: %v", query.GetName(), err)
+	}
+	x.prepared, x.stmt = pq, stmt
+	return x, nil
+}
+
+func (x *queryExecutor) exec() error {
+	ctx, cancel := x.context()
+	defer cancel()
+	if x.validated != nil {
+		return x.execAndValidate(ctx)
+	}
+	if x.stmt != nil {
+		_, err := x.stmt.ExecContext(ctx, x.prepared.GetParams()...)
+		return err
+	}
+	_, err := x.conn.ExecContext(ctx, x.query.GetSQL())
+	return err
+}
+
+// context returns the context of an execution, with a deadline if the option tells a timeout.
+// The deadline is later than the timeout, so that the server cancels the execution by statement_timeout first,
+// while the deadline still stops waiting for a connection which is lost.
+func (x *queryExecutor) context() (context.Context, context.CancelFunc) {
+	if x.ebs.opt.Timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), x.ebs.opt.Timeout+_TIMEOUT_GRACE)
+}
+
+// execAndValidate fetches the results and validates them,
+// where an invalid result is counted by the stat instead of returned as an error.
+func (x *queryExecutor) execAndValidate(ctx context.Context) error {
+	var params []interface{}
+	var rows *sqlx.Rows
+	var err error
+	if x.stmt != nil {
+		params = x.prepared.GetParams()
+		rows, err = x.stmt.QueryxContext(ctx, params...)
+	} else {
+		var sql string
+		sql, params = x.validated.GetValidatedSQL()
+		rows, err = x.conn.QueryxContext(ctx, sql)
+	}
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var n int64
+	for rows.Next() {
+		n++
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	x.ebs.addValidation(x.validated.Validate(params, n))
+	return nil
+}
+
+// close closes the statement prepared, leaving the connection to its worker
+func (x *queryExecutor) close() {
+	if x.stmt != nil {
+		x.stmt.Close()
+	}
+}
+
+// benchWorker executes the queries on a connection of the benchmark, by an executor of each query.
+// With more than one query, as a mixed workload, each execution picks one of them by its weight.
+type benchWorker struct {
+	conn      *sqlx.DB
+	executors []*queryExecutor
+
+	// cumWeights are the cumulative weights of the executors
+	cumWeights []float64
+	rnd        *rand.Rand
+}
+
+// newBenchWorker creates the executor of each query of the stats on the connection,
+// where the weights are only used if there are more than one query.
+func newBenchWorker(conn *sqlx.DB, stats []*ExecBenchStat, weights []float64, rnd *rand.Rand) (*benchWorker, error) {
+	w := &benchWorker{conn: conn, rnd: rnd}
+	var cum float64
+	for i, ebs := range stats {
+		x, err := newQueryExecutor(conn, ebs.query, ebs)
+		if err != nil {
+			w.close()
+			return nil, err
+		}
+		w.executors = append(w.executors, x)
+		if i < len(weights) && weights[i] > 0 {
+			cum += weights[i]
+		}
+		w.cumWeights = append(w.cumWeights, cum)
+	}
+	return w, nil
+}
+
+// pick returns the executor of the query to execute next
+func (w *benchWorker) pick() *queryExecutor {
+	if len(w.executors) == 1 {
+		return w.executors[0]
+	}
+	total := w.cumWeights[len(w.cumWeights)-1]
+	if total <= 0 {
+		return w.executors[w.rnd.Intn(len(w.executors))]
+	}
+	r := w.rnd.Float64() * total
+	i := sort.Search(len(w.cumWeights), func(i int) bool { return w.cumWeights[i] > r })
+	if i == len(w.executors) {
+		i--
+	}
+	return w.executors[i]
+}
+
+// exec executes the query of the executor, retrying it if it fails by a retryable error as the option tells.
+// Every failed execution is counted by the class of its error, and the error failing the last retry is returned.
+func (w *benchWorker) exec(ctx context.Context, x *queryExecutor) error {
+	errs := x.ebs.Errors
+	for retries := 0; ; retries++ {
+		err := x.exec()
+		if err == nil {
+			return nil
+		}
+		class := classifyError(err)
+		errs.add(class, err)
+		if retries >= x.ebs.opt.Retries || !isRetryable(class) {
+			return err
+		}
+		if class == ErrorClassConnection {
+			if w.reconnect(ctx) != nil {
+				return err
+			}
+			atomic.AddInt64(&errs.Reconnects, 1)
+		}
+		atomic.AddInt64(&errs.Retries, 1)
+	}
+}
+
+// recover tells whether to keep executing the queries after an execution failed by the error,
+// which is only if the option tells to continue on error, and the connection is reestablished if it is lost.
+func (w *benchWorker) recover(ctx context.Context, x *queryExecutor, err error) bool {
+	if !x.ebs.opt.ContinueOnError {
+		atomic.AddInt64(&x.ebs.Errors.StoppedConnections, 1)
+		log.Error("query: %s execute error, stop executing it on the connection: %v", x.query.GetName(), err)
+		return false
+	}
+	if classifyError(err) != ErrorClassConnection {
+		return true
+	}
+	if w.reconnect(ctx) != nil {
+		return false
+	}
+	atomic.AddInt64(&x.ebs.Errors.Reconnects, 1)
+	return true
+}
+
+// reconnect reestablishes the connection lost, backing off until it succeeds or the context is done
+func (w *benchWorker) reconnect(ctx context.Context) error {
+	backoff := _RECONNECT_BACKOFF_MIN
+	for {
+		err := w.conn.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		log.Verbose("Reconnect failed, retry in %s: %v", backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > _RECONNECT_BACKOFF_MAX {
+			backoff = _RECONNECT_BACKOFF_MAX
+		}
+	}
+}
+
+// elapse updates the time elapsed of the stat of each query
+func (w *benchWorker) elapse(elapsed time.Duration) {
+	for _, x := range w.executors {
+		atomic.StoreInt64(&x.ebs.TimeElapsed, int64(elapsed))
+	}
+}
+
+// close closes the executors and the connection
+func (w *benchWorker) close() {
+	for _, x := range w.executors {
+		x.close()
+	}
+	w.conn.Close()
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+)
+
+const _JSON_REPORT_FILE = "report.json"
+
+// JSONReport is the report of a run written into report.json, a line for each run, if report-format is json.
+type JSONReport struct {
+	Table   string             `json:"table"`
+	Writer  *WriterSummary     `json:"writer,omitempty"`
+	Queries []*JSONQueryReport `json:"queries,omitempty"`
+
+	DegradeStartAt string `json:"degrade-start-at,omitempty"`
+	DegradeStopAt  string `json:"degrade-stop-at,omitempty"`
+
+	// Thresholds tells the results of the thresholds, including the violations, if any is given
+	Thresholds *ThresholdReport `json:"thresholds,omitempty"`
+}
+
+// JSONQueryReport is the stat of a query with a parallel in JSONReport.
+type JSONQueryReport struct {
+	Query    string `json:"query"`
+	Parallel int    `json:"parallel"`
+	*ExecBenchStat
+}
+
+// newJSONReport returns the report of the stats of the writer and the benchmark, and the thresholds checked, if any.
+func newJSONReport(table string, writerStat, benchmarkStat Stat, thresholds *ThresholdReport) *JSONReport {
+	r := &JSONReport{Table: table, Thresholds: thresholds}
+	if ws, ok := writerStat.(WriterStat); ok {
+		summary := ws.GetWriterSummary()
+		r.Writer = &summary
+	}
+	if benchmarkStat != nil {
+		for _, ss := range benchmarkStat.GetSubStats() {
+			if ebs, ok := ss.(*ExecBenchStat); ok {
+				r.addQuery(ebs)
+			}
+		}
+	}
+	return r
+}
+
+func (r *JSONReport) addQuery(ebs *ExecBenchStat) {
+	ebs.complete()
+	r.Queries = append(r.Queries, &JSONQueryReport{Query: ebs.query.GetName(), Parallel: ebs.opt.Parallel, ExecBenchStat: ebs})
+}
+
+// writeJSONReport appends the report of the run to report.json under report-path
+func (e *Engine) writeJSONReport() {
+	r := newJSONReport(e.Config.GlobalCfg.TableName, e.IWriter.GetStat(), e.IBenchmark.GetStat(), e.thresholdReport)
+	if e.Config.GlobalCfg.Degrade {
+		r.DegradeStartAt = e.degradeStartAt.Format(util.TIME_FMT)
+		r.DegradeStopAt = e.degradeStopAt.Format(util.TIME_FMT)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		log.Warn("Marshal report failed: %v", err)
+		return
+	}
+
+	filePath := filepath.Join(e.Config.GlobalCfg.ReportPath, _JSON_REPORT_FILE)
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		log.Warn("Report directory open failed: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err = file.Write(append(b, '\n')); err != nil {
+		log.Warn("Write report failed: %v", err)
+	}
+}
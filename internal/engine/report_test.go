@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeBenchmarkStat struct {
+	Stat
+	subStats []Stat
+}
+
+func (s *fakeBenchmarkStat) GetSubStats() []Stat { return s.subStats }
+
+var _ = Describe("JSON report", func() {
+	It("should include the queries and the violated thresholds", func() {
+		slow, err := parseThreshold("Q p95 < 20ms")
+		Expect(err).NotTo(HaveOccurred())
+		ebs := newFakeExecBenchStat("Q", 8, 50*time.Millisecond)
+		thresholds := checkThresholds([]*threshold{slow}, nil, []*ExecBenchStat{ebs})
+
+		r := newJSONReport("t", nil, &fakeBenchmarkStat{subStats: []Stat{ebs}}, thresholds)
+		Expect(r.Writer).To(BeNil())
+		Expect(r.Queries).To(HaveLen(1))
+		Expect(r.Queries[0].Query).To(Equal("Q"))
+		Expect(r.Queries[0].Parallel).To(Equal(8))
+
+		b, err := json.Marshal(r)
+		Expect(err).NotTo(HaveOccurred())
+		var m map[string]interface{}
+		Expect(json.Unmarshal(b, &m)).To(Succeed())
+		Expect(m).To(HaveKeyWithValue("table", "t"))
+		Expect(m).NotTo(HaveKey("writer"))
+		query := m["queries"].([]interface{})[0].(map[string]interface{})
+		Expect(query).To(HaveKeyWithValue("query", "Q"))
+		Expect(query).To(HaveKey("p95-latency"))
+		report := m["thresholds"].(map[string]interface{})
+		Expect(report).To(HaveKeyWithValue("passed", false))
+		Expect(report["results"]).To(HaveLen(1))
+	})
+
+	It("should omit the thresholds if none is given", func() {
+		b, err := json.Marshal(newJSONReport("t", nil, nil, nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`{"table":"t"}`))
+	})
+})
@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+)
+
+const _SCENARIO_REPORT_FILE_FMT = "scenario_%s.csv"
+
+// ScenarioPhase is a phase of the [[scenario]] of the config file.
+// A phase either runs mxbench with the options of its own, or runs the SQL statements, e.g. VACUUM.
+type ScenarioPhase struct {
+	Name string
+	// Duration stops the phase once it has run so long, 0 to run it to the end
+	Duration time.Duration
+	// SQL are the statements the phase executes in order, instead of running mxbench
+	SQL []string
+	// Args are the command line flags giving the options of the phase
+	Args []string
+}
+
+// ScenarioConfig is the [[scenario]] of the config file, run by command scenario.
+type ScenarioConfig struct {
+	Phases []ScenarioPhase
+}
+
+func (p ScenarioPhase) String() string {
+	if len(p.SQL) > 0 {
+		return fmt.Sprintf("%s: %s", p.Name, strings.Join(p.SQL, "; "))
+	}
+	if len(p.Args) == 0 {
+		return p.Name
+	}
+	return fmt.Sprintf("%s: %s", p.Name, strings.Join(p.Args, " "))
+}
+
+// phaseRun is the run of a phase of a scenario
+type phaseRun struct {
+	sequenceRun
+	phase ScenarioPhase
+
+	startAt time.Time
+	stopAt  time.Time
+}
+
+// Scenario runs the phases of command scenario one after another against the same table,
+// each by an engine of its own config.
+type Scenario struct {
+	sequence
+
+	Config *Config
+
+	// StartAt is when Run is called
+	StartAt time.Time
+
+	runs []*phaseRun
+}
+
+var _ IEngine = (*Scenario)(nil)
+
+// NewScenario creates a Scenario of the phases of the config,
+// given the config of each phase derived from it, nil for a phase executing SQL.
+func NewScenario(cfg *Config, phaseConfigs []*Config) *Scenario {
+	s := &Scenario{Config: cfg}
+	for i, p := range cfg.ScenarioCfg.Phases {
+		s.runs = append(s.runs, &phaseRun{sequenceRun: sequenceRun{config: phaseConfigs[i]}, phase: p})
+	}
+	return s
+}
+
+func (s *Scenario) Run() error {
+	s.StartAt = time.Now()
+	for i, r := range s.runs {
+		log.Info("Begin to run phase %d/%d of the scenario: %s", i+1, len(s.runs), r.phase)
+		r.startAt = time.Now()
+		if err := s.runPhase(r); err != nil {
+			return fmt.Errorf("scenario phase %d (%s): %w", i+1, r.phase.Name, err)
+		}
+		r.stopAt = time.Now()
+		log.Info("Phase %s finished in %s", r.phase.Name, r.stopAt.Sub(r.startAt).Round(time.Millisecond))
+
+		s.mu.Lock()
+		stopped := s.stopped
+		s.mu.Unlock()
+		if stopped {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Scenario) runPhase(r *phaseRun) error {
+	if len(r.phase.SQL) > 0 {
+		r.finished = true
+		return execStatements(s.Config.DB, r.phase.SQL)
+	}
+
+	e, err := New(r.config)
+	if !s.start(&r.sequenceRun, e) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run()
+	}()
+	if r.phase.Duration <= 0 {
+		if err = <-done; err != nil {
+			return err
+		}
+		s.end(&r.sequenceRun)
+		return nil
+	}
+
+	timer := time.NewTimer(r.phase.Duration)
+	defer timer.Stop()
+	select {
+	case err = <-done:
+		if err != nil {
+			return err
+		}
+	case <-timer.C:
+		log.Info("Stop phase %s as it has run for %s", r.phase.Name, r.phase.Duration)
+		if !s.end(&r.sequenceRun) {
+			return nil
+		}
+		// the engine may fail writing or querying as it is closed
+		if err = <-done; err != nil {
+			log.Verbose("Phase %s stopped with: %v", r.phase.Name, err)
+		}
+		return nil
+	}
+	s.end(&r.sequenceRun)
+	return nil
+}
+
+// execStatements executes the SQL statements in order, each on its own
+func execStatements(params util.DBConnParams, statements []string) error {
+	conn, err := util.CreateDBConnection(params)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, stmt := range statements {
+		log.Info("Execute: %s", stmt)
+		if _, err = conn.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the scenario, closing and reporting the phase running.
+func (s *Scenario) Close() error {
+	s.stop()
+	return nil
+}
+
+// PrintStat prints the results of all the phases finished.
+func (s *Scenario) PrintStat() {
+	if s.Config.GlobalCfg.Dump {
+		return
+	}
+	tbl := s.reportTable()
+	tbl.SetTitle("Scenario Summary")
+	fmt.Println(tbl.Render())
+}
+
+func (s *Scenario) PrintProgress() {
+	s.printProgress()
+}
+
+// GetFormattedSummary writes the results of all the phases finished into a report of the scenario.
+func (s *Scenario) GetFormattedSummary() {
+	writeReport(s.Config, fmt.Sprintf(_SCENARIO_REPORT_FILE_FMT, s.StartAt.Format("20060102150405")), s.reportTable())
+}
+
+// SaveResult does nothing, as each phase is saved as a run of its own once it is finished.
+func (s *Scenario) SaveResult() {}
+
+func (s *Scenario) CheckThresholds() error {
+	runs := make([]*sequenceRun, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, &r.sequenceRun)
+	}
+	return s.checkThresholds("scenario phases", runs)
+}
+
+func (s *Scenario) reportTable() table.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return renderScenarioReport(s.runs, s.Config.GlobalCfg.ResultsDB != "")
+}
+
+// renderScenarioReport returns a table of the results of the phases finished,
+// led by the number, the name and the elapsed time of the phase.
+func renderScenarioReport(runs []*phaseRun, withRunID bool) table.Writer {
+	var prefixes []table.Row
+	var results [][]runResult
+	for i, r := range runs {
+		if !r.finished {
+			continue
+		}
+		elapsed := "-"
+		if r.stopAt.After(r.startAt) {
+			elapsed = r.stopAt.Sub(r.startAt).Round(time.Millisecond).String()
+		}
+		prefixes = append(prefixes, table.Row{i + 1, r.phase.Name, elapsed})
+		phaseResults := r.results
+		if len(phaseResults) == 0 {
+			// a phase executing SQL
+			phaseResults = []runResult{{table: "-"}}
+		}
+		results = append(results, phaseResults)
+	}
+	return renderResultsReport(table.Row{"Phase", "Name", "Elapsed"}, prefixes, results, withRunID)
+}
+
+func (s *Scenario) IsNil() bool {
+	return s == nil
+}
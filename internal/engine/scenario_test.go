@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scenario", func() {
+	It("should report the results of all the phases", func() {
+		start := time.Now()
+		runs := []*phaseRun{
+			{phase: ScenarioPhase{Name: "backfill"}, startAt: start, stopAt: start.Add(time.Minute),
+				sequenceRun: sequenceRun{finished: true, results: []runResult{{
+					table:  "t1",
+					writer: &WriterSummary{StartAt: start, StopAt: start.Add(2 * time.Second), Lines: 1000},
+				}}}},
+			{phase: ScenarioPhase{Name: "vacuum", SQL: []string{"VACUUM t1"}}, startAt: start, stopAt: start.Add(time.Second),
+				sequenceRun: sequenceRun{finished: true}},
+			{phase: ScenarioPhase{Name: "steady state"}},
+		}
+
+		csv := renderScenarioReport(runs, false).RenderCSV()
+		Expect(strings.Split(csv, "\n")).To(Equal([]string{
+			"Phase,Name,Elapsed,Table,Rows/s,Query,Parallel,TPS,Errors,P50 Latency,P95 Latency,P99 Latency",
+			"1,backfill,1m0s,t1,500.00,-,-,-,-,-,-,-",
+			"2,vacuum,1s,-,-,-,-,-,-,-,-,-",
+		}))
+		Expect(runs[1].phase.String()).To(Equal("vacuum: VACUUM t1"))
+	})
+})
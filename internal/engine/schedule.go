@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+// A query starting later than its schedule by more than this misses its schedule.
+const _SCHEDULE_TOLERANCE = time.Millisecond
+
+// arrivalSchedule hands out the scheduled start of every query in open-loop mode.
+// Connections take the schedules in order, a schedule taken late means
+// the query has been queued, which is counted in its response time.
+type arrivalSchedule struct {
+	mu       sync.Mutex
+	next     time.Time
+	interval float64 // mean inter-arrival time in nanoseconds
+	arrival  ArrivalDistribution
+	rnd      *rand.Rand
+
+	// limit is the number of queries to schedule, non-positive means unlimited
+	issued, limit int64
+}
+
+func newArrivalSchedule(opt ExecBenchOption) (*arrivalSchedule, error) {
+	arrival := opt.Arrival
+	if arrival == "" {
+		arrival = ArrivalUniform
+	}
+	if arrival != ArrivalUniform && arrival != ArrivalPoisson {
+		return nil, mxerror.CommonErrorf("unknown arrival distribution %s, should be %s or %s", opt.Arrival, ArrivalUniform, ArrivalPoisson)
+	}
+	var limit int64
+	if opt.RunTimes > 0 {
+		limit = opt.RunTimes * int64(opt.Parallel)
+	}
+	return &arrivalSchedule{
+		interval: float64(time.Second) / opt.TargetQPS,
+		arrival:  arrival,
+		rnd:      util.NewRand(opt.Seed, "arrival"),
+		limit:    limit,
+	}, nil
+}
+
+// take returns the scheduled start of the next query,
+// false if all the queries have been scheduled.
+func (s *arrivalSchedule) take() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limit > 0 && s.issued >= s.limit {
+		return time.Time{}, false
+	}
+	s.issued++
+	if s.next.IsZero() {
+		// the first query is scheduled once it is taken
+		s.next = time.Now()
+	}
+
+	scheduled := s.next
+	interval := s.interval
+	if s.arrival == ArrivalPoisson {
+		// inter-arrival times of a poisson process are exponentially distributed
+		interval *= s.rnd.ExpFloat64()
+	}
+	s.next = s.next.Add(time.Duration(interval))
+	return scheduled, true
+}
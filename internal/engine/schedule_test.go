@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Arrival Schedule", func() {
+	It("should reject unknown arrival distribution", func() {
+		_, err := newArrivalSchedule(ExecBenchOption{TargetQPS: 10, Arrival: "burst"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should schedule uniformly", func() {
+		s, err := newArrivalSchedule(ExecBenchOption{TargetQPS: 100, Parallel: 2, RunTimes: 2})
+		Expect(err).NotTo(HaveOccurred())
+		first, ok := s.take()
+		Expect(ok).To(BeTrue())
+		for i := 1; i < 4; i++ {
+			scheduled, ok := s.take()
+			Expect(ok).To(BeTrue())
+			Expect(scheduled.Sub(first)).To(Equal(time.Duration(i) * 10 * time.Millisecond))
+		}
+		// RunTimes * Parallel queries in total
+		_, ok = s.take()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should schedule by poisson process", func() {
+		s, err := newArrivalSchedule(ExecBenchOption{TargetQPS: 1000, Arrival: ArrivalPoisson})
+		Expect(err).NotTo(HaveOccurred())
+		first, _ := s.take()
+		last := first
+		for i := 1; i <= 10000; i++ {
+			scheduled, ok := s.take()
+			Expect(ok).To(BeTrue())
+			Expect(scheduled).NotTo(BeTemporally("<", last))
+			last = scheduled
+		}
+		// the mean inter-arrival time is about 1ms
+		Expect(last.Sub(first)).To(BeNumerically("~", 10*time.Second, time.Second))
+	})
+})
+
+var _ = Describe("ExecBenchStat in open-loop mode", func() {
+	It("should report service time and missed schedule", func() {
+		ebs := NewExecBenchStat(ExecBenchOption{Parallel: 1, TargetQPS: 10}, nil)
+		Expect(ebs.OpenLoop).NotTo(BeNil())
+		for i := 1; i <= 4; i++ {
+			ebs.addOpenLoopLatency(time.Duration(i*10)*time.Millisecond, time.Duration(i)*time.Millisecond, i > 2)
+		}
+		ebs.TimeElapsed = int64(time.Second)
+		ebs.complete()
+		Expect(ebs.OpenLoop.MissedSchedule).To(Equal(int64(2)))
+		Expect(ebs.MaxLatency).To(Equal(40 * time.Millisecond))
+		Expect(ebs.OpenLoop.MaxServiceTime).To(Equal(4 * time.Millisecond))
+		// percentiles keep 3 significant digits
+		Expect(ebs.OpenLoop.P50ServiceTime).To(BeNumerically("~", 3*time.Millisecond, 3*time.Microsecond))
+	})
+
+	It("should not report open-loop stat in closed-loop mode", func() {
+		Expect(NewExecBenchStat(ExecBenchOption{Parallel: 1}, nil).OpenLoop).To(BeNil())
+	})
+})
@@ -0,0 +1,205 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+// sequence runs engines of different configs one after another, as the points of a sweep,
+// or the phases of a scenario. Each run is closed and reported as a run of its own as soon as it is done,
+// and the results of all the runs are reported together in the end.
+type sequence struct {
+	mu      sync.Mutex
+	current *sequenceRun
+	stopped bool
+}
+
+// sequenceRun is one of the runs of a sequence
+type sequenceRun struct {
+	config *Config
+	engine IEngine
+
+	finished bool
+	violated bool
+	results  []runResult
+}
+
+// runResult is what a table of a run has written and queried
+type runResult struct {
+	table   string
+	runID   string
+	writer  *WriterSummary
+	queries []*ExecBenchStat
+}
+
+// start makes the run the current one, unless the sequence is stopped
+func (s *sequence) start(r *sequenceRun, e IEngine) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		if e != nil && !e.IsNil() {
+			_ = e.Close()
+		}
+		return false
+	}
+	r.engine = e
+	s.current = r
+	return true
+}
+
+// end finishes the run, unless the sequence is stopped, which has finished it
+func (s *sequence) end(r *sequenceRun) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.finish(r)
+	return true
+}
+
+// finish closes the engine of the run and reports it as a run of its own
+func (s *sequence) finish(r *sequenceRun) {
+	if r.finished || r.engine == nil || r.engine.IsNil() {
+		return
+	}
+	r.finished = true
+	e := r.engine
+	if err := e.Close(); err != nil {
+		log.Warn("Close engine failed: %v", err)
+	}
+	e.PrintStat()
+	if err := e.CheckThresholds(); err != nil {
+		r.violated = true
+	}
+	e.GetFormattedSummary()
+	e.SaveResult()
+	r.results = collectRunResults(e)
+}
+
+// stop stops the sequence, finishing the run in progress.
+func (s *sequence) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	if s.current != nil {
+		s.finish(s.current)
+	}
+}
+
+func (s *sequence) printProgress() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil && !s.current.finished {
+		s.current.engine.PrintProgress()
+	}
+}
+
+// checkThresholds returns an error with ExitCodeThresholdViolated,
+// if the thresholds are violated in any of the runs, called as the kind of the runs.
+func (s *sequence) checkThresholds(kind string, runs []*sequenceRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var violations int
+	for _, r := range runs {
+		if r.violated {
+			violations++
+		}
+	}
+	if violations > 0 {
+		return mxerror.ThresholdViolatedErrorf("thresholds violated in %d of the %s", violations, kind)
+	}
+	return nil
+}
+
+// writeReport writes the report into report-path, if report-format is csv
+func writeReport(cfg *Config, fileName string, tbl table.Writer) {
+	if cfg.GlobalCfg.Dump || cfg.GlobalCfg.ReportFormat != ReportFormatCSV {
+		return
+	}
+	filePath := filepath.Join(cfg.GlobalCfg.ReportPath, fileName)
+	if err := os.WriteFile(filePath, []byte(tbl.RenderCSV()+"\n"), 0644); err != nil {
+		log.Warn("Write report failed: %v", err)
+		return
+	}
+	log.Info("Report written to %s", filePath)
+}
+
+func collectRunResults(e IEngine) []runResult {
+	var engines []*Engine
+	switch e := e.(type) {
+	case *Engine:
+		engines = []*Engine{e}
+	case *Group:
+		engines = e.Engines
+	}
+
+	results := make([]runResult, 0, len(engines))
+	for _, e := range engines {
+		if e.Config.GlobalCfg.Dump {
+			continue
+		}
+		r := runResult{table: e.Config.GlobalCfg.TableName, runID: e.RunID}
+		if stat, ok := e.IWriter.GetStat().(WriterStat); ok {
+			summary := stat.GetWriterSummary()
+			r.writer = &summary
+		}
+		if stat := e.IBenchmark.GetStat(); stat != nil {
+			for _, ss := range stat.GetSubStats() {
+				if ebs, ok := ss.(*ExecBenchStat); ok && ebs.Histogram != nil && ebs.Histogram.Count() > 0 {
+					ebs.complete()
+					r.queries = append(r.queries, ebs)
+				}
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// renderResultsReport returns a table of the results of the runs,
+// a row for each query of each table of each run, after the columns telling the run.
+func renderResultsReport(header table.Row, prefixes []table.Row, results [][]runResult, withRunID bool) table.Writer {
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+
+	header = append(append(table.Row{}, header...), "Table")
+	if withRunID {
+		header = append(header, "Run ID")
+	}
+	header = append(header, "Rows/s", "Query", "Parallel", "TPS", "Errors", "P50 Latency", "P95 Latency", "P99 Latency")
+	tbl.AppendHeader(header)
+
+	for i, prefix := range prefixes {
+		for _, result := range results[i] {
+			row := append(append(table.Row{}, prefix...), result.table)
+			if withRunID {
+				row = append(row, result.runID)
+			}
+			rowsPerSecond := "-"
+			if w := result.writer; w != nil && w.StopAt.After(w.StartAt) {
+				rowsPerSecond = fmt.Sprintf("%.2f", float64(w.Lines)/w.StopAt.Sub(w.StartAt).Seconds())
+			}
+			row = append(row, rowsPerSecond)
+
+			if len(result.queries) == 0 {
+				tbl.AppendRow(append(row, "-", "-", "-", "-", "-", "-", "-"))
+				continue
+			}
+			for _, ebs := range result.queries {
+				tbl.AppendRow(append(append(table.Row{}, row...), ebs.GetQuery().GetName(), ebs.GetOption().Parallel, ebs.TPS, ebs.Errors.Total(),
+					fmt.Sprintf("%.3fms", float64(ebs.P50Latency.Nanoseconds())/1e6),
+					fmt.Sprintf("%.3fms", float64(ebs.P95Latency.Nanoseconds())/1e6),
+					fmt.Sprintf("%.3fms", float64(ebs.P99Latency.Nanoseconds())/1e6)))
+			}
+		}
+	}
+	return tbl
+}
@@ -3,7 +3,8 @@ package engine
 import (
 	"encoding/json"
 	"fmt"
-	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 
 	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/histogram"
 )
 
 type ReportFormat = string
@@ -32,35 +35,231 @@ type Stat interface {
 	GetCurrentProgress(opt ...interface{}) map[string]interface{}
 }
 
+// _HIGHEST_LATENCY is the highest latency tracked by the histograms,
+// a longer one is still counted, as if it were the highest.
+const _HIGHEST_LATENCY = time.Hour
+
 func NewExecBenchStat(opt ExecBenchOption, query Query) *ExecBenchStat {
-	return &ExecBenchStat{
-		latencies:    make([]time.Duration, 0, 100*opt.Parallel), // 100 is the default of run times
+	ebs := &ExecBenchStat{
+		Histogram:    histogram.New(int64(_HIGHEST_LATENCY)),
 		opt:          opt,
 		query:        query,
+		Errors:       &ErrorStat{},
 		reportFormat: ReportFormatJSON,
 	}
+	if opt.TargetQPS > 0 {
+		ebs.OpenLoop = &OpenLoopStat{
+			TargetQPS:    opt.TargetQPS,
+			serviceTimes: histogram.New(int64(_HIGHEST_LATENCY)),
+		}
+	}
+	if opt.Validate {
+		ebs.Validation = &ValidationStat{}
+	}
+	return ebs
 }
 
-type ExecBenchStat struct {
-	TPS        int           `json:"tps"`
-	AvgLatency time.Duration `json:"avg-latency"`
-	MaxLatency time.Duration `json:"max-latency"`
-	P75Latency time.Duration `json:"p75-latency"`
-	P50Latency time.Duration `json:"p50-latency"`
-	P25Latency time.Duration `json:"p25-latency"`
+// OpenLoopStat is only reported in open-loop mode,
+// where the latencies of ExecBenchStat are response times measured from the scheduled start,
+// while the service times here are measured from the actual start.
+type OpenLoopStat struct {
+	TargetQPS      float64       `json:"target-qps"`
+	MissedSchedule int64         `json:"missed-schedule"`
+	AvgServiceTime time.Duration `json:"avg-service-time"`
+	MaxServiceTime time.Duration `json:"max-service-time"`
+	P99ServiceTime time.Duration `json:"p99-service-time"`
+	P75ServiceTime time.Duration `json:"p75-service-time"`
+	P50ServiceTime time.Duration `json:"p50-service-time"`
+	P25ServiceTime time.Duration `json:"p25-service-time"`
+
+	serviceTimes *histogram.Histogram
+}
+
+// ValidationStat is only reported when the results of the query are validated.
+type ValidationStat struct {
+	Validated int64 `json:"validated"`
+	Invalid   int64 `json:"invalid"`
+	// FirstInvalid tells why the first invalid result is invalid
+	FirstInvalid string `json:"first-invalid,omitempty"`
+
+	mu sync.Mutex
+}
+
+// ErrorStat counts the failed executions of the query by the class of the error.
+type ErrorStat struct {
+	Timeout       int64 `json:"timeout"`
+	Canceled      int64 `json:"canceled"`
+	OOM           int64 `json:"oom"`
+	Serialization int64 `json:"serialization"`
+	Connection    int64 `json:"connection"`
+	Other         int64 `json:"other"`
+
+	// Retries counts the executions retried, and Reconnects the connections reestablished to retry them
+	Retries    int64 `json:"retries"`
+	Reconnects int64 `json:"reconnects"`
+	// StoppedConnections counts the connections stopped executing the query by an error
+	StoppedConnections int64 `json:"stopped-connections"`
+	// FirstError is the first error of the executions
+	FirstError string `json:"first-error,omitempty"`
+
+	mu sync.Mutex
+}
+
+func (es *ErrorStat) counter(class ErrorClass) *int64 {
+	switch class {
+	case ErrorClassTimeout:
+		return &es.Timeout
+	case ErrorClassCanceled:
+		return &es.Canceled
+	case ErrorClassOOM:
+		return &es.OOM
+	case ErrorClassSerialization:
+		return &es.Serialization
+	case ErrorClassConnection:
+		return &es.Connection
+	}
+	return &es.Other
+}
+
+// Count returns the number of errors of the class
+func (es *ErrorStat) Count(class ErrorClass) int64 {
+	return atomic.LoadInt64(es.counter(class))
+}
 
-	mu        sync.Mutex
-	latencies []time.Duration
+// Total returns the number of errors of all the classes
+func (es *ErrorStat) Total() int64 {
+	var total int64
+	for _, class := range ErrorClasses {
+		total += es.Count(class)
+	}
+	return total
+}
+
+// String tells the number of errors, and of each class if any, e.g. "3 (timeout: 2, connection: 1)"
+func (es *ErrorStat) String() string {
+	total := es.Total()
+	if total == 0 {
+		return "0"
+	}
+	counts := make([]string, 0, len(ErrorClasses))
+	for _, class := range ErrorClasses {
+		if n := es.Count(class); n > 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d", class, n))
+		}
+	}
+	return fmt.Sprintf("%d (%s)", total, strings.Join(counts, ", "))
+}
+
+func (es *ErrorStat) add(class ErrorClass, err error) {
+	atomic.AddInt64(es.counter(class), 1)
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if es.FirstError == "" {
+		es.FirstError = err.Error()
+	}
+}
+
+type ExecBenchStat struct {
+	TPS           int           `json:"tps"`
+	AvgLatency    time.Duration `json:"avg-latency"`
+	StdDevLatency time.Duration `json:"stddev-latency"`
+	MinLatency    time.Duration `json:"min-latency"`
+	MaxLatency    time.Duration `json:"max-latency"`
+	P999Latency   time.Duration `json:"p99.9-latency"`
+	P99Latency    time.Duration `json:"p99-latency"`
+	P95Latency    time.Duration `json:"p95-latency"`
+	P90Latency    time.Duration `json:"p90-latency"`
+	P75Latency    time.Duration `json:"p75-latency"`
+	P50Latency    time.Duration `json:"p50-latency"`
+	P25Latency    time.Duration `json:"p25-latency"`
+
+	// Histogram holds all the latencies in nanoseconds,
+	// it is exported in the report so that runs could be merged offline.
+	Histogram *histogram.Histogram `json:"histogram"`
 
 	runs        int64
 	TimeElapsed int64 `json:"overall-duration"`
 
+	OpenLoop *OpenLoopStat `json:"open-loop,omitempty"`
+
+	Validation *ValidationStat `json:"validation,omitempty"`
+
+	Errors *ErrorStat `json:"errors"`
+
 	query Query
 	opt   ExecBenchOption
+	// mixedRuns counts the executions of all the queries of the mixed workload the query is in, if any,
+	// where the progress is of the mixed workload instead of the query
+	mixedRuns *int64
 
 	reportFormat ReportFormat
 }
 
+// MixedBenchStat is the stat of a MixedQuery, made of a stat of each of its queries,
+// as if they were executed on their own.
+type MixedBenchStat struct {
+	query *MixedQuery
+	stats []*ExecBenchStat
+}
+
+func NewMixedBenchStat(opt ExecBenchOption, query *MixedQuery) *MixedBenchStat {
+	ms := &MixedBenchStat{query: query}
+	var runs int64
+	for _, q := range query.Queries {
+		ebs := NewExecBenchStat(opt, q)
+		ebs.mixedRuns = &runs
+		ms.stats = append(ms.stats, ebs)
+	}
+	return ms
+}
+
+func (ms *MixedBenchStat) AddSubStat(_ Stat) {}
+
+func (ms *MixedBenchStat) GetName() string {
+	return fmt.Sprintf("stats for query %s, with parallel %d", ms.query.GetName(), ms.stats[0].opt.Parallel)
+}
+
+func (ms *MixedBenchStat) GetSummary() string {
+	summaries := make([]string, 0, len(ms.stats))
+	for _, ebs := range ms.stats {
+		summaries = append(summaries, ebs.query.GetName()+"\n"+ebs.GetSummary())
+	}
+	return strings.Join(summaries, "\n")
+}
+
+func (ms *MixedBenchStat) GetFormattedSummary() string {
+	summaries := make([]string, 0, len(ms.stats))
+	for _, ebs := range ms.stats {
+		summaries = append(summaries, ebs.GetFormattedSummary())
+	}
+	return strings.Join(summaries, "\n")
+}
+
+func (ms *MixedBenchStat) GetProgress() string {
+	return ms.stats[0].GetProgress()
+}
+
+// GetSubStats returns the stat of each query, in the order of the queries.
+func (ms *MixedBenchStat) GetSubStats() []Stat {
+	stats := make([]Stat, 0, len(ms.stats))
+	for _, ebs := range ms.stats {
+		stats = append(stats, ebs)
+	}
+	return stats
+}
+
+func (ms *MixedBenchStat) GetCurrentProgress(_ ...interface{}) map[string]interface{} {
+	return nil
+}
+
+func (ebs *ExecBenchStat) GetQuery() Query {
+	return ebs.query
+}
+
+func (ebs *ExecBenchStat) GetOption() ExecBenchOption {
+	return ebs.opt
+}
+
 func (ebs *ExecBenchStat) GetName() string {
 	return fmt.Sprintf("stats for query %s, with parallel %d", ebs.query.GetName(), ebs.opt.Parallel)
 }
@@ -70,7 +269,7 @@ func (ebs *ExecBenchStat) GetName() string {
 // Any data in float will be rounded to 2 decimal places.
 func (ebs *ExecBenchStat) GetSummary() string {
 	ebs.complete()
-	if len(ebs.latencies) == 0 {
+	if ebs.Histogram.Count() == 0 && ebs.Errors.Total() == 0 {
 		return "not actually executed"
 	}
 	tbl := table.NewWriter()
@@ -82,11 +281,43 @@ func (ebs *ExecBenchStat) GetSummary() string {
 	tbl.AppendRows([]table.Row{
 		{"Overall Duration", fmt.Sprintf("%.2fs", float64(time.Duration(atomic.LoadInt64(&ebs.TimeElapsed)))/1e9)},
 		{"Average Latency", fmt.Sprintf("%.3fms", float64(ebs.AvgLatency.Nanoseconds())/1e6)},
+		{"StdDev Latency", fmt.Sprintf("%.3fms", float64(ebs.StdDevLatency.Nanoseconds())/1e6)},
+		{"Min Latency", fmt.Sprintf("%.3fms", float64(ebs.MinLatency.Nanoseconds())/1e6)},
+		{"Max Latency", fmt.Sprintf("%.3fms", float64(ebs.MaxLatency.Nanoseconds())/1e6)},
+		{"P99.9 Latency", fmt.Sprintf("%.3fms", float64(ebs.P999Latency.Nanoseconds())/1e6)},
+		{"P99 Latency", fmt.Sprintf("%.3fms", float64(ebs.P99Latency.Nanoseconds())/1e6)},
+		{"P95 Latency", fmt.Sprintf("%.3fms", float64(ebs.P95Latency.Nanoseconds())/1e6)},
+		{"P90 Latency", fmt.Sprintf("%.3fms", float64(ebs.P90Latency.Nanoseconds())/1e6)},
 		{"P75 Latency", fmt.Sprintf("%.3fms", float64(ebs.P75Latency.Nanoseconds())/1e6)},
 		{"P50 Latency", fmt.Sprintf("%.3fms", float64(ebs.P50Latency.Nanoseconds())/1e6)},
 		{"P25 Latency", fmt.Sprintf("%.3fms", float64(ebs.P25Latency.Nanoseconds())/1e6)},
 		{"TPS", ebs.TPS},
+		{"Errors", ebs.Errors.String()},
 	})
+	if es := ebs.Errors; es.Total() > 0 {
+		tbl.AppendRows([]table.Row{
+			{"Retries", atomic.LoadInt64(&es.Retries)},
+			{"Reconnects", atomic.LoadInt64(&es.Reconnects)},
+			{"Stopped Connections", atomic.LoadInt64(&es.StoppedConnections)},
+		})
+	}
+	if ol := ebs.OpenLoop; ol != nil {
+		tbl.AppendRows([]table.Row{
+			{"Target QPS", fmt.Sprintf("%.2f", ol.TargetQPS)},
+			{"Average Service Time", fmt.Sprintf("%.3fms", float64(ol.AvgServiceTime.Nanoseconds())/1e6)},
+			{"P99 Service Time", fmt.Sprintf("%.3fms", float64(ol.P99ServiceTime.Nanoseconds())/1e6)},
+			{"P75 Service Time", fmt.Sprintf("%.3fms", float64(ol.P75ServiceTime.Nanoseconds())/1e6)},
+			{"P50 Service Time", fmt.Sprintf("%.3fms", float64(ol.P50ServiceTime.Nanoseconds())/1e6)},
+			{"P25 Service Time", fmt.Sprintf("%.3fms", float64(ol.P25ServiceTime.Nanoseconds())/1e6)},
+			{"Missed Schedule", ol.MissedSchedule},
+		})
+	}
+	if v := ebs.Validation; v != nil {
+		tbl.AppendRows([]table.Row{
+			{"Validated Results", atomic.LoadInt64(&v.Validated)},
+			{"Invalid Results", atomic.LoadInt64(&v.Invalid)},
+		})
+	}
 
 	return tbl.Render()
 }
@@ -95,7 +326,7 @@ func (ebs *ExecBenchStat) GetSummary() string {
 // No data in will be rounded.
 func (ebs *ExecBenchStat) GetFormattedSummary() string {
 	ebs.complete()
-	if len(ebs.latencies) == 0 {
+	if ebs.Histogram.Count() == 0 && ebs.Errors.Total() == 0 {
 		return "not actually executed"
 	}
 
@@ -128,59 +359,120 @@ func (ebs *ExecBenchStat) GetProgress() string {
 		return fmt.Sprintf("%d", progress)
 
 	}
-	return fmt.Sprintf("%d", int(100*float64(ebs.runs)/float64(ebs.opt.RunTimes*int64(ebs.opt.Parallel))))
+	return fmt.Sprintf("%d", int(100*float64(ebs.progressRuns())/float64(ebs.opt.RunTimes*int64(ebs.opt.Parallel))))
+}
+
+// progressRuns returns the executions the progress is of
+func (ebs *ExecBenchStat) progressRuns() int64 {
+	if ebs.mixedRuns != nil {
+		return atomic.LoadInt64(ebs.mixedRuns)
+	}
+	return atomic.LoadInt64(&ebs.runs)
+}
+
+// addRun counts an execution of the query
+func (ebs *ExecBenchStat) addRun() {
+	atomic.AddInt64(&ebs.runs, 1)
+	if ebs.mixedRuns != nil {
+		atomic.AddInt64(ebs.mixedRuns, 1)
+	}
 }
 
 func (ebs *ExecBenchStat) addLatency(latency time.Duration) {
-	ebs.mu.Lock()
-	defer ebs.mu.Unlock()
-	if ebs.latencies == nil {
-		ebs.latencies = []time.Duration{latency}
+	ebs.Histogram.Record(int64(latency))
+}
+
+// addOpenLoopLatency records a query issued in open-loop mode,
+// whose latency is the response time since its scheduled start.
+func (ebs *ExecBenchStat) addOpenLoopLatency(responseTime, serviceTime time.Duration, missed bool) {
+	ebs.Histogram.Record(int64(responseTime))
+	ebs.OpenLoop.serviceTimes.Record(int64(serviceTime))
+	if missed {
+		atomic.AddInt64(&ebs.OpenLoop.MissedSchedule, 1)
+	}
+}
+
+// addValidation counts a result validated, which is invalid if err is not nil.
+func (ebs *ExecBenchStat) addValidation(err error) {
+	v := ebs.Validation
+	if v == nil {
+		return
+	}
+	atomic.AddInt64(&v.Validated, 1)
+	if err == nil {
+		return
+	}
+	if atomic.AddInt64(&v.Invalid, 1) == 1 {
+		v.mu.Lock()
+		v.FirstInvalid = err.Error()
+		v.mu.Unlock()
+	}
+}
+
+// checkValidation returns an error if any result is invalid
+func (ebs *ExecBenchStat) checkValidation() error {
+	v := ebs.Validation
+	if v == nil {
+		return nil
+	}
+	if invalid := atomic.LoadInt64(&v.Invalid); invalid > 0 {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		return mxerror.CommonErrorf("%d of the %d results of query %s with parallel %d are invalid, the first one: %s",
+			invalid, atomic.LoadInt64(&v.Validated), ebs.query.GetName(), ebs.opt.Parallel, v.FirstInvalid)
+	}
+	return nil
+}
+
+func percentileOf(h *histogram.Histogram, percentile float64) time.Duration {
+	return time.Duration(h.ValueAtPercentile(percentile))
+}
+
+func (ol *OpenLoopStat) complete() {
+	serviceTimes := ol.serviceTimes
+	if serviceTimes.Count() == 0 {
 		return
 	}
-	ebs.latencies = append(ebs.latencies, latency)
+	ol.AvgServiceTime = time.Duration(serviceTimes.Mean())
+	ol.MaxServiceTime = time.Duration(serviceTimes.Max())
+	ol.P99ServiceTime = percentileOf(serviceTimes, 99)
+	ol.P75ServiceTime = percentileOf(serviceTimes, 75)
+	ol.P50ServiceTime = percentileOf(serviceTimes, 50)
+	ol.P25ServiceTime = percentileOf(serviceTimes, 25)
 }
 
 func (ebs *ExecBenchStat) complete() {
-	latencies := ebs.latencies
-	numOfLatencies := len(latencies)
+	if ebs.OpenLoop != nil {
+		ebs.OpenLoop.complete()
+	}
+	latencies := ebs.Histogram
+	numOfLatencies := latencies.Count()
 	if numOfLatencies == 0 {
 		return
 	}
-	sort.Slice(latencies, func(i, j int) bool {
-		return latencies[i] < latencies[j]
-	})
-	var latencySum time.Duration
-	for _, l := range latencies {
-		latencySum += l
-	}
-	ebs.AvgLatency = latencySum / time.Duration(numOfLatencies)
-	ebs.MaxLatency = latencies[numOfLatencies-1]
-	ebs.P75Latency = latencies[numOfLatencies*3/4]
-	ebs.P50Latency = latencies[numOfLatencies/2]
-	ebs.P25Latency = latencies[numOfLatencies/4]
+	ebs.AvgLatency = time.Duration(latencies.Mean())
+	ebs.StdDevLatency = time.Duration(latencies.StdDev())
+	ebs.MinLatency = time.Duration(latencies.Min())
+	ebs.MaxLatency = time.Duration(latencies.Max())
+	ebs.P999Latency = percentileOf(latencies, 99.9)
+	ebs.P99Latency = percentileOf(latencies, 99)
+	ebs.P95Latency = percentileOf(latencies, 95)
+	ebs.P90Latency = percentileOf(latencies, 90)
+	ebs.P75Latency = percentileOf(latencies, 75)
+	ebs.P50Latency = percentileOf(latencies, 50)
+	ebs.P25Latency = percentileOf(latencies, 25)
 	ebs.TPS = int(float64(numOfLatencies) / float64(time.Duration(ebs.TimeElapsed).Seconds()))
 }
 
 func (ebs *ExecBenchStat) GetCurrentProgress(_ ...interface{}) map[string]interface{} {
-	ebs.mu.Lock()
-	defer ebs.mu.Unlock()
-
 	p := make(map[string]interface{})
 
 	// 1. prepare latency data
-	latencies := ebs.latencies
-	numOfLatencies := len(latencies)
+	latencies := ebs.Histogram
+	numOfLatencies := latencies.Count()
 	if numOfLatencies == 0 {
 		return nil
 	}
-	sort.Slice(latencies, func(i, j int) bool {
-		return latencies[i] < latencies[j]
-	})
-	var latencySum time.Duration
-	for _, l := range latencies {
-		latencySum += l
-	}
 
 	// 2. calc finishedPercentage
 	percentage := 0
@@ -191,20 +483,67 @@ func (ebs *ExecBenchStat) GetCurrentProgress(_ ...interface{}) map[string]interf
 			percentage = 100
 		}
 	} else {
-		percentage = int(100 * float64(ebs.runs) / float64(ebs.opt.RunTimes*int64(ebs.opt.Parallel)))
+		percentage = int(100 * float64(ebs.progressRuns()) / float64(ebs.opt.RunTimes*int64(ebs.opt.Parallel)))
 	}
 
 	// 3. build full status
 	p["name"] = ebs.GetName()
 	p["module"] = "benchmark"
 	p["timeElapsed"] = time.Duration(atomic.LoadInt64(&ebs.TimeElapsed))
-	p["avgLatency"] = latencySum / time.Duration(numOfLatencies)
-	p["maxLatency"] = latencies[numOfLatencies-1]
-	p["p75Latency"] = latencies[numOfLatencies*3/4]
-	p["p50Latency"] = latencies[numOfLatencies/2]
-	p["p25Latency"] = latencies[numOfLatencies/4]
+	p["avgLatency"] = time.Duration(latencies.Mean())
+	p["stddevLatency"] = time.Duration(latencies.StdDev())
+	p["minLatency"] = time.Duration(latencies.Min())
+	p["maxLatency"] = time.Duration(latencies.Max())
+	p["p999Latency"] = percentileOf(latencies, 99.9)
+	p["p99Latency"] = percentileOf(latencies, 99)
+	p["p95Latency"] = percentileOf(latencies, 95)
+	p["p90Latency"] = percentileOf(latencies, 90)
+	p["p75Latency"] = percentileOf(latencies, 75)
+	p["p50Latency"] = percentileOf(latencies, 50)
+	p["p25Latency"] = percentileOf(latencies, 25)
 	p["tps"] = int(float64(numOfLatencies) / float64(time.Duration(ebs.TimeElapsed).Seconds()))
 	p["finishedPercentage"] = percentage
+	if ol := ebs.OpenLoop; ol != nil {
+		ol.complete()
+		p["targetQPS"] = ol.TargetQPS
+		p["missedSchedule"] = atomic.LoadInt64(&ol.MissedSchedule)
+		p["avgServiceTime"] = ol.AvgServiceTime
+		p["p99ServiceTime"] = ol.P99ServiceTime
+		p["p75ServiceTime"] = ol.P75ServiceTime
+		p["p50ServiceTime"] = ol.P50ServiceTime
+		p["p25ServiceTime"] = ol.P25ServiceTime
+	}
+	p["errors"] = ebs.Errors.Total()
+	if v := ebs.Validation; v != nil {
+		p["validatedResults"] = atomic.LoadInt64(&v.Validated)
+		p["invalidResults"] = atomic.LoadInt64(&v.Invalid)
+	}
 
 	return p
 }
+
+func (ebs *ExecBenchStat) CollectMetrics(mw *MetricsWriter) {
+	if ebs.query == nil {
+		return
+	}
+	labels := []MetricLabel{
+		{"query", ebs.query.GetName()},
+		{"parallel", strconv.Itoa(ebs.opt.Parallel)},
+	}
+	mw.Counter("mxbench_query_runs_total", "The number of executions of the query", float64(atomic.LoadInt64(&ebs.runs)), labels...)
+	mw.LatencyHistogram("mxbench_query_latency_seconds", "The latency of the query", ebs.Histogram, labels...)
+	if ol := ebs.OpenLoop; ol != nil {
+		mw.Counter("mxbench_query_missed_schedule_total", "The number of executions of the query started later than scheduled",
+			float64(atomic.LoadInt64(&ol.MissedSchedule)), labels...)
+	}
+	for _, class := range ErrorClasses {
+		mw.Counter("mxbench_query_errors_total", "The number of executions of the query failed by the class of the error",
+			float64(ebs.Errors.Count(class)), append(labels, MetricLabel{"class", class})...)
+	}
+	mw.Counter("mxbench_query_retries_total", "The number of executions of the query retried",
+		float64(atomic.LoadInt64(&ebs.Errors.Retries)), labels...)
+	if v := ebs.Validation; v != nil {
+		mw.Counter("mxbench_query_invalid_results_total", "The number of executions of the query returning invalid results",
+			float64(atomic.LoadInt64(&v.Invalid)), labels...)
+	}
+}
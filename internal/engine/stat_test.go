@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExecBenchStat", func() {
+	It("should not report validation unless the results are validated", func() {
+		ebs := NewExecBenchStat(ExecBenchOption{Parallel: 1}, fakeQuery("Q"))
+		Expect(ebs.Validation).To(BeNil())
+		ebs.addValidation(fmt.Errorf("invalid"))
+		Expect(ebs.checkValidation()).To(Succeed())
+	})
+
+	It("should count the invalid results and report the first one", func() {
+		ebs := NewExecBenchStat(ExecBenchOption{Parallel: 1, Validate: true}, fakeQuery("Q"))
+		ebs.addValidation(nil)
+		Expect(ebs.checkValidation()).To(Succeed())
+		ebs.addValidation(fmt.Errorf("0 rows of vin 'v1', expected 1"))
+		ebs.addValidation(fmt.Errorf("0 rows of vin 'v2', expected 1"))
+		Expect(ebs.Validation.Validated).To(Equal(int64(3)))
+		Expect(ebs.Validation.Invalid).To(Equal(int64(2)))
+		Expect(ebs.Validation.FirstInvalid).To(Equal("0 rows of vin 'v1', expected 1"))
+		Expect(ebs.checkValidation()).To(MatchError(ContainSubstring("2 of the 3 results of query Q with parallel 1 are invalid")))
+
+		b, err := json.Marshal(ebs.Validation)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(`{"validated":3,"invalid":2,"first-invalid":"0 rows of vin 'v1', expected 1"}`))
+	})
+})
+
+var _ = Describe("ErrorStat", func() {
+	It("should count the errors by class", func() {
+		ebs := NewExecBenchStat(ExecBenchOption{Parallel: 1}, fakeQuery("Q"))
+		Expect(ebs.Errors.String()).To(Equal("0"))
+
+		ebs.Errors.add(ErrorClassTimeout, fmt.Errorf("first"))
+		ebs.Errors.add(ErrorClassConnection, fmt.Errorf("second"))
+		ebs.Errors.add(ErrorClassTimeout, fmt.Errorf("third"))
+		Expect(ebs.Errors.Count(ErrorClassTimeout)).To(Equal(int64(2)))
+		Expect(ebs.Errors.Total()).To(Equal(int64(3)))
+		Expect(ebs.Errors.String()).To(Equal("3 (timeout: 2, connection: 1)"))
+		Expect(ebs.Errors.FirstError).To(Equal("first"))
+		// reported even if none is executed successfully
+		Expect(ebs.GetSummary()).To(ContainSubstring("3 (timeout: 2, connection: 1)"))
+	})
+})
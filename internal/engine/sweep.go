@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/lib/pq"
+
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+)
+
+const (
+	_DROP_TABLE_SQL = `DROP TABLE IF EXISTS %s`
+
+	_SWEEP_REPORT_FILE_FMT = "sweep_%s.csv"
+)
+
+// SweepOption is an option of the [sweep] section of the config file,
+// with the values to run in the form of the command line.
+type SweepOption struct {
+	Name   string
+	Values []string
+	// KeepsTable tells the option does not change the table loaded, e.g. an option of the benchmark
+	KeepsTable bool
+}
+
+// SweepConfig is the [sweep] section of the config file, run by command sweep.
+// The points are the cartesian product of the values of the options,
+// in which the last option varies the fastest.
+type SweepConfig struct {
+	Options []SweepOption
+}
+
+// SweepPoint is one of the combinations of the values of the options swept.
+type SweepPoint struct {
+	Names  []string
+	Values []string
+	// SharesTable tells the point differs from the previous one only in options keeping the table,
+	// so it runs the queries against the table loaded by the previous one, instead of loading it again.
+	SharesTable bool
+}
+
+// GetPoints returns the points of the sweep in the order to run.
+func (cfg SweepConfig) GetPoints() []SweepPoint {
+	if len(cfg.Options) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Options))
+	for _, opt := range cfg.Options {
+		if len(opt.Values) == 0 {
+			return nil
+		}
+		names = append(names, opt.Name)
+	}
+
+	var points []SweepPoint
+	indexes := make([]int, len(cfg.Options))
+	for {
+		p := SweepPoint{Names: names, Values: make([]string, len(cfg.Options))}
+		for i, opt := range cfg.Options {
+			p.Values[i] = opt.Values[indexes[i]]
+		}
+		if len(points) > 0 {
+			prev := points[len(points)-1]
+			p.SharesTable = true
+			for i, opt := range cfg.Options {
+				if p.Values[i] != prev.Values[i] && !opt.KeepsTable {
+					p.SharesTable = false
+				}
+			}
+		}
+		points = append(points, p)
+
+		i := len(indexes) - 1
+		for ; i >= 0; i-- {
+			indexes[i]++
+			if indexes[i] < len(cfg.Options[i].Values) {
+				break
+			}
+			indexes[i] = 0
+		}
+		if i < 0 {
+			return points
+		}
+	}
+}
+
+// GetArgs returns the command line flags giving the values of the point.
+func (p SweepPoint) GetArgs() []string {
+	args := make([]string, 0, len(p.Names))
+	for i, name := range p.Names {
+		args = append(args, fmt.Sprintf("--%s=%s", name, p.Values[i]))
+	}
+	return args
+}
+
+func (p SweepPoint) String() string {
+	pairs := make([]string, 0, len(p.Names))
+	for i, name := range p.Names {
+		pairs = append(pairs, name+"="+p.Values[i])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// sweepRun is the run of a point of a sweep
+type sweepRun struct {
+	sequenceRun
+	point SweepPoint
+}
+
+// Sweep runs the points of command sweep one after another, each by an engine of its own config.
+type Sweep struct {
+	sequence
+
+	Config *Config
+
+	// StartAt is when Run is called
+	StartAt time.Time
+
+	runs []*sweepRun
+}
+
+var _ IEngine = (*Sweep)(nil)
+
+// NewSweep creates a Sweep of the points of the config,
+// given the config of each point derived from it.
+func NewSweep(cfg *Config, pointConfigs []*Config) *Sweep {
+	s := &Sweep{Config: cfg}
+	for i, p := range cfg.SweepCfg.GetPoints() {
+		s.runs = append(s.runs, &sweepRun{sequenceRun: sequenceRun{config: pointConfigs[i]}, point: p})
+	}
+	return s
+}
+
+func (s *Sweep) Run() error {
+	s.StartAt = time.Now()
+	// the tables created by the points run so far
+	created := map[string]bool{}
+	for i, r := range s.runs {
+		log.Info("Begin to run point %d/%d of the sweep: %s", i+1, len(s.runs), r.point)
+		e, err := New(r.config)
+		if !s.start(&r.sequenceRun, e) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !r.point.SharesTable && !r.config.GlobalCfg.Dump && !writesWithoutDB(e) {
+			for _, identifier := range tableIdentifiers(r.config) {
+				if !created[identifier] {
+					created[identifier] = true
+					continue
+				}
+				log.Info("Drop table %s to load it again", identifier)
+				if err = dropTable(r.config.DB, identifier); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = e.Run(); err != nil {
+			return fmt.Errorf("sweep point %d (%s): %w", i+1, r.point, err)
+		}
+		if !s.end(&r.sequenceRun) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tableIdentifiers returns the tables the config runs
+func tableIdentifiers(cfg *Config) []string {
+	names := []string{cfg.GlobalCfg.TableName}
+	if tables := cfg.GlobalCfg.GetTables(); len(tables) > 0 {
+		names = names[:0]
+		for _, t := range tables {
+			names = append(names, t.TableName)
+		}
+	}
+	identifiers := make([]string, 0, len(names))
+	for _, name := range names {
+		identifiers = append(identifiers, fmt.Sprintf("%s.%s", pq.QuoteIdentifier(cfg.GlobalCfg.SchemaName), pq.QuoteIdentifier(name)))
+	}
+	return identifiers
+}
+
+// writesWithoutDB tells the tables of the engine are written without the database, so there is no table to drop
+func writesWithoutDB(e IEngine) bool {
+	switch e := e.(type) {
+	case *Engine:
+		return e.writesWithoutDB()
+	case *Group:
+		return len(e.Engines) > 0 && e.Engines[0].writesWithoutDB()
+	}
+	return false
+}
+
+func dropTable(params util.DBConnParams, identifier string) error {
+	conn, err := util.CreateDBConnection(params)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Exec(fmt.Sprintf(_DROP_TABLE_SQL, identifier))
+	return err
+}
+
+// Close stops the sweep, closing and reporting the point running.
+func (s *Sweep) Close() error {
+	s.stop()
+	return nil
+}
+
+// PrintStat prints the results of all the points finished.
+func (s *Sweep) PrintStat() {
+	if s.Config.GlobalCfg.Dump {
+		return
+	}
+	tbl := s.reportTable()
+	tbl.SetTitle("Sweep Summary")
+	fmt.Println(tbl.Render())
+}
+
+func (s *Sweep) PrintProgress() {
+	s.printProgress()
+}
+
+// GetFormattedSummary writes the results of all the points finished into a report of the sweep.
+func (s *Sweep) GetFormattedSummary() {
+	writeReport(s.Config, fmt.Sprintf(_SWEEP_REPORT_FILE_FMT, s.StartAt.Format("20060102150405")), s.reportTable())
+}
+
+// SaveResult does nothing, as each point is saved as a run of its own once it is finished.
+func (s *Sweep) SaveResult() {}
+
+func (s *Sweep) CheckThresholds() error {
+	runs := make([]*sequenceRun, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, &r.sequenceRun)
+	}
+	return s.checkThresholds("sweep points", runs)
+}
+
+func (s *Sweep) reportTable() table.Writer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	if len(s.runs) > 0 {
+		names = s.runs[0].point.Names
+	}
+	return renderSweepReport(names, s.runs, s.Config.GlobalCfg.ResultsDB != "")
+}
+
+// renderSweepReport returns a table of the results of the points finished,
+// led by the number of the point and the values of the options.
+func renderSweepReport(names []string, runs []*sweepRun, withRunID bool) table.Writer {
+	header := table.Row{"Point"}
+	for _, name := range names {
+		header = append(header, name)
+	}
+	var prefixes []table.Row
+	var results [][]runResult
+	for i, r := range runs {
+		if !r.finished {
+			continue
+		}
+		prefix := table.Row{i + 1}
+		for _, v := range r.point.Values {
+			prefix = append(prefix, v)
+		}
+		prefixes = append(prefixes, prefix)
+		results = append(results, r.results)
+	}
+	return renderResultsReport(header, prefixes, results, withRunID)
+}
+
+func (s *Sweep) IsNil() bool {
+	return s == nil
+}
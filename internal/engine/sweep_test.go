@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sweep", func() {
+	cfg := SweepConfig{Options: []SweepOption{
+		{Name: "storage-type", Values: []string{"mars2", "mars3"}},
+		{Name: "writer-parallel", Values: []string{"4"}},
+		{Name: "benchmark-parallel", Values: []string{"8", "16"}, KeepsTable: true},
+	}}
+
+	It("should run the cartesian product of the values", func() {
+		points := cfg.GetPoints()
+		Expect(points).To(HaveLen(4))
+
+		var got []string
+		var shares []bool
+		for _, p := range points {
+			got = append(got, p.String())
+			shares = append(shares, p.SharesTable)
+		}
+		Expect(got).To(Equal([]string{
+			"storage-type=mars2 writer-parallel=4 benchmark-parallel=8",
+			"storage-type=mars2 writer-parallel=4 benchmark-parallel=16",
+			"storage-type=mars3 writer-parallel=4 benchmark-parallel=8",
+			"storage-type=mars3 writer-parallel=4 benchmark-parallel=16",
+		}))
+		Expect(shares).To(Equal([]bool{false, true, false, true}))
+		Expect(points[3].GetArgs()).To(Equal([]string{
+			"--storage-type=mars3", "--writer-parallel=4", "--benchmark-parallel=16",
+		}))
+
+		Expect(SweepConfig{}.GetPoints()).To(BeEmpty())
+		Expect(SweepConfig{Options: []SweepOption{{Name: "tag-num"}}}.GetPoints()).To(BeEmpty())
+	})
+
+	It("should report the results of all the points", func() {
+		points := cfg.GetPoints()
+		start := time.Now()
+		runs := []*sweepRun{
+			{point: points[0], sequenceRun: sequenceRun{finished: true, results: []runResult{{
+				table:   "t1",
+				runID:   "1",
+				writer:  &WriterSummary{StartAt: start, StopAt: start.Add(2 * time.Second), Lines: 1000},
+				queries: []*ExecBenchStat{newFakeExecBenchStat("q1", 8, time.Millisecond, time.Millisecond, 3*time.Millisecond)},
+			}}}},
+			{point: points[1], sequenceRun: sequenceRun{finished: true, results: []runResult{{table: "t1", runID: "2"}}}},
+			{point: points[2]},
+		}
+		for _, ebs := range runs[0].results[0].queries {
+			ebs.complete()
+		}
+
+		csv := renderSweepReport(points[0].Names, runs, true).RenderCSV()
+		Expect(strings.Split(csv, "\n")).To(Equal([]string{
+			"Point,storage-type,writer-parallel,benchmark-parallel,Table,Run ID,Rows/s,Query,Parallel,TPS,Errors,P50 Latency,P95 Latency,P99 Latency",
+			"1,mars2,4,8,t1,1,500.00,q1,8,3,0,1.000ms,3.000ms,3.000ms",
+			"2,mars2,4,16,t1,2,-,-,-,-,-,-,-,-",
+		}))
+	})
+})
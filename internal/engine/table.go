@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/ymatrix-data/mxbench/internal/engine/metadata"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+// TableConfig is one of the tables of a multi-table run given by --tables,
+// the fields not given are inherited from the global config,
+// except ddl-file-path and the columns mapped in it.
+type TableConfig struct {
+	TableName           string               `json:"table-name"`
+	TagNum              int64                `json:"tag-num"`
+	MetricsType         metadata.MetricsType `json:"metrics-type"`
+	TotalMetricsCount   int64                `json:"total-metrics-count"`
+	MetricsDescriptions string               `json:"metrics-descriptions"`
+	StorageType         string               `json:"storage-type"`
+	DDLFilePath         string               `json:"ddl-file-path"`
+	TSColumnName        string               `json:"ts-column-name"`
+	VINColumnName       string               `json:"vin-column-name"`
+	// Generator overrides the generator plugin config by the keys in config file,
+	// e.g. {"generator-batch-size": 2}
+	Generator map[string]interface{} `json:"generator"`
+}
+
+func parseTables(tables string) ([]TableConfig, error) {
+	if strings.TrimSpace(tables) == "" {
+		return nil, nil
+	}
+	var tableCfgs []TableConfig
+	dec := json.NewDecoder(strings.NewReader(tables))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&tableCfgs); err != nil {
+		return nil, mxerror.CommonErrorf("invalid tables: %v", err)
+	}
+	names := make(map[string]bool, len(tableCfgs))
+	for _, t := range tableCfgs {
+		if t.TableName == "" {
+			return nil, mxerror.CommonError("invalid tables: table-name is required for each table")
+		}
+		if t.DDLFilePath == "" && (t.TSColumnName != "" || t.VINColumnName != "") {
+			return nil, mxerror.CommonErrorf("invalid tables: ts-column-name and vin-column-name of table %s only map the columns of its ddl-file-path", t.TableName)
+		}
+		if names[t.TableName] {
+			return nil, mxerror.CommonErrorf("invalid tables: table %s is given more than once", t.TableName)
+		}
+		names[t.TableName] = true
+	}
+	return tableCfgs, nil
+}
+
+// GetTables returns the tables given by --tables, nil unless it is a multi-table run.
+func (cfg *GlobalConfig) GetTables() []TableConfig {
+	return cfg.tables
+}
+
+// ForTable derives the config of an engine running the given table of a multi-table run.
+func (cfg *Config) ForTable(t TableConfig) (*Config, error) {
+	c := *cfg
+	g := &c.GlobalCfg
+	g.TableName = t.TableName
+	if t.TagNum > 0 {
+		g.TagNum = t.TagNum
+	}
+	if t.MetricsType != "" {
+		g.MetricsType = t.MetricsType
+	}
+	if t.TotalMetricsCount > 0 {
+		g.TotalMetricsCount = t.TotalMetricsCount
+	}
+	if t.MetricsDescriptions != "" {
+		g.MetricsDescriptions = t.MetricsDescriptions
+	}
+	if t.StorageType != "" {
+		g.StorageType = t.StorageType
+	}
+	g.DDLFilePath = t.DDLFilePath
+	g.TSColumnName, g.VINColumnName = t.TSColumnName, t.VINColumnName
+	g.Workspace = filepath.Join(cfg.GlobalCfg.Workspace, t.TableName)
+	g.Tables, g.tables = "", nil
+	// tables of the same config generate different data
+	g.seed = util.DeriveSeed(cfg.GlobalCfg.GetSeed(), t.TableName)
+	// metrics of all the tables are served by the group
+	g.MetricsAddr = ""
+
+	pluginConfig, err := copyPluginConfig(cfg.GeneratorCfg.PluginConfig, t.Generator)
+	if err != nil {
+		return nil, mxerror.CommonErrorf("invalid generator config of table %s: %v", t.TableName, err)
+	}
+	c.GeneratorCfg.PluginConfig = pluginConfig
+	c.GeneratorCfg.GlobalConfig = g
+	// the plugins of the tables run concurrently, each on a config of its own
+	if c.BenchmarkCfg.PluginConfig, err = copyPluginConfig(cfg.BenchmarkCfg.PluginConfig, nil); err != nil {
+		return nil, mxerror.CommonErrorf("invalid benchmark config of table %s: %v", t.TableName, err)
+	}
+	if c.WriterCfg.PluginConfig, err = copyPluginConfig(cfg.WriterCfg.PluginConfig, nil); err != nil {
+		return nil, mxerror.CommonErrorf("invalid writer config of table %s: %v", t.TableName, err)
+	}
+	return &c, nil
+}
+
+// copyPluginConfig returns a copy of the plugin config struct,
+// with the values overridden by the keys of its mapstructure tags.
+func copyPluginConfig(pluginConfig interface{}, overrides map[string]interface{}) (interface{}, error) {
+	v := reflect.ValueOf(pluginConfig)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		if len(overrides) > 0 {
+			return nil, fmt.Errorf("the plugin has no config")
+		}
+		return pluginConfig, nil
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	if len(overrides) == 0 {
+		return c.Interface(), nil
+	}
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           c.Interface(),
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = dec.Decode(overrides); err != nil {
+		return nil, err
+	}
+	return c.Interface(), nil
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/util"
+)
+
+type fakeGeneratorConfig struct {
+	BatchSize  int    `mapstructure:"generator-batch-size"`
+	Randomness string `mapstructure:"generator-randomness"`
+
+	templateSize int64
+}
+
+type fakePluginConfig struct {
+	Parallel int `mapstructure:"parallel"`
+
+	done bool
+}
+
+var _ = Describe("Multi-table config", func() {
+	It("should parse tables", func() {
+		tables, err := parseTables(`[{"table-name": "t1", "tag-num": 10}, {"table-name": "t2", "generator": {"generator-batch-size": 2}}]`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tables).To(HaveLen(2))
+		Expect(tables[0].TagNum).To(Equal(int64(10)))
+		Expect(tables[1].Generator).To(HaveKeyWithValue("generator-batch-size", 2.0))
+
+		tables, err = parseTables(" ")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tables).To(BeNil())
+	})
+
+	It("should reject invalid tables", func() {
+		for _, tables := range []string{
+			`{"table-name": "t1"}`,
+			`[{"tag-num": 10}]`,
+			`[{"table-name": "t1"}, {"table-name": "t1"}]`,
+			`[{"table-name": "t1", "tag-number": 10}]`,
+			`[{"table-name": "t1", "ts-column-name": "collected_at"}]`,
+		} {
+			_, err := parseTables(tables)
+			Expect(err).To(HaveOccurred(), tables)
+		}
+	})
+
+	It("should derive the config of a table", func() {
+		cfg := &Config{}
+		cfg.GlobalCfg = GlobalConfig{
+			SchemaName:  "public",
+			TableName:   "t0",
+			TagNum:      100,
+			MetricsType: "float8",
+			StorageType: "mars3",
+			Workspace:   "/tmp/mxbench",
+			MetricsAddr: ":9187",
+			Seed:        42,
+		}
+		generatorCfg := &fakeGeneratorConfig{BatchSize: 1, Randomness: "OFF", templateSize: 3}
+		cfg.GeneratorCfg.PluginConfig = generatorCfg
+		cfg.GeneratorCfg.GlobalConfig = &cfg.GlobalCfg
+
+		tableCfg, err := cfg.ForTable(TableConfig{
+			TableName:     "t1",
+			TagNum:        10,
+			StorageType:   "heap",
+			DDLFilePath:   "/tmp/t1.sql",
+			VINColumnName: "device_id",
+			Generator:     map[string]interface{}{"generator-batch-size": 2.0},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tableCfg.GlobalCfg.TableName).To(Equal("t1"))
+		Expect(tableCfg.GlobalCfg.TagNum).To(Equal(int64(10)))
+		Expect(tableCfg.GlobalCfg.MetricsType).To(Equal("float8"))
+		Expect(tableCfg.GlobalCfg.StorageType).To(Equal("heap"))
+		Expect(tableCfg.GlobalCfg.NewMetadataConfig().ColumnNameVIN).To(Equal("device_id"))
+		Expect(tableCfg.GlobalCfg.Workspace).To(Equal("/tmp/mxbench/t1"))
+		Expect(tableCfg.GlobalCfg.MetricsAddr).To(BeEmpty())
+		Expect(tableCfg.GlobalCfg.GetSeed()).To(Equal(util.DeriveSeed(42, "t1")))
+		Expect(tableCfg.GeneratorCfg.GlobalConfig).To(BeIdenticalTo(&tableCfg.GlobalCfg))
+		Expect(tableCfg.GeneratorCfg.PluginConfig).To(Equal(&fakeGeneratorConfig{BatchSize: 2, Randomness: "OFF", templateSize: 3}))
+
+		// the global config is left untouched
+		Expect(cfg.GlobalCfg.TableName).To(Equal("t0"))
+		Expect(generatorCfg.BatchSize).To(Equal(1))
+	})
+
+	It("should derive the plugin configs of each table apart", func() {
+		cfg := &Config{}
+		cfg.GeneratorCfg.PluginConfig = &fakeGeneratorConfig{BatchSize: 1}
+		cfg.BenchmarkCfg.PluginConfig = &fakePluginConfig{Parallel: 8}
+		cfg.WriterCfg.PluginConfig = &fakePluginConfig{Parallel: 4}
+
+		t1, err := cfg.ForTable(TableConfig{TableName: "t1"})
+		Expect(err).NotTo(HaveOccurred())
+		t2, err := cfg.ForTable(TableConfig{TableName: "t2"})
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, pluginConfigs := range [][]interface{}{
+			{cfg.GeneratorCfg.PluginConfig, t1.GeneratorCfg.PluginConfig, t2.GeneratorCfg.PluginConfig},
+			{cfg.BenchmarkCfg.PluginConfig, t1.BenchmarkCfg.PluginConfig, t2.BenchmarkCfg.PluginConfig},
+			{cfg.WriterCfg.PluginConfig, t1.WriterCfg.PluginConfig, t2.WriterCfg.PluginConfig},
+		} {
+			Expect(pluginConfigs[1]).To(Equal(pluginConfigs[0]))
+			Expect(pluginConfigs[2]).To(Equal(pluginConfigs[0]))
+			Expect(pluginConfigs[1]).NotTo(BeIdenticalTo(pluginConfigs[0]))
+			Expect(pluginConfigs[2]).NotTo(BeIdenticalTo(pluginConfigs[0]))
+			Expect(pluginConfigs[2]).NotTo(BeIdenticalTo(pluginConfigs[1]))
+		}
+
+		// a plugin of a table updating its config leaves the other tables untouched
+		t1.BenchmarkCfg.PluginConfig.(*fakePluginConfig).done = true
+		Expect(t2.BenchmarkCfg.PluginConfig.(*fakePluginConfig).done).To(BeFalse())
+	})
+
+	It("should reject unknown generator options", func() {
+		cfg := &Config{}
+		cfg.GeneratorCfg.PluginConfig = &fakeGeneratorConfig{}
+		_, err := cfg.ForTable(TableConfig{TableName: "t1", Generator: map[string]interface{}{"generator-batch": 2}})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,273 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+const _THRESHOLD_SUBJECT_WRITER = "writer"
+
+type ThresholdsConfig struct {
+	Thresholds []string `mapstructure:"thresholds"`
+
+	thresholds []*threshold
+}
+
+func (cfg *Config) ThresholdsFlagSet() *pflag.FlagSet {
+	set := pflag.NewFlagSet("thresholds", pflag.ContinueOnError)
+	set.StringSliceVar(&cfg.ThresholdsCfg.Thresholds, "thresholds", nil,
+		"limits of the results, in the form of \"<query name or writer> <metric> <op> <value> [@<parallel>]\".\n"+
+			"Metrics of queries: tps, avg, stddev, min, max, p25, p50, p75, p90, p95, p99, p99.9, the values of latencies are durations.\n"+
+			"Metrics of writer: rows-per-second, bytes-per-second, rows, bytes, compress-ratio.\n"+
+			"Ops: <, <=, >, >=. mxbench exits with code 3 if any is violated,\n"+
+			"e.g. \"SINGLE_TAG_LATEST_QUERY p95 < 20ms @8\", \"writer rows-per-second > 1000000\"")
+	set.SortFlags = false
+	return set
+}
+
+func (cfg *ThresholdsConfig) DoAfterInit() error {
+	cfg.thresholds = make([]*threshold, 0, len(cfg.Thresholds))
+	for _, expr := range cfg.Thresholds {
+		t, err := parseThreshold(expr)
+		if err != nil {
+			return err
+		}
+		cfg.thresholds = append(cfg.thresholds, t)
+	}
+	return nil
+}
+
+type threshold struct {
+	expr     string
+	subject  string
+	metric   string
+	op       string
+	limit    float64
+	parallel int
+}
+
+// ThresholdResult is the result of checking a threshold,
+// a threshold of a query without parallel is checked against each parallel.
+type ThresholdResult struct {
+	Threshold string `json:"threshold"`
+	Parallel  int    `json:"parallel,omitempty"`
+	Actual    string `json:"actual"`
+	Passed    bool   `json:"passed"`
+}
+
+type ThresholdReport struct {
+	Passed  bool              `json:"passed"`
+	Results []ThresholdResult `json:"results"`
+}
+
+func isLatencyMetric(metric string) bool {
+	switch metric {
+	case "avg", "stddev", "min", "max", "p25", "p50", "p75", "p90", "p95", "p99", "p99.9":
+		return true
+	}
+	return false
+}
+
+func isWriterMetric(metric string) bool {
+	switch metric {
+	case "rows-per-second", "bytes-per-second", "rows", "bytes", "compress-ratio":
+		return true
+	}
+	return false
+}
+
+func parseThreshold(expr string) (*threshold, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 4 && len(fields) != 5 {
+		return nil, mxerror.CommonErrorf("invalid threshold %q, should be \"<query name or writer> <metric> <op> <value> [@<parallel>]\"", expr)
+	}
+	t := &threshold{expr: expr, subject: fields[0], metric: strings.ToLower(fields[1]), op: fields[2]}
+
+	switch t.op {
+	case "<", "<=", ">", ">=":
+	default:
+		return nil, mxerror.CommonErrorf("invalid threshold %q, op should be one of <, <=, >, >=, got %s", expr, t.op)
+	}
+
+	var err error
+	switch {
+	case t.subject == _THRESHOLD_SUBJECT_WRITER:
+		if !isWriterMetric(t.metric) {
+			return nil, mxerror.CommonErrorf("invalid threshold %q, unknown metric of writer: %s", expr, t.metric)
+		}
+		t.limit, err = strconv.ParseFloat(fields[3], 64)
+	case isLatencyMetric(t.metric):
+		var d time.Duration
+		d, err = time.ParseDuration(fields[3])
+		t.limit = float64(d)
+	case t.metric == "tps":
+		t.limit, err = strconv.ParseFloat(fields[3], 64)
+	default:
+		return nil, mxerror.CommonErrorf("invalid threshold %q, unknown metric of query: %s", expr, t.metric)
+	}
+	if err != nil {
+		return nil, mxerror.CommonErrorf("invalid threshold %q, bad value %s: %v", expr, fields[3], err)
+	}
+
+	if len(fields) == 5 {
+		if t.subject == _THRESHOLD_SUBJECT_WRITER {
+			return nil, mxerror.CommonErrorf("invalid threshold %q, parallel is only for queries", expr)
+		}
+		t.parallel, err = strconv.Atoi(strings.TrimPrefix(fields[4], "@"))
+		if !strings.HasPrefix(fields[4], "@") || err != nil || t.parallel <= 0 {
+			return nil, mxerror.CommonErrorf("invalid threshold %q, parallel should be like @8, got %s", expr, fields[4])
+		}
+	}
+	return t, nil
+}
+
+func (t *threshold) pass(actual float64) bool {
+	switch t.op {
+	case "<":
+		return actual < t.limit
+	case "<=":
+		return actual <= t.limit
+	case ">":
+		return actual > t.limit
+	default:
+		return actual >= t.limit
+	}
+}
+
+func (t *threshold) formatValue(v float64) string {
+	if isLatencyMetric(t.metric) {
+		return time.Duration(v).String()
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (t *threshold) checkWriter(summary *WriterSummary) ThresholdResult {
+	if summary == nil {
+		return ThresholdResult{Threshold: t.expr, Actual: "no writer result"}
+	}
+	var actual float64
+	seconds := summary.StopAt.Sub(summary.StartAt).Seconds()
+	switch t.metric {
+	case "rows-per-second":
+		if seconds > 0 {
+			actual = float64(summary.Lines) / seconds
+		}
+	case "bytes-per-second":
+		if seconds > 0 {
+			actual = float64(summary.Bytes) / seconds
+		}
+	case "rows":
+		actual = float64(summary.Lines)
+	case "bytes":
+		actual = float64(summary.Bytes)
+	case "compress-ratio":
+		actual = summary.CompressRatio
+	}
+	return ThresholdResult{Threshold: t.expr, Actual: fmt.Sprintf("%.2f", actual), Passed: t.pass(actual)}
+}
+
+func (t *threshold) checkQueries(execBenchStats []*ExecBenchStat) []ThresholdResult {
+	var results []ThresholdResult
+	for _, ebs := range execBenchStats {
+		if ebs.query == nil || ebs.query.GetName() != t.subject {
+			continue
+		}
+		if t.parallel > 0 && ebs.opt.Parallel != t.parallel {
+			continue
+		}
+		if ebs.Histogram.Count() == 0 {
+			continue
+		}
+		ebs.complete()
+		var actual time.Duration
+		switch t.metric {
+		case "tps":
+			tps := float64(ebs.TPS)
+			results = append(results, ThresholdResult{Threshold: t.expr, Parallel: ebs.opt.Parallel,
+				Actual: t.formatValue(tps), Passed: t.pass(tps)})
+			continue
+		case "avg":
+			actual = ebs.AvgLatency
+		case "stddev":
+			actual = ebs.StdDevLatency
+		case "min":
+			actual = ebs.MinLatency
+		case "max":
+			actual = ebs.MaxLatency
+		case "p25":
+			actual = ebs.P25Latency
+		case "p50":
+			actual = ebs.P50Latency
+		case "p75":
+			actual = ebs.P75Latency
+		case "p90":
+			actual = ebs.P90Latency
+		case "p95":
+			actual = ebs.P95Latency
+		case "p99":
+			actual = ebs.P99Latency
+		case "p99.9":
+			actual = ebs.P999Latency
+		}
+		results = append(results, ThresholdResult{Threshold: t.expr, Parallel: ebs.opt.Parallel,
+			Actual: t.formatValue(float64(actual)), Passed: t.pass(float64(actual))})
+	}
+	if len(results) == 0 {
+		// a regression gate should not pass by a query not executed at all
+		return []ThresholdResult{{Threshold: t.expr, Parallel: t.parallel, Actual: "not executed"}}
+	}
+	return results
+}
+
+func checkThresholds(thresholds []*threshold, writerSummary *WriterSummary, execBenchStats []*ExecBenchStat) *ThresholdReport {
+	report := &ThresholdReport{Passed: true}
+	for _, t := range thresholds {
+		var results []ThresholdResult
+		if t.subject == _THRESHOLD_SUBJECT_WRITER {
+			results = []ThresholdResult{t.checkWriter(writerSummary)}
+		} else {
+			results = t.checkQueries(execBenchStats)
+		}
+		for _, r := range results {
+			report.Passed = report.Passed && r.Passed
+		}
+		report.Results = append(report.Results, results...)
+	}
+	return report
+}
+
+func (report *ThresholdReport) violations() int {
+	var n int
+	for _, r := range report.Results {
+		if !r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+func (report *ThresholdReport) GetSummary() string {
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+	tbl.AppendHeader(table.Row{"Threshold", "Parallel", "Actual", "Result"})
+	for _, r := range report.Results {
+		parallel := ""
+		if r.Parallel > 0 {
+			parallel = strconv.Itoa(r.Parallel)
+		}
+		result := "PASS"
+		if !r.Passed {
+			result = "FAIL"
+		}
+		tbl.AppendRow(table.Row{r.Threshold, parallel, r.Actual, result})
+	}
+	tbl.SetTitle("Thresholds")
+	return tbl.Render()
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeQuery string
+
+func (q fakeQuery) GetSQL() string  { return "SELECT 1" }
+func (q fakeQuery) GetName() string { return string(q) }
+
+func newFakeExecBenchStat(name string, parallel int, latencies ...time.Duration) *ExecBenchStat {
+	ebs := NewExecBenchStat(ExecBenchOption{Parallel: parallel}, fakeQuery(name))
+	for _, l := range latencies {
+		ebs.addLatency(l)
+	}
+	ebs.TimeElapsed = int64(time.Second)
+	return ebs
+}
+
+var _ = Describe("Thresholds", func() {
+	It("should parse thresholds", func() {
+		t, err := parseThreshold("SINGLE_TAG_LATEST_QUERY p95 < 20ms @8")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.subject).To(Equal("SINGLE_TAG_LATEST_QUERY"))
+		Expect(t.metric).To(Equal("p95"))
+		Expect(t.limit).To(Equal(float64(20 * time.Millisecond)))
+		Expect(t.parallel).To(Equal(8))
+
+		t, err = parseThreshold("writer compress-ratio > 5")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.limit).To(Equal(5.0))
+	})
+
+	It("should reject invalid thresholds", func() {
+		for _, expr := range []string{
+			"Q p95 < 20ms @8 extra",
+			"Q p95 != 20ms",
+			"Q p95 < 20",
+			"Q p42 < 20ms",
+			"Q tps > 10 8",
+			"writer p95 < 20ms",
+			"writer rows > 10 @8",
+		} {
+			_, err := parseThreshold(expr)
+			Expect(err).To(HaveOccurred(), expr)
+		}
+	})
+
+	It("should check queries of the given parallel only", func() {
+		fast, err := parseThreshold("Q p95 < 20ms @8")
+		Expect(err).NotTo(HaveOccurred())
+		report := checkThresholds([]*threshold{fast}, nil, []*ExecBenchStat{
+			newFakeExecBenchStat("Q", 1, 50*time.Millisecond),
+			newFakeExecBenchStat("Q", 8, 10*time.Millisecond),
+		})
+		Expect(report.Passed).To(BeTrue())
+		Expect(report.Results).To(HaveLen(1))
+		Expect(report.Results[0].Parallel).To(Equal(8))
+	})
+
+	It("should check queries of every parallel without a parallel given", func() {
+		fast, err := parseThreshold("Q p95 < 20ms")
+		Expect(err).NotTo(HaveOccurred())
+		report := checkThresholds([]*threshold{fast}, nil, []*ExecBenchStat{
+			newFakeExecBenchStat("Q", 1, 50*time.Millisecond),
+			newFakeExecBenchStat("Q", 8, 10*time.Millisecond),
+		})
+		Expect(report.Passed).To(BeFalse())
+		Expect(report.violations()).To(Equal(1))
+	})
+
+	It("should fail a query not executed", func() {
+		t, err := parseThreshold("Q tps > 1")
+		Expect(err).NotTo(HaveOccurred())
+		report := checkThresholds([]*threshold{t}, nil, []*ExecBenchStat{newFakeExecBenchStat("P", 1, time.Millisecond)})
+		Expect(report.Passed).To(BeFalse())
+		Expect(report.Results[0].Actual).To(Equal("not executed"))
+	})
+
+	It("should check the writer", func() {
+		rate, err := parseThreshold("writer rows-per-second > 1000000")
+		Expect(err).NotTo(HaveOccurred())
+		ratio, err := parseThreshold("writer compress-ratio > 5")
+		Expect(err).NotTo(HaveOccurred())
+		start := time.Now()
+		report := checkThresholds([]*threshold{rate, ratio}, &WriterSummary{
+			StartAt:       start,
+			StopAt:        start.Add(10 * time.Second),
+			Lines:         20000000,
+			CompressRatio: 3,
+		}, nil)
+		Expect(report.Results[0].Passed).To(BeTrue())
+		Expect(report.Results[1].Passed).To(BeFalse())
+	})
+})
@@ -1,5 +1,11 @@
 package engine
 
+import (
+	"io"
+	"sync"
+	"time"
+)
+
 type WriteFunc func([]byte, int64, int64) error
 
 type IWriter interface {
@@ -18,4 +24,66 @@ type WriterConfig struct {
 
 	// Plugin specific config
 	PluginConfig interface{}
+
+	// Shared is shared by the writers of all the tables of a multi-table run,
+	// created for each run of the group, nil otherwise.
+	Shared *WriterShared
+}
+
+// WriterShared holds what the writers of all the tables of a multi-table run share,
+// e.g. the mxgate writing into all the tables.
+type WriterShared struct {
+	mu     sync.Mutex
+	value  io.Closer
+	closed bool
+}
+
+// Load returns the value shared by the writers, created by newValue of the writer loading it first.
+// The value created after the run has failed is closed at once.
+func (s *WriterShared) Load(newValue func() io.Closer) io.Closer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.value == nil {
+		s.value = newValue()
+		if s.closed {
+			_ = s.value.Close()
+		}
+	}
+	return s.value
+}
+
+// Close closes the value shared, if any, so that no writer waits for the others any longer.
+// It is called once the run ends, and once any of the tables fails.
+func (s *WriterShared) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.value == nil {
+		return nil
+	}
+	return s.value.Close()
+}
+
+// DBLessWriter is implemented by the writers able to write without the database, e.g. into a fake of mxgate,
+// with which the steps in the database are skipped, and the DDL, GUCs and queries are dumped instead.
+type DBLessWriter interface {
+	WritesWithoutDB() bool
+}
+
+// WriterSummary is what all the writers have written in the end.
+type WriterSummary struct {
+	StartAt       time.Time `json:"start-at"`
+	StopAt        time.Time `json:"stop-at"`
+	Bytes         int64     `json:"bytes"`
+	Lines         int64     `json:"lines"`
+	CompressRatio float64   `json:"compress-ratio"`
+}
+
+// WriterStat is the Stat of a writer able to summarize what it has written.
+type WriterStat interface {
+	Stat
+	GetWriterSummary() WriterSummary
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/ymatrix-data/mxbench/internal/engine"
+	cp "github.com/ymatrix-data/mxbench/internal/engine/writer/copy"
 	"github.com/ymatrix-data/mxbench/internal/engine/writer/http"
+	"github.com/ymatrix-data/mxbench/internal/engine/writer/insert"
 	ni "github.com/ymatrix-data/mxbench/internal/engine/writer/nil"
 	"github.com/ymatrix-data/mxbench/internal/engine/writer/stdin"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
@@ -20,6 +22,10 @@ func GetWriter(cfg engine.WriterConfig) func(engine.WriterConfig) engine.IWriter
 		return http.NewWriter
 	case "stdin":
 		return stdin.NewWriter
+	case "copy":
+		return cp.NewWriter
+	case "insert":
+		return insert.NewWriter
 	case "nil":
 		return ni.NewWriter
 	}
@@ -37,6 +43,10 @@ func GetDefaultFlags(cfg engine.WriterConfig) (*pflag.FlagSet, interface{}) {
 		pluginWriter = new(http.Writer)
 	case "stdin":
 		pluginWriter = new(stdin.Writer)
+	case "copy":
+		pluginWriter = new(cp.Writer)
+	case "insert":
+		pluginWriter = new(insert.Writer)
 	case "nil":
 		pluginWriter = new(ni.Writer)
 	}
@@ -55,6 +65,10 @@ func RenderPluginConfig(cfgViper *viper.Viper, cfg engine.WriterConfig, renew bo
 			result = new(http.Writer).CreatePluginConfig()
 		case "stdin":
 			result = new(stdin.Writer).CreatePluginConfig()
+		case "copy":
+			result = new(cp.Writer).CreatePluginConfig()
+		case "insert":
+			result = new(insert.Writer).CreatePluginConfig()
 		case "nil":
 			result = new(ni.Writer).CreatePluginConfig()
 		}
@@ -0,0 +1,261 @@
+package copy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+const (
+	_BATCH_SIZE = 4 * 1024 * 1024
+	_BATCH_RED  = _BATCH_SIZE / 8 * 7
+)
+
+type Config struct {
+	Parallel int `mapstructure:"writer-parallel"`
+
+	ProgressFormat           string `mapstructure:"writer-progress-format"`
+	ProgressIncludeTableSize bool   `mapstructure:"writer-progress-include-table-size"`
+	ProgressWithTimezone     bool   `mapstructure:"writer-progress-with-timezone"`
+}
+
+func (c *Config) getProgressTimeLayout() string {
+	if c.ProgressWithTimezone {
+		return util.TIME_WITH_TZ_FMT
+	}
+	return util.TIME_FMT
+}
+
+// Writer loads the generated batches into the target table
+// with "COPY ... FROM STDIN", no mxgate is involved.
+type Writer struct {
+	cCfg *Config
+
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	finCh      chan error
+
+	stat *Stat
+
+	batchCh  chan *sendAndFeed
+	globalWG sync.WaitGroup
+
+	copySQL string
+
+	accCopy, nCopy, maxCopyTime int64
+}
+
+type sendAndFeed struct {
+	msg  []byte
+	feed chan struct{}
+}
+
+func NewWriter(cfg engine.WriterConfig) engine.IWriter {
+	cCfg := cfg.PluginConfig.(*Config)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	return &Writer{
+		cCfg:       cCfg,
+		ctx:        ctx,
+		cancelFunc: cancelFunc,
+		finCh:      make(chan error, cCfg.Parallel),
+		batchCh:    make(chan *sendAndFeed, 100),
+		stat:       &Stat{},
+	}
+}
+
+func newStat(volumeDesc engine.VolumeDesc, cfg *Config) *Stat {
+	return &Stat{volumeDesc: volumeDesc, config: cfg}
+}
+
+func (w *Writer) Start(cfg engine.Config, volumeDesc engine.VolumeDesc) (<-chan error, error) {
+	if w.cCfg.Parallel <= 0 {
+		return nil, mxerror.CommonErrorf("writer-parallel of copy writer should be positive, got %d", w.cCfg.Parallel)
+	}
+
+	w.stat = newStat(volumeDesc, w.cCfg)
+	w.copySQL = buildCopyStatement(cfg.GlobalCfg.SchemaName, cfg.GlobalCfg.TableName)
+
+	// Establish all the connections ahead,
+	// so that a bad connection string fails the run instantly.
+	conns := make([]*pgx.Conn, 0, w.cCfg.Parallel)
+	connStr := cfg.DB.GetConnStr()
+	for i := 0; i < w.cCfg.Parallel; i++ {
+		conn, err := pgx.Connect(w.ctx, connStr)
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close(context.Background())
+			}
+			return nil, mxerror.CommonErrorf("copy writer failed to connect to database: %v", err)
+		}
+		conns = append(conns, conn)
+	}
+
+	w.globalWG.Add(1)
+	w.stat.startAt = time.Now()
+	go func() {
+		defer func() {
+			w.globalWG.Done()
+			w.stat.stopAt = time.Now()
+			// Notify copy writer finished
+			close(w.finCh)
+		}()
+		w.send(conns)
+	}()
+
+	return w.finCh, nil
+}
+
+// buildCopyStatement returns the COPY loading the generated lines, delimited by util.DELIMITER, into the table.
+func buildCopyStatement(schemaName, tableName string) string {
+	return fmt.Sprintf("COPY %s.%s FROM STDIN WITH (FORMAT csv, DELIMITER '%s')", schemaName, tableName, util.DELIMITER)
+}
+
+func (w *Writer) send(conns []*pgx.Conn) {
+	var wg sync.WaitGroup
+
+	wg.Add(len(conns))
+
+	for _, conn := range conns {
+		go func(conn *pgx.Conn) {
+			var batchBuf = bytes.NewBuffer(make([]byte, 0, _BATCH_SIZE))
+
+			defer func() {
+				_ = conn.Close(context.Background())
+				wg.Done()
+			}()
+
+			for {
+				select {
+				case <-w.ctx.Done():
+					return
+
+				case body, ok := <-w.batchCh:
+					if !ok {
+						if batchBuf.Len() > 0 {
+							if err := w.copy(conn, batchBuf); err != nil {
+								w.fail(err)
+							}
+						}
+						return
+					}
+
+					_, err := batchBuf.Write(body.msg)
+					close(body.feed)
+					if err != nil {
+						w.fail(err)
+						return
+					}
+
+					if batchBuf.Len() >= _BATCH_RED {
+						if err := w.copy(conn, batchBuf); err != nil {
+							w.fail(err)
+							return
+						}
+						batchBuf.Reset()
+					}
+				}
+			}
+		}(conn)
+	}
+	wg.Wait()
+}
+
+// copy runs a single COPY for the buffered batch.
+func (w *Writer) copy(conn *pgx.Conn, batchBuf *bytes.Buffer) error {
+	size := int64(batchBuf.Len())
+	ts := time.Now()
+	_, err := conn.PgConn().CopyFrom(w.ctx, batchBuf, w.copySQL)
+	dur := time.Since(ts).Nanoseconds()
+
+	atomic.AddInt64(&w.nCopy, 1)
+	atomic.AddInt64(&w.accCopy, dur)
+	for {
+		maxCopyTime := atomic.LoadInt64(&w.maxCopyTime)
+		if dur <= maxCopyTime || atomic.CompareAndSwapInt64(&w.maxCopyTime, maxCopyTime, dur) {
+			break
+		}
+	}
+	if err != nil {
+		return err
+	}
+	atomic.AddInt64(&w.stat.sizeToDB, size)
+	return nil
+}
+
+// fail reports the error to the engine and stops all the other connections,
+// so that Write does not block on a writer that has gone.
+func (w *Writer) fail(err error) {
+	log.Error("copy writer failed: %v", err)
+	w.finCh <- err
+	w.cancelFunc()
+}
+
+func (w *Writer) Stop() error {
+	w.cancelFunc()
+	w.globalWG.Wait()
+
+	log.Verbose("Parallel: %d", w.cCfg.Parallel)
+	log.Verbose("Acc Copy: %s", time.Duration(atomic.LoadInt64(&w.accCopy)))
+	log.Verbose("Num Copy: %d", atomic.LoadInt64(&w.nCopy))
+	log.Verbose("Slowest Copy: %s", time.Duration(atomic.LoadInt64(&w.maxCopyTime)))
+
+	return nil
+}
+
+func (w *Writer) Write(msg []byte, msgCnt, msgSize int64) error {
+	ch := make(chan struct{})
+	select {
+	case <-w.ctx.Done():
+		return mxerror.CommonError("copy writer has stopped")
+	case w.batchCh <- &sendAndFeed{msg: msg, feed: ch}:
+	}
+
+	select {
+	case <-w.ctx.Done():
+		return mxerror.CommonError("copy writer has stopped")
+	case <-ch:
+	}
+
+	atomic.AddInt64(&w.stat.size, msgSize)
+	atomic.AddInt64(&w.stat.count, msgCnt)
+	return nil
+}
+
+func (w *Writer) WriteEOF() error {
+	close(w.batchCh)
+	return nil
+}
+
+func (w *Writer) GetStat() engine.Stat {
+	return w.stat
+}
+
+func (w *Writer) CreatePluginConfig() interface{} {
+	return &Config{}
+}
+
+func (w *Writer) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
+	cCfg := &Config{}
+	p := pflag.NewFlagSet("writer.copy", pflag.ContinueOnError)
+	p.IntVar(&cCfg.Parallel, "writer-parallel", 8, "The number of parallel COPY connections")
+
+	p.StringVar(&cCfg.ProgressFormat, "writer-progress-format", "list", "progress format. support \"list\", \"json\"")
+	p.BoolVar(&cCfg.ProgressIncludeTableSize, "writer-progress-include-table-size", false, "whether progress include table size")
+	p.BoolVar(&cCfg.ProgressWithTimezone, "writer-progress-with-timezone", false, "whether print time with timezone")
+	return p, cCfg
+}
+
+func (w *Writer) IsNil() bool {
+	return w == nil
+}
@@ -0,0 +1,13 @@
+package copy
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCopy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "COPY Writer Suite")
+}
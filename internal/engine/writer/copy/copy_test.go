@@ -0,0 +1,86 @@
+package copy
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+var _ = Describe("COPY writer", func() {
+	It("should build the COPY statement of the table", func() {
+		Expect(buildCopyStatement("public", "t1")).To(Equal("COPY public.t1 FROM STDIN WITH (FORMAT csv, DELIMITER '|')"))
+	})
+
+	It("should reject a non-positive parallel", func() {
+		w := NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 0}})
+		_, err := w.Start(engine.Config{}, engine.VolumeDesc{})
+		Expect(err).To(MatchError(ContainSubstring("writer-parallel of copy writer should be positive")))
+	})
+
+	It("should count the lines written once they are taken into a batch", func() {
+		w := NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 1}}).(*Writer)
+		go func() {
+			for body := range w.batchCh {
+				close(body.feed)
+			}
+		}()
+		Expect(w.Write([]byte("1|a\n2|b\n"), 2, 8)).To(Succeed())
+		Expect(w.Write([]byte("3|c\n"), 1, 4)).To(Succeed())
+		Expect(w.WriteEOF()).To(Succeed())
+		Expect(w.stat.count).To(Equal(int64(3)))
+		Expect(w.stat.size).To(Equal(int64(12)))
+	})
+
+	It("should fail writing once it has stopped", func() {
+		w := NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 1}}).(*Writer)
+		w.cancelFunc()
+		Expect(w.Write([]byte("1|a\n"), 1, 4)).To(MatchError(ContainSubstring("copy writer has stopped")))
+	})
+})
+
+var _ = Describe("COPY writer stat", func() {
+	var s *Stat
+
+	BeforeEach(func() {
+		startAt := time.Date(2022, 4, 25, 9, 0, 0, 0, time.UTC)
+		s = newStat(engine.VolumeDesc{GetTableSizeFunc: func() (int64, error) { return 1000, nil }}, &Config{})
+		s.startAt, s.stopAt = startAt, startAt.Add(time.Minute)
+		s.size, s.sizeToDB, s.count = 1500, 2000, 100
+	})
+
+	It("should tell the compress ratio of the data copied to the table size", func() {
+		ratio, err := s.GetTableCompressRatio()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ratio).To(Equal(2.0))
+	})
+
+	It("should summarize the copy", func() {
+		Expect(s.GetFormattedSummary()).To(Equal("2022-04-25 09:00:00|2022-04-25 09:01:00|2000|100|2.000000"))
+		summary := s.GetSummary()
+		Expect(summary).To(ContainSubstring("Summary Report for COPY Writer"))
+		Expect(summary).To(ContainSubstring("2.0000 : 1"))
+
+		ws := s.GetWriterSummary()
+		Expect(ws.StartAt).To(Equal(s.startAt))
+		Expect(ws.StopAt).To(Equal(s.stopAt))
+		Expect(ws.Bytes).To(Equal(int64(2000)))
+		Expect(ws.Lines).To(Equal(int64(100)))
+		Expect(ws.CompressRatio).To(Equal(2.0))
+	})
+
+	It("should not summarize without the table", func() {
+		s.volumeDesc.GetTableSizeFunc = nil
+		Expect(s.GetSummary()).To(BeEmpty())
+		Expect(s.GetFormattedSummary()).To(BeEmpty())
+	})
+
+	It("should report the progress in json", func() {
+		s.config.ProgressFormat = "json"
+		s.stopAt = time.Time{}
+		Expect(strings.HasPrefix(s.GetProgress(), "{")).To(BeTrue())
+	})
+})
@@ -0,0 +1,21 @@
+package copy
+
+// WriterProgress shares its JSON keys with the mxgate based writers,
+// so that progress consumers need not tell the writers apart.
+type WriterProgress struct {
+	Start                   string  `json:"start"`
+	End                     string  `json:"end"`
+	Period                  string  `json:"period"`
+	CurrTotalRows           int64   `json:"currTotalRows"`
+	TotalRows               int64   `json:"totalRows"`
+	CurrPeriodRows          int64   `json:"currPeriodRows"`
+	CurrTotalBytes          int64   `json:"currTotalBytes"`
+	TotalBytes              int64   `json:"totalBytes"`
+	CurrPeriodBytes         int64   `json:"currPeriodBytes"`
+	WrittenMxgateTotal      int64   `json:"writtenMxGateTotal"`
+	CurrPeriodWrittenMxgate int64   `json:"currPeriodWrittenMxGate"`
+	TableSize               int64   `json:"tableSize"`
+	RateUnit                string  `json:"rateUnit,omitempty"`
+	TargetRate              float64 `json:"targetRate,omitempty"`
+	AchievedRate            float64 `json:"achievedRate,omitempty"`
+}
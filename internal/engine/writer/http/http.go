@@ -19,6 +19,7 @@ import (
 	"github.com/ymatrix-data/mxbench/internal/util"
 	"github.com/ymatrix-data/mxbench/internal/util/log"
 	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/fakegate"
 )
 
 const (
@@ -29,6 +30,9 @@ const (
 	_METHOD_POST = "POST"
 	_TEXT_PLAIN  = "text/plain"
 	_HTTP_PORT   = 8086
+
+	_BACKOFF_MIN = 100 * time.Millisecond
+	_BACKOFF_MAX = 5 * time.Second
 )
 
 var (
@@ -63,6 +67,11 @@ type Config struct {
 	ProgressIncludeTableSize bool   `mapstructure:"writer-progress-include-table-size"`
 	ProgressWithTimezone     bool   `mapstructure:"writer-progress-with-timezone"`
 	MxgateURL                string `mapstructure:"writer-mxgate-url"`
+
+	BackoffMaxRetries int `mapstructure:"writer-backoff-max-retries"`
+
+	FakeMxgate                  bool `mapstructure:"writer-fake-mxgate"`
+	FakeMxgateBackpressureEvery int  `mapstructure:"writer-fake-mxgate-backpressure-every"`
 }
 
 func (c *Config) getProgressTimeLayout() string {
@@ -84,6 +93,7 @@ type Writer struct {
 	gateOut io.Reader
 
 	url      string
+	addr     string
 	batchCh  chan *sendAndFeed
 	globalWG sync.WaitGroup
 
@@ -93,21 +103,33 @@ type Writer struct {
 func NewWriter(cfg engine.WriterConfig) engine.IWriter {
 	hCfg := cfg.PluginConfig.(*Config)
 	ctx, cancelFunc := context.WithCancel(context.Background())
-	var urlStr string
-	if hCfg.MxgateURL != "" {
-		urlStr = hCfg.MxgateURL
-	} else {
-		urlStr = fmt.Sprintf("http://127.0.0.1:%d", _HTTP_PORT)
-	}
-	return &Writer{
+	w := &Writer{
 		hCfg:       hCfg,
 		ctx:        ctx,
 		cancelFunc: cancelFunc,
 		finCh:      make(chan error, hCfg.Parallel),
 		batchCh:    make(chan *sendAndFeed, 100),
-		url:        urlStr,
 		stat:       &Stat{},
 	}
+	if hCfg.MxgateURL != "" {
+		w.setURL(hCfg.MxgateURL)
+	} else {
+		w.setURL(fmt.Sprintf("http://127.0.0.1:%d", _HTTP_PORT))
+	}
+	return w
+}
+
+// WritesWithoutDB tells the data is written into the fake of mxgate, instead of the database.
+func (w *Writer) WritesWithoutDB() bool {
+	return w.hCfg.FakeMxgate
+}
+
+func (w *Writer) setURL(url string) {
+	w.url = url
+	w.addr = strings.TrimPrefix(url, "http://")
+	if i := strings.Index(w.addr, "/"); i >= 0 {
+		w.addr = w.addr[:i]
+	}
 }
 
 func newStat(volumeDesc engine.VolumeDesc, cfg *Config) *Stat {
@@ -117,6 +139,9 @@ func newStat(volumeDesc engine.VolumeDesc, cfg *Config) *Stat {
 func (w *Writer) Start(cfg engine.Config, volumeDesc engine.VolumeDesc) (<-chan error, error) {
 	w.stat = newStat(volumeDesc, w.hCfg)
 	w.tableName = fmt.Sprintf("%s.%s", cfg.GlobalCfg.SchemaName, cfg.GlobalCfg.TableName)
+	if w.hCfg.FakeMxgate && w.hCfg.MxgateURL != "" {
+		return nil, mxerror.CommonError("writer-fake-mxgate conflicts with writer-mxgate-url")
+	}
 
 	var err error
 	var startWG sync.WaitGroup
@@ -139,109 +164,220 @@ func (w *Writer) Start(cfg engine.Config, volumeDesc engine.VolumeDesc) (<-chan
 			return
 		}
 
-		// TODO according tag num ... to decide the stream_prepared
-		tableIdentifier := fmt.Sprintf("%s.%s", cfg.GlobalCfg.SchemaName, cfg.GlobalCfg.TableName)
-		streamPrepared := w.hCfg.StreamPrepared
-		interval := w.hCfg.Interval
-
-		// TODO according tag num ... to decide the stream_prepared, interval etc.
-		if streamPrepared < 0 {
-			streamPrepared = 2
-		}
-		if interval < 0 {
-			interval = 250
-		}
-		useGzip := "no"
-		if w.hCfg.UseGzip {
-			useGzip = "yes"
-		}
-		fs := Flags{
-			"--source":          "http",
-			"--format":          "csv",
-			"--time-format":     "raw",
-			"--http-port":       _HTTP_PORT,
-			"--max-body-bytes":  _BATCH_SIZE * 2, // Avoid batchSize bigger than --max-body-bytes
-			"--interval":        interval,
-			"--stream-prepared": streamPrepared,
-			"--use-gzip":        useGzip,
-
-			"--db-database":    cfg.DB.Database,
-			"--db-master-host": cfg.DB.MasterHost,
-			"--db-master-port": cfg.DB.MasterPort,
-			"--db-user":        cfg.DB.User,
-			"--delimiter":      util.DELIMITER,
-			"--target":         tableIdentifier,
-
-			"--timing":                  "true",
-			"--metrics-sample-interval": 15,
-
-			// To benchmark generate speed
-			// "--writer":    "nil",
-			// "--transform": "nil",
+		if w.hCfg.FakeMxgate {
+			var fake *fakegate.Server
+			fake, err = fakegate.Start("127.0.0.1:0", fakegate.Options{BackpressureEvery: w.hCfg.FakeMxgateBackpressureEvery})
+			w.stat.startAt = time.Now()
+			startWG.Done()
+			if err != nil {
+				return
+			}
+			defer fake.Close()
+			log.Info("Writing %s into the fake mxgate at %s, instead of the database", w.tableName, fake.URL())
+			w.setURL(fake.URL())
+			w.send()
+			stats := fake.Stats()
+			log.Info("The fake mxgate accepted %d rows, %d bytes of %s in %d posts, %d backpressured",
+				stats.Rows, stats.Bytes, w.tableName, stats.Posts, stats.Backpressures)
+			return
 		}
 
-		var cmd *exec.Cmd
-		cmd, w.gateOut, err = util.StartMxgate(w.hCfg.mxgatePath, fs.ToStr())
-		// fmt.Println("mxgate:", cmd.String())
-		if err != nil {
+		if targets, shared := cfg.GlobalCfg.SharedTargets, cfg.WriterCfg.Shared; len(targets) > 1 && shared != nil {
+			// all the tables of a multi-table run are written through one mxgate
+			g := shared.Load(func() io.Closer { return newGate() }).(*gate)
+			defer g.release(len(targets))
+			err = g.acquire(func() (*exec.Cmd, error) {
+				return w.launchMxgate(cfg, strings.Join(targets, ","))
+			})
+			w.stat.startAt = time.Now()
 			startWG.Done()
+			if err != nil {
+				return
+			}
+			w.send()
 			return
 		}
 
-		func() {
-			var out string
-			var n int
-			b := make([]byte, 1024)
-			defer func() {
-				startWG.Done()
-				go func() {
-					// consume gate stdout to prevent hang
-					_, _ = io.ReadAll(w.gateOut)
-				}()
-			}()
-			for {
-				select {
-				case <-w.ctx.Done():
-					return
-				default:
-					time.Sleep(time.Second)
-					for {
-						n, err = w.gateOut.Read(b)
-						if n > 0 {
-							out += string(b)
-						}
-						if err == io.EOF {
-							err = mxerror.CommonError(out)
-							return
-						} else if err != nil {
-							log.Error("read error %s", err)
-							return
-						}
-						if n < len(b) {
-							break
-						}
-					}
-
-					if strings.Contains(out, "http listening on") {
-						w.stat.startAt = time.Now()
-						return
-					} else if strings.Contains(out, "exit status") {
-						err = mxerror.CommonError(out)
-						return
-					}
-				}
+		var cmd *exec.Cmd
+		cmd, err = w.launchMxgate(cfg, w.tableName)
+		w.stat.startAt = time.Now()
+		startWG.Done()
+		if err != nil {
+			if cmd != nil {
+				stopMxgate(cmd)
 			}
-		}()
+			return
+		}
 
 		// Send data to mxgate until completed
 		w.send()
-		_ = cmd.Process.Signal(syscall.SIGQUIT)
-		_ = cmd.Wait()
+		stopMxgate(cmd)
 	}()
 	startWG.Wait()
 	return w.finCh, err
 }
 
+// launchMxgate starts mxgate writing into the target tables, separated by comma,
+// and returns after it is listening.
+func (w *Writer) launchMxgate(cfg engine.Config, target string) (*exec.Cmd, error) {
+	// TODO according tag num ... to decide the stream_prepared
+	streamPrepared := w.hCfg.StreamPrepared
+	interval := w.hCfg.Interval
+
+	// TODO according tag num ... to decide the stream_prepared, interval etc.
+	if streamPrepared < 0 {
+		streamPrepared = 2
+	}
+	if interval < 0 {
+		interval = 250
+	}
+	useGzip := "no"
+	if w.hCfg.UseGzip {
+		useGzip = "yes"
+	}
+	fs := Flags{
+		"--source":          "http",
+		"--format":          "csv",
+		"--time-format":     "raw",
+		"--http-port":       _HTTP_PORT,
+		"--max-body-bytes":  _BATCH_SIZE * 2, // Avoid batchSize bigger than --max-body-bytes
+		"--interval":        interval,
+		"--stream-prepared": streamPrepared,
+		"--use-gzip":        useGzip,
+
+		"--db-database":    cfg.DB.Database,
+		"--db-master-host": cfg.DB.MasterHost,
+		"--db-master-port": cfg.DB.MasterPort,
+		"--db-user":        cfg.DB.User,
+		"--delimiter":      util.DELIMITER,
+		"--target":         target,
+
+		"--timing":                  "true",
+		"--metrics-sample-interval": 15,
+
+		// To benchmark generate speed
+		// "--writer":    "nil",
+		// "--transform": "nil",
+	}
+
+	cmd, gateOut, err := util.StartMxgate(w.hCfg.mxgatePath, fs.ToStr())
+	// fmt.Println("mxgate:", cmd.String())
+	if err != nil {
+		return nil, err
+	}
+	w.gateOut = gateOut
+
+	var out string
+	var n int
+	b := make([]byte, 1024)
+	defer func() {
+		go func() {
+			// consume gate stdout to prevent hang
+			_, _ = io.ReadAll(gateOut)
+		}()
+	}()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return cmd, nil
+		default:
+			time.Sleep(time.Second)
+			for {
+				n, err = gateOut.Read(b)
+				if n > 0 {
+					out += string(b)
+				}
+				if err == io.EOF {
+					return cmd, mxerror.CommonError(out)
+				} else if err != nil {
+					log.Error("read error %s", err)
+					return cmd, nil
+				}
+				if n < len(b) {
+					break
+				}
+			}
+
+			if strings.Contains(out, "http listening on") {
+				return cmd, nil
+			} else if strings.Contains(out, "exit status") {
+				return cmd, mxerror.CommonError(out)
+			}
+		}
+	}
+}
+
+func stopMxgate(cmd *exec.Cmd) {
+	_ = cmd.Process.Signal(syscall.SIGQUIT)
+	_ = cmd.Wait()
+}
+
+// gate is the mxgate shared by the http writers of all the tables in a multi-table run.
+// It is launched by the writer started first, and stopped after all of them have sent their data,
+// or once it is closed as any of the tables fails.
+type gate struct {
+	mu       sync.Mutex
+	launched bool
+	cmd      *exec.Cmd
+	err      error
+	released int
+	stopped  chan struct{}
+}
+
+var errGateClosed = mxerror.CommonError("the mxgate shared by the tables has been stopped")
+
+func newGate() *gate {
+	return &gate{stopped: make(chan struct{})}
+}
+
+func (g *gate) acquire(launch func() (*exec.Cmd, error)) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.stopped:
+		return errGateClosed
+	default:
+	}
+	if !g.launched {
+		g.launched = true
+		g.cmd, g.err = launch()
+	}
+	return g.err
+}
+
+// release returns after all the writers have released the gate and mxgate is stopped,
+// so that the data of each table has been flushed when its writer finishes.
+func (g *gate) release(writers int) {
+	g.mu.Lock()
+	g.released++
+	if g.released == writers {
+		g.stop()
+	}
+	g.mu.Unlock()
+	<-g.stopped
+}
+
+// Close stops mxgate, so that the writers released do not wait for the others any longer.
+func (g *gate) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stop()
+	return nil
+}
+
+// stop stops mxgate unless it has been stopped, with mu held.
+func (g *gate) stop() {
+	select {
+	case <-g.stopped:
+		return
+	default:
+	}
+	if g.cmd != nil {
+		stopMxgate(g.cmd)
+	}
+	close(g.stopped)
+}
+
 var gAccPost, gNPost, gAccSize, gMaxSize, gMaxPostTime int64
 var mu sync.Mutex
 
@@ -256,14 +392,8 @@ func (w *Writer) send() {
 			var nPost int
 			var batchBuf = bytes.NewBuffer(make([]byte, 0, _BATCH_SIZE))
 
-			var addr string
-			if w.hCfg.MxgateURL != "" {
-				addr = strings.TrimPrefix(w.hCfg.MxgateURL, "http://")
-			} else {
-				addr = fmt.Sprintf("127.0.0.1:%d", _HTTP_PORT)
-			}
 			c := &fasthttp.HostClient{
-				Addr: addr,
+				Addr: w.addr,
 			}
 
 			defer func() {
@@ -296,7 +426,7 @@ func (w *Writer) send() {
 							if size > maxPostSize {
 								maxPostSize = size
 							}
-							_, dur, err := w.post(c, batchBuf.Bytes())
+							dur, err := w.postWithBackoff(c, batchBuf.Bytes())
 							if dur > maxPostTime {
 								maxPostTime = dur
 							}
@@ -332,7 +462,7 @@ func (w *Writer) send() {
 						if size > maxPostSize {
 							maxPostSize = size
 						}
-						_, dur, err := w.post(c, batchBuf.Bytes())
+						dur, err := w.postWithBackoff(c, batchBuf.Bytes())
 						if dur > maxPostTime {
 							maxPostTime = dur
 						}
@@ -408,6 +538,10 @@ func (w *Writer) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	p.IntVar(&hCfg.Interval, "writer-interval", -1, "interval for mxgate")
 	p.StringVar(&hCfg.mxgatePath, "writer-mxgate-path", "", "path of mxgate")
 	p.StringVar(&hCfg.MxgateURL, "writer-mxgate-url", "", "http url of mxgate")
+	p.IntVar(&hCfg.BackoffMaxRetries, "writer-backoff-max-retries", 10, "the times to post again after backing off as mxgate asks for backpressure,\n"+
+		"before failing the run, 0 fails at once")
+	p.BoolVar(&hCfg.FakeMxgate, "writer-fake-mxgate", false, "write into an in-process fake of mxgate, which counts the data instead of writing it into the database")
+	p.IntVar(&hCfg.FakeMxgateBackpressureEvery, "writer-fake-mxgate-backpressure-every", 0, "the fake of mxgate backpressures every n-th post, 0 never")
 
 	p.StringVar(&hCfg.ProgressFormat, "writer-progress-format", "list", "progress format. support \"list\", \"json\"")
 	p.BoolVar(&hCfg.ProgressIncludeTableSize, "writer-progress-include-table-size", false, "whether progress include table size")
@@ -418,6 +552,31 @@ func (w *Writer) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
 	return p, hCfg
 }
 
+// postWithBackoff posts the body, and posts it again after backing off as long as mxgate asks for backpressure,
+// doubling the time to back off each time, up to writer-backoff-max-retries times.
+// It returns the time of the last post.
+func (w *Writer) postWithBackoff(c *fasthttp.HostClient, body []byte) (int64, error) {
+	backoff := _BACKOFF_MIN
+	for retries := 0; ; retries++ {
+		_, dur, err := w.post(c, body)
+		if err != errBackoff {
+			return dur, err
+		}
+		if retries >= w.hCfg.BackoffMaxRetries {
+			return dur, mxerror.CommonErrorf("%v, still after %d retries", err, retries)
+		}
+		atomic.AddInt64(&w.stat.backoffs, 1)
+		select {
+		case <-w.ctx.Done():
+			return dur, err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > _BACKOFF_MAX {
+			backoff = _BACKOFF_MAX
+		}
+	}
+}
+
 func (w *Writer) post(c *fasthttp.HostClient, body []byte) (int, int64, error) {
 	req := fasthttp.AcquireRequest()
 	resp := fasthttp.AcquireResponse()
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/valyala/fasthttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/pkg/fakegate"
+)
+
+var _ = Describe("HTTP writer", func() {
+	var fake *fakegate.Server
+	var w *Writer
+
+	BeforeEach(func() {
+		var err error
+		fake, err = fakegate.Start("127.0.0.1:0", fakegate.Options{BackpressureEvery: 2})
+		Expect(err).NotTo(HaveOccurred())
+		w = NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 2, MxgateURL: fake.URL(), BackoffMaxRetries: 3}}).(*Writer)
+		w.tableName = "public.t1"
+	})
+
+	AfterEach(func() {
+		Expect(fake.Close()).To(Succeed())
+	})
+
+	It("should tell the backpressure of the fake mxgate", func() {
+		c := &fasthttp.HostClient{Addr: w.addr}
+		_, _, err := w.post(c, []byte("public.t1\n1|a\n"))
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = w.post(c, []byte("public.t1\n1|a\n"))
+		Expect(err).To(Equal(errBackoff))
+	})
+
+	It("should post again after backing off", func() {
+		c := &fasthttp.HostClient{Addr: w.addr}
+		for i := 0; i < 2; i++ {
+			_, err := w.postWithBackoff(c, []byte("public.t1\n1|a\n2|b\n"))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(w.stat.backoffs).To(Equal(int64(1)))
+		stats := fake.Stats()
+		Expect(stats.Posts).To(Equal(int64(3)))
+		Expect(stats.Rows).To(Equal(int64(4)))
+	})
+
+	It("should fail after the max retries", func() {
+		always, err := fakegate.Start("127.0.0.1:0", fakegate.Options{BackpressureEvery: 1})
+		Expect(err).NotTo(HaveOccurred())
+		defer always.Close()
+		w.setURL(always.URL())
+		w.hCfg.BackoffMaxRetries = 2
+
+		c := &fasthttp.HostClient{Addr: w.addr}
+		_, err = w.postWithBackoff(c, []byte("public.t1\n1|a\n"))
+		Expect(err).To(MatchError(ContainSubstring("still after 2 retries")))
+		Expect(w.stat.backoffs).To(Equal(int64(2)))
+		Expect(always.Stats().Posts).To(Equal(int64(3)))
+	})
+
+	It("should send all the data written", func() {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			w.send()
+		}()
+		for i := 0; i < 10; i++ {
+			Expect(w.Write([]byte("1|a\n2|b\n"), 2, 8)).To(Succeed())
+		}
+		Expect(w.WriteEOF()).To(Succeed())
+		Eventually(done, "5s").Should(BeClosed())
+
+		Expect(w.finCh).To(BeEmpty())
+		stats := fake.Stats()
+		Expect(stats.Tables).To(HaveKeyWithValue("public.t1", int64(20)))
+		Expect(w.stat.count).To(Equal(int64(20)))
+	})
+})
+
+var _ = Describe("Shared mxgate", func() {
+	launch := func() (*exec.Cmd, error) { return nil, nil }
+
+	It("should be released after all the writers release it", func() {
+		g := newGate()
+		Expect(g.acquire(launch)).To(Succeed())
+		Expect(g.acquire(launch)).To(Succeed())
+
+		released := make(chan struct{})
+		go func() {
+			defer close(released)
+			g.release(2)
+		}()
+		Consistently(released).ShouldNot(BeClosed())
+		g.release(2)
+		Eventually(released).Should(BeClosed())
+	})
+
+	It("should release the writers once closed", func() {
+		g := newGate()
+		Expect(g.acquire(launch)).To(Succeed())
+
+		released := make(chan struct{})
+		go func() {
+			defer close(released)
+			g.release(2)
+		}()
+		Expect(g.Close()).To(Succeed())
+		Eventually(released).Should(BeClosed())
+		Expect(g.acquire(launch)).To(Equal(errGateClosed))
+	})
+
+	It("should be created for each run", func() {
+		newShared := func() *gate {
+			return (&engine.WriterShared{}).Load(func() io.Closer { return newGate() }).(*gate)
+		}
+		g := newShared()
+		Expect(g.Close()).To(Succeed())
+		Expect(newShared().acquire(launch)).To(Succeed())
+	})
+})
@@ -21,6 +21,7 @@ type Stat struct {
 	size, lastWatchSize             int64
 	sizeToGate, lastWatchSizeToGate int64
 	count, lastWatchCount           int64
+	backoffs                        int64
 	volumeDesc                      engine.VolumeDesc
 	config                          *Config
 }
@@ -60,6 +61,7 @@ func (s *Stat) GetSummary() string {
 		{"stop time:", s.stopAt.Format(s.config.getProgressTimeLayout())},
 		{"size written to mxgate (bytes):", s.sizeToGate},
 		{"lines inserted:", s.count},
+		{"posts backed off:", atomic.LoadInt64(&s.backoffs)},
 		{"compress ratio:", fmt.Sprintf("%.4f : 1", compressRatio)},
 	})
 	// Set Style
@@ -90,6 +92,25 @@ func (s *Stat) GetFormattedSummary() string {
 	return row
 }
 
+func (s *Stat) GetWriterSummary() engine.WriterSummary {
+	compressRatio, _ := s.GetTableCompressRatio()
+	return engine.WriterSummary{
+		StartAt:       s.startAt,
+		StopAt:        s.stopAt,
+		Bytes:         atomic.LoadInt64(&s.sizeToGate),
+		Lines:         atomic.LoadInt64(&s.count),
+		CompressRatio: compressRatio,
+	}
+}
+
+func (s *Stat) CollectMetrics(mw *engine.MetricsWriter) {
+	label := engine.MetricLabel{Name: "writer", Value: "http"}
+	mw.Counter("mxbench_writer_lines_total", "The number of lines written", float64(atomic.LoadInt64(&s.count)), label)
+	mw.Counter("mxbench_writer_bytes_total", "The size of data generated to write in bytes", float64(atomic.LoadInt64(&s.size)), label)
+	mw.Counter("mxbench_writer_bytes_to_gate_total", "The size of data written to mxgate in bytes", float64(atomic.LoadInt64(&s.sizeToGate)), label)
+	mw.Counter("mxbench_writer_backoffs_total", "The number of posts backed off as mxgate asks for backpressure", float64(atomic.LoadInt64(&s.backoffs)), label)
+}
+
 func (s *Stat) AddSubStat(engine.Stat) {}
 func (s *Stat) GetSubStats() []engine.Stat {
 	return nil
@@ -159,6 +180,11 @@ func (s *Stat) getProgressWithListStr() string {
 	l.AppendItem(fmt.Sprintf("size written in total: %d bytes/ %d bytes %.2f%%, %d bytes in this period\n", size, estimatedSize,
 		sizeProgressPercentile,
 		size-s.lastWatchSize))
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		achievedRate := engine.GetAchievedRate(target, s.count-s.lastWatchCount, size-s.lastWatchSize, now.Sub(s.lastWatchAt))
+		l.AppendItem(fmt.Sprintf("rate achieved in this period: %.2f %s/s, target rate: %.2f %s/s\n", achievedRate, target.GetUnit(),
+			target.GetCurrentRate(), target.GetUnit()))
+	}
 	l.AppendItem(fmt.Sprintf("size written to mxgate in total: %d bytes, %d bytes in this period\n", s.sizeToGate,
 		s.sizeToGate-s.lastWatchSizeToGate))
 	if s.config.ProgressIncludeTableSize && s.volumeDesc.GetTableSizeFunc != nil {
@@ -200,6 +226,11 @@ func (s *Stat) getProgressWithJSONStr() string {
 	size := atomic.LoadInt64(&s.size)
 	progress.CurrTotalBytes = size
 	progress.CurrPeriodBytes = size - s.lastWatchSize
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		progress.RateUnit = target.GetUnit()
+		progress.TargetRate = target.GetCurrentRate()
+		progress.AchievedRate = engine.GetAchievedRate(target, progress.CurrPeriodRows, progress.CurrPeriodBytes, now.Sub(s.lastWatchAt))
+	}
 	progress.TotalBytes = s.volumeDesc.GeneratorPrediction.Size
 
 	progress.WrittenMxgateTotal = s.sizeToGate
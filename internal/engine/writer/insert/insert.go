@@ -0,0 +1,369 @@
+package insert
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/spf13/pflag"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+	"github.com/ymatrix-data/mxbench/pkg/histogram"
+)
+
+const (
+	// The extended protocol carries at most 65535 parameters in one message.
+	_MAX_PARAMS_PER_STATEMENT = 65535
+
+	_SELECT_COLUMN_NAMES = `
+SELECT attname
+FROM pg_catalog.pg_attribute
+WHERE attrelid = $1::regclass
+  AND attnum > 0
+  AND NOT attisdropped
+ORDER BY attnum`
+)
+
+type Config struct {
+	Parallel         int    `mapstructure:"writer-parallel"`
+	RowsPerStatement int    `mapstructure:"writer-rows-per-statement"`
+	UsePrepared      bool   `mapstructure:"writer-use-prepared"`
+	OnConflict       string `mapstructure:"writer-on-conflict"`
+
+	ProgressFormat           string `mapstructure:"writer-progress-format"`
+	ProgressIncludeTableSize bool   `mapstructure:"writer-progress-include-table-size"`
+	ProgressWithTimezone     bool   `mapstructure:"writer-progress-with-timezone"`
+}
+
+func (c *Config) getProgressTimeLayout() string {
+	if c.ProgressWithTimezone {
+		return util.TIME_WITH_TZ_FMT
+	}
+	return util.TIME_FMT
+}
+
+// Writer turns every batch into parameterized multi-row INSERT statements,
+// which is the way most ORMs ingest data.
+type Writer struct {
+	iCfg *Config
+
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	finCh      chan error
+
+	stat *Stat
+
+	db       *sqlx.DB
+	batchCh  chan *sendAndFeed
+	globalWG sync.WaitGroup
+
+	tableName string
+	columns   []string
+
+	// statements caches the INSERT statement by the number of rows it carries.
+	statements sync.Map
+}
+
+type sendAndFeed struct {
+	msg  []byte
+	feed chan struct{}
+}
+
+func NewWriter(cfg engine.WriterConfig) engine.IWriter {
+	iCfg := cfg.PluginConfig.(*Config)
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	return &Writer{
+		iCfg:       iCfg,
+		ctx:        ctx,
+		cancelFunc: cancelFunc,
+		finCh:      make(chan error, iCfg.Parallel),
+		batchCh:    make(chan *sendAndFeed, 100),
+		stat:       &Stat{},
+	}
+}
+
+func newStat(volumeDesc engine.VolumeDesc, cfg *Config) *Stat {
+	return &Stat{
+		volumeDesc: volumeDesc,
+		config:     cfg,
+		latencies:  histogram.New(int64(time.Hour)),
+	}
+}
+
+func (w *Writer) Start(cfg engine.Config, volumeDesc engine.VolumeDesc) (<-chan error, error) {
+	if w.iCfg.Parallel <= 0 {
+		return nil, mxerror.CommonErrorf("writer-parallel of insert writer should be positive, got %d", w.iCfg.Parallel)
+	}
+	if w.iCfg.RowsPerStatement <= 0 {
+		return nil, mxerror.CommonErrorf("writer-rows-per-statement should be positive, got %d", w.iCfg.RowsPerStatement)
+	}
+
+	w.stat = newStat(volumeDesc, w.iCfg)
+	w.tableName = fmt.Sprintf("%s.%s", pq.QuoteIdentifier(cfg.GlobalCfg.SchemaName), pq.QuoteIdentifier(cfg.GlobalCfg.TableName))
+
+	connCfg, err := pgx.ParseConfig(cfg.DB.GetConnStr())
+	if err != nil {
+		return nil, mxerror.CommonErrorf("insert writer failed to parse connection string: %v", err)
+	}
+	if !w.iCfg.UsePrepared {
+		// Without the statement cache, every statement is parsed
+		// by an unnamed prepared statement, no server side statement survives.
+		connCfg.BuildStatementCache = nil
+	}
+	w.db = sqlx.NewDb(stdlib.OpenDB(*connCfg), "pgx")
+	w.db.SetMaxOpenConns(w.iCfg.Parallel)
+	w.db.SetMaxIdleConns(w.iCfg.Parallel)
+	if err = w.db.PingContext(w.ctx); err != nil {
+		_ = w.db.Close()
+		return nil, mxerror.CommonErrorf("insert writer failed to connect to database: %v", err)
+	}
+
+	err = w.db.SelectContext(w.ctx, &w.columns, _SELECT_COLUMN_NAMES, w.tableName)
+	if err != nil {
+		_ = w.db.Close()
+		return nil, mxerror.CommonErrorf("insert writer failed to get columns of %s: %v", w.tableName, err)
+	}
+	if err = checkParamsPerStatement(len(w.columns), w.iCfg.RowsPerStatement); err != nil {
+		_ = w.db.Close()
+		return nil, err
+	}
+
+	w.globalWG.Add(1)
+	w.stat.startAt = time.Now()
+	go func() {
+		defer func() {
+			_ = w.db.Close()
+			w.globalWG.Done()
+			w.stat.stopAt = time.Now()
+			// Notify insert writer finished
+			close(w.finCh)
+		}()
+		w.send()
+	}()
+
+	return w.finCh, nil
+}
+
+// checkParamsPerStatement fails if a statement of the rows carries more parameters than the protocol allows
+func checkParamsPerStatement(numOfColumns, rowsPerStatement int) error {
+	if numOfColumns*rowsPerStatement > _MAX_PARAMS_PER_STATEMENT {
+		return mxerror.CommonErrorf("writer-rows-per-statement %d is too large for %d columns, at most %d parameters are allowed in one statement",
+			rowsPerStatement, numOfColumns, _MAX_PARAMS_PER_STATEMENT)
+	}
+	return nil
+}
+
+func (w *Writer) send() {
+	var wg sync.WaitGroup
+
+	wg.Add(w.iCfg.Parallel)
+
+	for i := 0; i < w.iCfg.Parallel; i++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-w.ctx.Done():
+					return
+
+				case body, ok := <-w.batchCh:
+					if !ok {
+						return
+					}
+
+					rows, err := w.parse(body.msg)
+					close(body.feed)
+					if err != nil {
+						w.fail(err)
+						return
+					}
+
+					for len(rows) > 0 {
+						n := w.iCfg.RowsPerStatement
+						if n > len(rows) {
+							n = len(rows)
+						}
+						if err = w.insert(rows[:n]); err != nil {
+							w.fail(err)
+							return
+						}
+						rows = rows[n:]
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// parse splits a batch in the delimited CSV format into rows of arguments.
+// An empty field is sent as NULL, the same as COPY does.
+func (w *Writer) parse(msg []byte) ([][]interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(msg))
+	r.Comma = []rune(util.DELIMITER)[0]
+	r.FieldsPerRecord = len(w.columns)
+	r.ReuseRecord = true
+
+	var rows [][]interface{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(record))
+		for i, field := range record {
+			if field != "" {
+				row[i] = field
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+func (w *Writer) insert(rows [][]interface{}) error {
+	args := make([]interface{}, 0, len(rows)*len(w.columns))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+
+	sql := w.getStatement(len(rows))
+	ts := time.Now()
+	_, err := w.db.ExecContext(w.ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	w.stat.addLatency(time.Since(ts))
+	atomic.AddInt64(&w.stat.rowsInserted, int64(len(rows)))
+	return nil
+}
+
+func (w *Writer) getStatement(numOfRows int) string {
+	if sql, ok := w.statements.Load(numOfRows); ok {
+		return sql.(string)
+	}
+	sql := buildInsertStatement(w.tableName, w.columns, numOfRows, w.iCfg.OnConflict)
+	w.statements.Store(numOfRows, sql)
+	return sql
+}
+
+func buildInsertStatement(tableName string, columns []string, numOfRows int, onConflict string) string {
+	var b strings.Builder
+	b.WriteString("INSERT INTO ")
+	b.WriteString(tableName)
+	b.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(pq.QuoteIdentifier(column))
+	}
+	b.WriteString(") VALUES ")
+
+	param := 1
+	for i := 0; i < numOfRows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(fmt.Sprintf("$%d", param))
+			param++
+		}
+		b.WriteByte(')')
+	}
+
+	if onConflict != "" {
+		b.WriteString(" ON CONFLICT ")
+		b.WriteString(onConflict)
+	}
+	return b.String()
+}
+
+// fail reports the error to the engine and stops all the other workers,
+// so that Write does not block on a writer that has gone.
+func (w *Writer) fail(err error) {
+	log.Error("insert writer failed: %v", err)
+	w.finCh <- err
+	w.cancelFunc()
+}
+
+func (w *Writer) Stop() error {
+	w.cancelFunc()
+	w.globalWG.Wait()
+
+	log.Verbose("Parallel: %d", w.iCfg.Parallel)
+	log.Verbose("Rows Per Statement: %d", w.iCfg.RowsPerStatement)
+	log.Verbose("Num Statement: %d", w.stat.getStatementCount())
+
+	return nil
+}
+
+func (w *Writer) Write(msg []byte, msgCnt, msgSize int64) error {
+	ch := make(chan struct{})
+	select {
+	case <-w.ctx.Done():
+		return mxerror.CommonError("insert writer has stopped")
+	case w.batchCh <- &sendAndFeed{msg: msg, feed: ch}:
+	}
+
+	select {
+	case <-w.ctx.Done():
+		return mxerror.CommonError("insert writer has stopped")
+	case <-ch:
+	}
+
+	atomic.AddInt64(&w.stat.size, msgSize)
+	atomic.AddInt64(&w.stat.count, msgCnt)
+	return nil
+}
+
+func (w *Writer) WriteEOF() error {
+	close(w.batchCh)
+	return nil
+}
+
+func (w *Writer) GetStat() engine.Stat {
+	return w.stat
+}
+
+func (w *Writer) CreatePluginConfig() interface{} {
+	return &Config{}
+}
+
+func (w *Writer) GetDefaultFlags() (*pflag.FlagSet, interface{}) {
+	iCfg := &Config{}
+	p := pflag.NewFlagSet("writer.insert", pflag.ContinueOnError)
+	p.IntVar(&iCfg.Parallel, "writer-parallel", 8, "The number of connections executing INSERT in parallel")
+	p.IntVar(&iCfg.RowsPerStatement, "writer-rows-per-statement", 100, "The number of rows carried by one INSERT statement")
+	p.BoolVar(&iCfg.UsePrepared, "writer-use-prepared", true, "whether to execute INSERT by named prepared statements")
+	p.StringVar(&iCfg.OnConflict, "writer-on-conflict", "", "the clause following \"ON CONFLICT\", e.g. \"DO NOTHING\", empty means no upsert")
+
+	p.StringVar(&iCfg.ProgressFormat, "writer-progress-format", "list", "progress format. support \"list\", \"json\"")
+	p.BoolVar(&iCfg.ProgressIncludeTableSize, "writer-progress-include-table-size", false, "whether progress include table size")
+	p.BoolVar(&iCfg.ProgressWithTimezone, "writer-progress-with-timezone", false, "whether print time with timezone")
+	return p, iCfg
+}
+
+func (w *Writer) IsNil() bool {
+	return w == nil
+}
@@ -0,0 +1,13 @@
+package insert
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestInsert(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Insert Writer Suite")
+}
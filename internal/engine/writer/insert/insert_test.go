@@ -0,0 +1,71 @@
+package insert
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+var _ = Describe("Insert writer", func() {
+	var w *Writer
+
+	BeforeEach(func() {
+		w = NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 1, RowsPerStatement: 2}}).(*Writer)
+		w.tableName = `"public"."t1"`
+		w.columns = []string{"ts", "vin", "ext"}
+	})
+
+	It("should parse an empty field as NULL", func() {
+		rows, err := w.parse([]byte("2022-04-25 09:00:00|v1|\n2022-04-25 09:00:01||x\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(Equal([][]interface{}{
+			{"2022-04-25 09:00:00", "v1", nil},
+			{"2022-04-25 09:00:01", nil, "x"},
+		}))
+	})
+
+	It("should parse a quoted json containing the delimiter", func() {
+		rows, err := w.parse([]byte(`2022-04-25 09:00:00|v1|"{""a"": ""x|y""}"` + "\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(Equal([][]interface{}{{"2022-04-25 09:00:00", "v1", `{"a": "x|y"}`}}))
+	})
+
+	It("should fail parsing a line of the wrong number of fields", func() {
+		_, err := w.parse([]byte("2022-04-25 09:00:00|v1\n"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should build the INSERT statement of the rows", func() {
+		Expect(buildInsertStatement(w.tableName, w.columns, 2, "")).To(Equal(
+			`INSERT INTO "public"."t1" ("ts", "vin", "ext") VALUES ($1, $2, $3), ($4, $5, $6)`))
+	})
+
+	It("should render ON CONFLICT", func() {
+		Expect(buildInsertStatement(w.tableName, w.columns, 1, "DO NOTHING")).To(Equal(
+			`INSERT INTO "public"."t1" ("ts", "vin", "ext") VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`))
+	})
+
+	It("should cache the statement by the number of rows", func() {
+		two := w.getStatement(2)
+		one := w.getStatement(1)
+		Expect(two).To(HaveSuffix("($4, $5, $6)"))
+		Expect(one).To(HaveSuffix("VALUES ($1, $2, $3)"))
+
+		w.columns = []string{"changed"}
+		Expect(w.getStatement(2)).To(Equal(two))
+		Expect(w.getStatement(3)).To(Equal(`INSERT INTO "public"."t1" ("changed") VALUES ($1), ($2), ($3)`))
+	})
+
+	It("should limit the parameters of a statement", func() {
+		Expect(checkParamsPerStatement(3, 21845)).To(Succeed())
+		Expect(checkParamsPerStatement(3, 21846)).To(MatchError(ContainSubstring(
+			"writer-rows-per-statement 21846 is too large for 3 columns, at most 65535 parameters are allowed in one statement")))
+	})
+
+	It("should reject a non-positive rows per statement", func() {
+		w = NewWriter(engine.WriterConfig{PluginConfig: &Config{Parallel: 1}}).(*Writer)
+		_, err := w.Start(engine.Config{}, engine.VolumeDesc{})
+		Expect(err).To(MatchError(ContainSubstring("writer-rows-per-statement should be positive")))
+	})
+})
@@ -0,0 +1,326 @@
+package insert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/list"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/pkg/histogram"
+)
+
+type Stat struct {
+	startAt, stopAt, lastWatchAt        time.Time
+	size, lastWatchSize                 int64
+	count, lastWatchCount               int64
+	rowsInserted, lastWatchRowsInserted int64
+	volumeDesc                          engine.VolumeDesc
+	config                              *Config
+
+	latencies               *histogram.Histogram
+	lastWatchStatementCount int
+}
+
+// StatementLatency is the latency distribution of INSERT statements,
+// named after the fields of engine.ExecBenchStat.
+type StatementLatency struct {
+	Statements int           `json:"statements"`
+	AvgLatency time.Duration `json:"avg-latency"`
+	MaxLatency time.Duration `json:"max-latency"`
+	P99Latency time.Duration `json:"p99-latency"`
+	P75Latency time.Duration `json:"p75-latency"`
+	P50Latency time.Duration `json:"p50-latency"`
+	P25Latency time.Duration `json:"p25-latency"`
+}
+
+func (s *Stat) addLatency(latency time.Duration) {
+	s.latencies.Record(int64(latency))
+}
+
+func (s *Stat) getStatementCount() int {
+	if s.latencies == nil {
+		return 0
+	}
+	return int(s.latencies.Count())
+}
+
+func (s *Stat) getStatementLatency() StatementLatency {
+	latencies := s.latencies
+	if latencies == nil || latencies.Count() == 0 {
+		return StatementLatency{}
+	}
+	return StatementLatency{
+		Statements: int(latencies.Count()),
+		AvgLatency: time.Duration(latencies.Mean()),
+		MaxLatency: time.Duration(latencies.Max()),
+		P99Latency: time.Duration(latencies.ValueAtPercentile(99)),
+		P75Latency: time.Duration(latencies.ValueAtPercentile(75)),
+		P50Latency: time.Duration(latencies.ValueAtPercentile(50)),
+		P25Latency: time.Duration(latencies.ValueAtPercentile(25)),
+	}
+}
+
+func (s *Stat) GetTableCompressRatio() (float64, error) {
+	if s.volumeDesc.GetTableSizeFunc == nil {
+		return 0, nil
+	}
+	sizeInDB, err := s.volumeDesc.GetTableSizeFunc()
+	if err != nil {
+		return 0, err
+	}
+	sizeWritten := atomic.LoadInt64(&s.size)
+	compressRatio := float64(0)
+	if sizeWritten > 0 {
+		compressRatio = float64(sizeWritten) / float64(sizeInDB)
+	}
+	return compressRatio, nil
+}
+
+// GetSummary is aimed at presenting statistics to the user
+// in the form of a table empowered by go-pretty.
+// Any data in float will be rounded to 2 decimal places.
+func (s *Stat) GetSummary() string {
+	if s.volumeDesc.GetTableSizeFunc == nil {
+		return ""
+	}
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+
+	compressRatio, err := s.GetTableCompressRatio()
+	if err != nil {
+		return ""
+	}
+	latency := s.getStatementLatency()
+	tbl.AppendRows([]table.Row{
+		{"start time:", s.startAt.Format(s.config.getProgressTimeLayout())},
+		{"stop time:", s.stopAt.Format(s.config.getProgressTimeLayout())},
+		{"size inserted (bytes):", atomic.LoadInt64(&s.size)},
+		{"lines inserted:", atomic.LoadInt64(&s.rowsInserted)},
+		{"compress ratio:", fmt.Sprintf("%.4f : 1", compressRatio)},
+		{"statements executed:", latency.Statements},
+		{"average statement latency:", fmt.Sprintf("%.3fms", float64(latency.AvgLatency.Nanoseconds())/1e6)},
+		{"P99 statement latency:", fmt.Sprintf("%.3fms", float64(latency.P99Latency.Nanoseconds())/1e6)},
+		{"P75 statement latency:", fmt.Sprintf("%.3fms", float64(latency.P75Latency.Nanoseconds())/1e6)},
+		{"P50 statement latency:", fmt.Sprintf("%.3fms", float64(latency.P50Latency.Nanoseconds())/1e6)},
+		{"P25 statement latency:", fmt.Sprintf("%.3fms", float64(latency.P25Latency.Nanoseconds())/1e6)},
+		{"max statement latency:", fmt.Sprintf("%.3fms", float64(latency.MaxLatency.Nanoseconds())/1e6)},
+	})
+	// Set Style
+	tbl.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Default", Align: text.AlignCenter, AlignHeader: text.AlignCenter},
+	})
+	tbl.SetStyle(table.StyleLight)
+	tbl.Style().Title.Align = text.AlignCenter
+	tbl.Style().Options.SeparateRows = true
+	tbl.SetTitle("Summary Report for INSERT Writer")
+
+	return tbl.Render()
+}
+
+// GetFormattedSummary is aimed at outputing statistics in certain format.
+// No data in will be rounded.
+// The statement latencies follow the columns shared with the other writers.
+func (s *Stat) GetFormattedSummary() string {
+	if s.volumeDesc.GetTableSizeFunc == nil {
+		return ""
+	}
+	startTime := s.startAt.Format(s.config.getProgressTimeLayout())
+	stopTime := s.stopAt.Format(s.config.getProgressTimeLayout())
+	sizeInsertedBytes := fmt.Sprintf("%d", atomic.LoadInt64(&s.size))
+	insertedLines := fmt.Sprintf("%d", atomic.LoadInt64(&s.rowsInserted))
+	compressRatio, _ := s.GetTableCompressRatio()
+	latency := s.getStatementLatency()
+	writeReports := []string{startTime, stopTime, sizeInsertedBytes, insertedLines, fmt.Sprintf("%f", compressRatio),
+		fmt.Sprintf("%d", latency.Statements),
+		latency.AvgLatency.String(),
+		latency.P99Latency.String(),
+		latency.P75Latency.String(),
+		latency.P50Latency.String(),
+		latency.P25Latency.String(),
+		latency.MaxLatency.String(),
+	}
+	row := strings.Join(writeReports, util.DELIMITER)
+	return row
+}
+
+func (s *Stat) GetWriterSummary() engine.WriterSummary {
+	compressRatio, _ := s.GetTableCompressRatio()
+	return engine.WriterSummary{
+		StartAt:       s.startAt,
+		StopAt:        s.stopAt,
+		Bytes:         atomic.LoadInt64(&s.size),
+		Lines:         atomic.LoadInt64(&s.rowsInserted),
+		CompressRatio: compressRatio,
+	}
+}
+
+func (s *Stat) AddSubStat(engine.Stat) {}
+func (s *Stat) GetSubStats() []engine.Stat {
+	return nil
+}
+func (s *Stat) GetName() string {
+	return ""
+}
+
+func (s *Stat) GetProgress() string {
+	if s.startAt.IsZero() {
+		return "start time is not set"
+	}
+
+	switch s.config.ProgressFormat {
+	case "json":
+		return s.getProgressWithJSONStr()
+	case "list":
+		return s.getProgressWithListStr()
+	default:
+		return "progress info does not support format: " + s.config.ProgressFormat
+	}
+}
+
+// getProgressWithListStr is aimed at presenting statistics to the user
+// in the form of a table empowered by go-pretty.
+// Any data in float will be rounded to 2 decimal places.
+func (s *Stat) getProgressWithListStr() string {
+	start := s.startAt
+
+	l := list.NewWriter()
+	l.SetStyle(list.StyleBulletCircle)
+
+	l.AppendItem("INSERT Writer Report")
+	l.Indent()
+
+	if !s.stopAt.IsZero() {
+		l.AppendItem("100% generating completed")
+		fmt.Println(l.Render())
+		return ""
+	}
+	now := time.Now()
+	if s.lastWatchAt.IsZero() {
+		s.lastWatchAt = start
+	}
+	start = s.lastWatchAt
+
+	l.AppendItem(fmt.Sprintf("period start: %s, end: %s, period: %.2f seconds\n", start.Format(s.config.getProgressTimeLayout()), now.Format(s.config.getProgressTimeLayout()),
+		now.Sub(s.lastWatchAt).Seconds()))
+
+	count := s.volumeDesc.GeneratorPrediction.Count
+	estimatedSize := s.volumeDesc.GeneratorPrediction.Size
+
+	currCount := atomic.LoadInt64(&s.count)
+	countStatItem := fmt.Sprintf("count written in total: %d rows, %d rows in this period\n", currCount,
+		currCount-s.lastWatchCount)
+	if count > 0 {
+		countStatItem = fmt.Sprintf("count written in total: %d rows/ %d rows %.2f%%, %d rows in this period\n", currCount, count,
+			100*float64(currCount)/float64(count),
+			currCount-s.lastWatchCount)
+	}
+	l.AppendItem(countStatItem)
+
+	size := atomic.LoadInt64(&s.size)
+	sizeProgressPercentile := 100 * float64(size) / float64(estimatedSize)
+	if sizeProgressPercentile > 100 {
+		sizeProgressPercentile = 100
+	}
+	l.AppendItem(fmt.Sprintf("size written in total: %d bytes/ %d bytes %.2f%%, %d bytes in this period\n", size, estimatedSize,
+		sizeProgressPercentile,
+		size-s.lastWatchSize))
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		achievedRate := engine.GetAchievedRate(target, currCount-s.lastWatchCount, size-s.lastWatchSize, now.Sub(s.lastWatchAt))
+		l.AppendItem(fmt.Sprintf("rate achieved in this period: %.2f %s/s, target rate: %.2f %s/s\n", achievedRate, target.GetUnit(),
+			target.GetCurrentRate(), target.GetUnit()))
+	}
+
+	rowsInserted := atomic.LoadInt64(&s.rowsInserted)
+	l.AppendItem(fmt.Sprintf("rows inserted in total: %d rows, %d rows in this period\n", rowsInserted,
+		rowsInserted-s.lastWatchRowsInserted))
+
+	latency := s.getStatementLatency()
+	l.AppendItem(fmt.Sprintf("statements executed in total: %d, %d in this period, latency avg: %.3fms, p50: %.3fms, p75: %.3fms, max: %.3fms\n",
+		latency.Statements, latency.Statements-s.lastWatchStatementCount,
+		float64(latency.AvgLatency.Nanoseconds())/1e6,
+		float64(latency.P50Latency.Nanoseconds())/1e6,
+		float64(latency.P75Latency.Nanoseconds())/1e6,
+		float64(latency.MaxLatency.Nanoseconds())/1e6))
+	if s.config.ProgressIncludeTableSize && s.volumeDesc.GetTableSizeFunc != nil {
+		tableSize, _ := s.volumeDesc.GetTableSizeFunc()
+		l.AppendItem(fmt.Sprintf("table size: %d bytes\n", tableSize))
+	}
+
+	s.lastWatchAt = now
+	s.lastWatchCount = currCount
+	s.lastWatchSize = size
+	s.lastWatchRowsInserted = rowsInserted
+	s.lastWatchStatementCount = latency.Statements
+
+	return l.Render()
+}
+
+// getProgressWithJSONStr is aimed at outputing statistics in JSON.
+// No data in will be rounded.
+func (s *Stat) getProgressWithJSONStr() string {
+	if !s.stopAt.IsZero() {
+		return ""
+	}
+
+	progress := &WriterProgress{}
+	now := time.Now()
+	if s.lastWatchAt.IsZero() {
+		s.lastWatchAt = s.startAt
+	}
+	start := s.lastWatchAt
+	progress.Start = start.Format(s.config.getProgressTimeLayout())
+	progress.End = now.Format(s.config.getProgressTimeLayout())
+	progress.Period = now.Sub(s.lastWatchAt).String()
+
+	currCount := atomic.LoadInt64(&s.count)
+	progress.CurrTotalRows = currCount
+	progress.CurrPeriodRows = currCount - s.lastWatchCount
+	if s.volumeDesc.GeneratorPrediction.Count > 0 {
+		progress.TotalRows = s.volumeDesc.GeneratorPrediction.Count
+	}
+
+	size := atomic.LoadInt64(&s.size)
+	progress.CurrTotalBytes = size
+	progress.CurrPeriodBytes = size - s.lastWatchSize
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		progress.RateUnit = target.GetUnit()
+		progress.TargetRate = target.GetCurrentRate()
+		progress.AchievedRate = engine.GetAchievedRate(target, progress.CurrPeriodRows, progress.CurrPeriodBytes, now.Sub(s.lastWatchAt))
+	}
+	progress.TotalBytes = s.volumeDesc.GeneratorPrediction.Size
+
+	rowsInserted := atomic.LoadInt64(&s.rowsInserted)
+	progress.InsertedTotalRows = rowsInserted
+	progress.CurrPeriodInsertedRows = rowsInserted - s.lastWatchRowsInserted
+	progress.StatementLatency = s.getStatementLatency()
+	progress.CurrPeriodStatements = progress.StatementLatency.Statements - s.lastWatchStatementCount
+	if s.config.ProgressIncludeTableSize && s.volumeDesc.GetTableSizeFunc != nil {
+		progress.TableSize, _ = s.volumeDesc.GetTableSizeFunc()
+	}
+
+	s.lastWatchAt = now
+	s.lastWatchCount = currCount
+	s.lastWatchSize = size
+	s.lastWatchRowsInserted = rowsInserted
+	s.lastWatchStatementCount = progress.StatementLatency.Statements
+
+	b, err := json.Marshal(&progress)
+	if err != nil {
+		log.Warn("failed to marshal progress to json: %v", err)
+	}
+
+	return string(b)
+}
+
+func (s *Stat) GetCurrentProgress(_ ...interface{}) map[string]interface{} {
+	// placeholder
+	return nil
+}
@@ -0,0 +1,21 @@
+package insert
+
+type WriterProgress struct {
+	Start                  string           `json:"start"`
+	End                    string           `json:"end"`
+	Period                 string           `json:"period"`
+	CurrTotalRows          int64            `json:"currTotalRows"`
+	TotalRows              int64            `json:"totalRows"`
+	CurrPeriodRows         int64            `json:"currPeriodRows"`
+	CurrTotalBytes         int64            `json:"currTotalBytes"`
+	TotalBytes             int64            `json:"totalBytes"`
+	CurrPeriodBytes        int64            `json:"currPeriodBytes"`
+	InsertedTotalRows      int64            `json:"insertedTotalRows"`
+	CurrPeriodInsertedRows int64            `json:"currPeriodInsertedRows"`
+	CurrPeriodStatements   int              `json:"currPeriodStatements"`
+	StatementLatency       StatementLatency `json:"statementLatency"`
+	TableSize              int64            `json:"tableSize"`
+	RateUnit               string           `json:"rateUnit,omitempty"`
+	TargetRate             float64          `json:"targetRate,omitempty"`
+	AchievedRate           float64          `json:"achievedRate,omitempty"`
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/jedib0t/go-pretty/v6/list"
@@ -89,6 +90,24 @@ func (s *Stat) GetFormattedSummary() string {
 	return row
 }
 
+func (s *Stat) GetWriterSummary() engine.WriterSummary {
+	compressRatio, _ := s.GetTableCompressRatio()
+	return engine.WriterSummary{
+		StartAt:       s.startAt,
+		StopAt:        s.stopAt,
+		Bytes:         s.sizeToGate,
+		Lines:         s.count,
+		CompressRatio: compressRatio,
+	}
+}
+
+func (s *Stat) CollectMetrics(mw *engine.MetricsWriter) {
+	label := engine.MetricLabel{Name: "writer", Value: "stdin"}
+	mw.Counter("mxbench_writer_lines_total", "The number of lines written", float64(atomic.LoadInt64(&s.count)), label)
+	mw.Counter("mxbench_writer_bytes_total", "The size of data generated to write in bytes", float64(atomic.LoadInt64(&s.size)), label)
+	mw.Counter("mxbench_writer_bytes_to_gate_total", "The size of data written to mxgate in bytes", float64(atomic.LoadInt64(&s.sizeToGate)), label)
+}
+
 func (s *Stat) AddSubStat(engine.Stat) {}
 func (s *Stat) GetSubStats() []engine.Stat {
 	return nil
@@ -158,6 +177,11 @@ func (s *Stat) getProgressWithListStr() string {
 	l.AppendItem(fmt.Sprintf("size written in total: %d bytes/ %d bytes %.2f%%, %d bytes in this period\n", s.size, estimatedSize,
 		sizeProgressPercentile,
 		s.size-s.lastWatchSize))
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		achievedRate := engine.GetAchievedRate(target, s.count-s.lastWatchCount, s.size-s.lastWatchSize, now.Sub(s.lastWatchAt))
+		l.AppendItem(fmt.Sprintf("rate achieved in this period: %.2f %s/s, target rate: %.2f %s/s\n", achievedRate, target.GetUnit(),
+			target.GetCurrentRate(), target.GetUnit()))
+	}
 	l.AppendItem(fmt.Sprintf("size written to mxgate in total: %d bytes, %d bytes in this period\n", s.sizeToGate,
 		s.sizeToGate-s.lastWatchSizeToGate))
 	if s.config.ProgressIncludeTableSize && s.volumeDesc.GetTableSizeFunc != nil {
@@ -194,6 +218,11 @@ func (s *Stat) getProgressWithJSONStr() string {
 
 	progress.CurrTotalBytes = s.size
 	progress.CurrPeriodBytes = s.size - s.lastWatchSize
+	if target := s.volumeDesc.GeneratorPrediction.RateTarget; target != nil {
+		progress.RateUnit = target.GetUnit()
+		progress.TargetRate = target.GetCurrentRate()
+		progress.AchievedRate = engine.GetAchievedRate(target, progress.CurrPeriodRows, progress.CurrPeriodBytes, now.Sub(s.lastWatchAt))
+	}
 	progress.TotalBytes = s.volumeDesc.GeneratorPrediction.Size
 
 	progress.WrittenMxgateTotal = s.sizeToGate
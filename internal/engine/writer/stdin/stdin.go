@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -189,9 +190,10 @@ func (w *Writer) Write(m []byte, cnt, size int64) error {
 	if err != nil {
 		return err
 	}
-	w.stat.size += size
-	w.stat.sizeToGate += int64(sizeToGate)
-	w.stat.count += cnt
+	// stat is read by the metrics endpoint concurrently
+	atomic.AddInt64(&w.stat.size, size)
+	atomic.AddInt64(&w.stat.sizeToGate, int64(sizeToGate))
+	atomic.AddInt64(&w.stat.count, cnt)
 	return nil
 }
 
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeCloser struct {
+	closed int
+}
+
+func (c *fakeCloser) Close() error {
+	c.closed++
+	return nil
+}
+
+var _ = Describe("WriterShared", func() {
+	It("should share the value created first", func() {
+		s := &WriterShared{}
+		first := s.Load(func() io.Closer { return &fakeCloser{} })
+		Expect(s.Load(func() io.Closer { return &fakeCloser{} })).To(BeIdenticalTo(first))
+
+		Expect(s.Close()).To(Succeed())
+		Expect(s.Close()).To(Succeed())
+		Expect(first.(*fakeCloser).closed).To(Equal(1))
+	})
+
+	It("should close the value created after closed", func() {
+		s := &WriterShared{}
+		Expect(s.Close()).To(Succeed())
+		Expect(s.Load(func() io.Closer { return &fakeCloser{} }).(*fakeCloser).closed).To(Equal(1))
+	})
+})
@@ -0,0 +1,250 @@
+package result
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/jmoiron/sqlx"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+const (
+	_SELECT_RUN = `
+SELECT run_id, start_at, stop_at, version, command_line, generator, writer, benchmark, config, config_file
+FROM mxbench_run
+WHERE run_id = ?`
+	_SELECT_WRITER_RESULT = `
+SELECT writer, start_time, stop_time, bytes, lines, compress_ratio, summary
+FROM mxbench_writer_result
+WHERE run_id = ?`
+	_SELECT_QUERY_RESULTS = `
+SELECT seq, query_name, parallel, executions, tps, avg_latency_ns, stddev_latency_ns,
+	min_latency_ns, max_latency_ns, p50_latency_ns, p95_latency_ns, p99_latency_ns
+FROM mxbench_query_result
+WHERE run_id = ?
+ORDER BY seq`
+)
+
+type queryKey struct {
+	name     string
+	parallel int
+}
+
+// Compare returns the report of command compare,
+// the deltas of the second run against the first one.
+func Compare(cfg *engine.Config) (string, error) {
+	if cfg.GlobalCfg.ResultsDB == "" {
+		return "", mxerror.IncorrectUsageError("invalid usage: command compare needs --results-db")
+	}
+	db, err := open(cfg.GlobalCfg.ResultsDB)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	return compare(db, cfg.GlobalCfg.CompareRunIDs[0], cfg.GlobalCfg.CompareRunIDs[1])
+}
+
+func compare(db *sqlx.DB, runIDA, runIDB string) (string, error) {
+	a, err := loadRun(db, runIDA)
+	if err != nil {
+		return "", err
+	}
+	b, err := loadRun(db, runIDB)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	out.WriteString(renderRuns(a, b))
+	out.WriteString("\n")
+	out.WriteString(diffText("config", a.RunID, b.RunID, a.Config, b.Config))
+	out.WriteString(diffText("config file", a.RunID, b.RunID, a.ConfigFile, b.ConfigFile))
+
+	writerA, err := loadWriterResult(db, a.RunID)
+	if err != nil {
+		return "", err
+	}
+	writerB, err := loadWriterResult(db, b.RunID)
+	if err != nil {
+		return "", err
+	}
+	if writerA != nil || writerB != nil {
+		out.WriteString(renderWriterResults(writerA, writerB))
+		out.WriteString("\n")
+	}
+
+	queriesA, err := loadQueryResults(db, a.RunID)
+	if err != nil {
+		return "", err
+	}
+	queriesB, err := loadQueryResults(db, b.RunID)
+	if err != nil {
+		return "", err
+	}
+	if len(queriesA) > 0 || len(queriesB) > 0 {
+		out.WriteString(renderQueryResults(queriesA, queriesB))
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+func loadRun(db *sqlx.DB, runID string) (*run, error) {
+	r := &run{}
+	err := db.Get(r, db.Rebind(_SELECT_RUN), runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, mxerror.CommonErrorf("run %s is not found in results-db", runID)
+	}
+	if err != nil {
+		return nil, mxerror.CommonErrorf("failed to load run %s: %v", runID, err)
+	}
+	return r, nil
+}
+
+func loadWriterResult(db *sqlx.DB, runID string) (*writerResult, error) {
+	var results []writerResult
+	if err := db.Select(&results, db.Rebind(_SELECT_WRITER_RESULT), runID); err != nil {
+		return nil, mxerror.CommonErrorf("failed to load writer result of run %s: %v", runID, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
+
+func loadQueryResults(db *sqlx.DB, runID string) ([]queryResult, error) {
+	var results []queryResult
+	if err := db.Select(&results, db.Rebind(_SELECT_QUERY_RESULTS), runID); err != nil {
+		return nil, mxerror.CommonErrorf("failed to load query results of run %s: %v", runID, err)
+	}
+	return results, nil
+}
+
+func renderRuns(a, b *run) string {
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+	tbl.AppendHeader(table.Row{"", "A", "B"})
+	tbl.AppendRows([]table.Row{
+		{"run id", a.RunID, b.RunID},
+		{"start at", a.StartAt, b.StartAt},
+		{"stop at", a.StopAt, b.StopAt},
+		{"version", a.Version, b.Version},
+		{"generator", a.Generator, b.Generator},
+		{"writer", a.Writer, b.Writer},
+		{"benchmark", a.Benchmark, b.Benchmark},
+	})
+	tbl.SetTitle("Runs Compared")
+	return tbl.Render()
+}
+
+func diffText(name, runIDA, runIDB, a, b string) string {
+	if a == b {
+		return fmt.Sprintf("%s: identical\n", name)
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: runIDA,
+		ToFile:   runIDB,
+		Context:  1,
+	})
+	if err != nil {
+		return fmt.Sprintf("%s: differs, %v\n", name, err)
+	}
+	return fmt.Sprintf("%s:\n%s\n", name, diff)
+}
+
+func renderWriterResults(a, b *writerResult) string {
+	if a == nil {
+		a = &writerResult{}
+	}
+	if b == nil {
+		b = &writerResult{}
+	}
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+	tbl.AppendHeader(table.Row{"", "A", "B", "Delta"})
+	tbl.AppendRows([]table.Row{
+		{"size written (bytes)", a.Bytes, b.Bytes, delta(float64(a.Bytes), float64(b.Bytes))},
+		{"lines inserted", a.Lines, b.Lines, delta(float64(a.Lines), float64(b.Lines))},
+		{"compress ratio", fmt.Sprintf("%.4f", a.CompressRatio), fmt.Sprintf("%.4f", b.CompressRatio),
+			delta(a.CompressRatio, b.CompressRatio)},
+	})
+	tbl.SetTitle("Writer")
+	return tbl.Render()
+}
+
+// renderQueryResults compares the queries by their names and parallels,
+// and if a query runs for several rounds, the last round is compared.
+func renderQueryResults(a, b []queryResult) string {
+	var keys []queryKey
+	resultsA := make(map[queryKey]queryResult)
+	resultsB := make(map[queryKey]queryResult)
+	for _, q := range a {
+		key := queryKey{q.QueryName, q.Parallel}
+		if _, ok := resultsA[key]; !ok {
+			keys = append(keys, key)
+		}
+		resultsA[key] = q
+	}
+	for _, q := range b {
+		key := queryKey{q.QueryName, q.Parallel}
+		_, inA := resultsA[key]
+		_, inB := resultsB[key]
+		if !inA && !inB {
+			keys = append(keys, key)
+		}
+		resultsB[key] = q
+	}
+
+	tbl := table.NewWriter()
+	tbl.SetStyle(table.StyleLight)
+	tbl.AppendHeader(table.Row{"Query Name", "Parallel", "TPS", "Average Latency", "P50 Latency", "P95 Latency", "P99 Latency"})
+	for _, key := range keys {
+		qa, okA := resultsA[key]
+		qb, okB := resultsB[key]
+		if !okA || !okB {
+			tbl.AppendRow(table.Row{key.name, key.parallel, "only in " + onlyIn(okA), "", "", "", ""})
+			continue
+		}
+		tbl.AppendRow(table.Row{
+			key.name,
+			key.parallel,
+			fmt.Sprintf("%d -> %d (%s)", qa.TPS, qb.TPS, delta(float64(qa.TPS), float64(qb.TPS))),
+			latencyDelta(qa.AvgLatency, qb.AvgLatency),
+			latencyDelta(qa.P50Latency, qb.P50Latency),
+			latencyDelta(qa.P95Latency, qb.P95Latency),
+			latencyDelta(qa.P99Latency, qb.P99Latency),
+		})
+	}
+	tbl.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 2, Align: text.AlignRight},
+	})
+	tbl.SetTitle("Queries")
+	return tbl.Render()
+}
+
+func onlyIn(inA bool) string {
+	if inA {
+		return "A"
+	}
+	return "B"
+}
+
+func latencyDelta(a, b int64) string {
+	return fmt.Sprintf("%.3fms -> %.3fms (%s)", float64(a)/1e6, float64(b)/1e6, delta(float64(a), float64(b)))
+}
+
+// delta returns the change of b against a in percentage.
+func delta(a, b float64) string {
+	if a == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", (b-a)/a*100)
+}
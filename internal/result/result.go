@@ -0,0 +1,269 @@
+// Package result saves the config and statistics of each run into a results database,
+// either PostgreSQL/YMatrix or a local SQLite file, so that runs can be compared later.
+package result
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+	"github.com/ymatrix-data/mxbench/internal/util"
+	"github.com/ymatrix-data/mxbench/internal/util/log"
+	"github.com/ymatrix-data/mxbench/internal/util/mxerror"
+)
+
+const (
+	_SQLITE_PREFIX = "sqlite://"
+
+	_DRIVER_PGX    = "pgx"
+	_DRIVER_SQLITE = "sqlite3"
+
+	_RUN_ID_FMT = "20060102150405"
+)
+
+// The column types are understood by both PostgreSQL and SQLite.
+var _DDLS = []string{`
+CREATE TABLE IF NOT EXISTS mxbench_run (
+	run_id       text PRIMARY KEY,
+	start_at     text NOT NULL,
+	stop_at      text NOT NULL,
+	version      text,
+	command_line text,
+	generator    text,
+	writer       text,
+	benchmark    text,
+	config       text,
+	config_file  text
+)`, `
+CREATE TABLE IF NOT EXISTS mxbench_writer_result (
+	run_id         text NOT NULL,
+	writer         text,
+	start_time     text,
+	stop_time      text,
+	bytes          bigint,
+	lines          bigint,
+	compress_ratio double precision,
+	summary        text
+)`, `
+CREATE TABLE IF NOT EXISTS mxbench_query_result (
+	run_id              text NOT NULL,
+	seq                 integer NOT NULL,
+	query_name          text NOT NULL,
+	parallel            integer NOT NULL,
+	query_sql           text,
+	executions          bigint,
+	tps                 integer,
+	overall_duration_ns bigint,
+	avg_latency_ns      bigint,
+	stddev_latency_ns   bigint,
+	min_latency_ns      bigint,
+	max_latency_ns      bigint,
+	p25_latency_ns      bigint,
+	p50_latency_ns      bigint,
+	p75_latency_ns      bigint,
+	p90_latency_ns      bigint,
+	p95_latency_ns      bigint,
+	p99_latency_ns      bigint,
+	p999_latency_ns     bigint,
+	stat                text
+)`,
+}
+
+const (
+	_INSERT_RUN = `
+INSERT INTO mxbench_run (run_id, start_at, stop_at, version, command_line, generator, writer, benchmark, config, config_file)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_INSERT_WRITER_RESULT = `
+INSERT INTO mxbench_writer_result (run_id, writer, start_time, stop_time, bytes, lines, compress_ratio, summary)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_INSERT_QUERY_RESULT = `
+INSERT INTO mxbench_query_result (run_id, seq, query_name, parallel, query_sql, executions, tps, overall_duration_ns,
+	avg_latency_ns, stddev_latency_ns, min_latency_ns, max_latency_ns,
+	p25_latency_ns, p50_latency_ns, p75_latency_ns, p90_latency_ns, p95_latency_ns, p99_latency_ns, p999_latency_ns, stat)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+)
+
+type run struct {
+	RunID       string `db:"run_id"`
+	StartAt     string `db:"start_at"`
+	StopAt      string `db:"stop_at"`
+	Version     string `db:"version"`
+	CommandLine string `db:"command_line"`
+	Generator   string `db:"generator"`
+	Writer      string `db:"writer"`
+	Benchmark   string `db:"benchmark"`
+	Config      string `db:"config"`
+	ConfigFile  string `db:"config_file"`
+}
+
+type writerResult struct {
+	Writer        string  `db:"writer"`
+	StartTime     string  `db:"start_time"`
+	StopTime      string  `db:"stop_time"`
+	Bytes         int64   `db:"bytes"`
+	Lines         int64   `db:"lines"`
+	CompressRatio float64 `db:"compress_ratio"`
+	Summary       string  `db:"summary"`
+}
+
+type queryResult struct {
+	Seq           int    `db:"seq"`
+	QueryName     string `db:"query_name"`
+	Parallel      int    `db:"parallel"`
+	Executions    int64  `db:"executions"`
+	TPS           int    `db:"tps"`
+	AvgLatency    int64  `db:"avg_latency_ns"`
+	StdDevLatency int64  `db:"stddev_latency_ns"`
+	MinLatency    int64  `db:"min_latency_ns"`
+	MaxLatency    int64  `db:"max_latency_ns"`
+	P50Latency    int64  `db:"p50_latency_ns"`
+	P95Latency    int64  `db:"p95_latency_ns"`
+	P99Latency    int64  `db:"p99_latency_ns"`
+}
+
+// open connects to the results database and creates the result tables if absent.
+func open(url string) (*sqlx.DB, error) {
+	driver, dsn := _DRIVER_PGX, url
+	if strings.HasPrefix(url, _SQLITE_PREFIX) {
+		if !sqliteSupported {
+			return nil, mxerror.CommonError("this mxbench is built without cgo, SQLite results-db is not supported")
+		}
+		driver, dsn = _DRIVER_SQLITE, strings.TrimPrefix(url, _SQLITE_PREFIX)
+	}
+
+	db, err := sqlx.Connect(driver, dsn)
+	if err != nil {
+		return nil, mxerror.CommonErrorf("failed to connect to results-db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	for _, ddl := range _DDLS {
+		if _, err = db.Exec(ddl); err != nil {
+			_ = db.Close()
+			return nil, mxerror.CommonErrorf("failed to create result tables: %v", err)
+		}
+	}
+	return db, nil
+}
+
+func newRunID(startAt time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return startAt.Format(_RUN_ID_FMT) + "-" + hex.EncodeToString(b)
+}
+
+// Save is an engine.SaveResultFunc.
+// The config saved is what command config prints, in which only the
+// flags given in the command line are uncommented, so the content of
+// the config file given by --config is saved along with it.
+func Save(e *engine.Engine) (string, error) {
+	cfg := e.Config
+	db, err := open(cfg.GlobalCfg.ResultsDB)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	r := &run{
+		RunID:       newRunID(e.StartAt),
+		StartAt:     e.StartAt.Format(util.TIME_WITH_TZ_FMT),
+		StopAt:      time.Now().Format(util.TIME_WITH_TZ_FMT),
+		Version:     util.GetMxbenchVersion(),
+		CommandLine: strings.Join(os.Args, " "),
+		Generator:   cfg.GeneratorCfg.Plugin,
+		Writer:      cfg.WriterCfg.Plugin,
+		Benchmark:   cfg.BenchmarkCfg.Plugin,
+	}
+	if cfg.RenderConfig != nil {
+		if r.Config, err = cfg.RenderConfig(); err != nil {
+			log.Warn("Render config for results-db failed: %v", err)
+		}
+	}
+	if cfg.GlobalCfg.CfgFile != "" {
+		b, err := os.ReadFile(cfg.GlobalCfg.CfgFile)
+		if err != nil {
+			log.Warn("Read config file for results-db failed: %v", err)
+		}
+		r.ConfigFile = string(b)
+	}
+
+	w := newWriterResult(r.Writer, e.IWriter.GetStat())
+	var execBenchStats []*engine.ExecBenchStat
+	if stat := e.IBenchmark.GetStat(); stat != nil {
+		for _, ss := range stat.GetSubStats() {
+			if ebs, ok := ss.(*engine.ExecBenchStat); ok {
+				execBenchStats = append(execBenchStats, ebs)
+			}
+		}
+	}
+
+	return r.RunID, save(db, r, w, execBenchStats)
+}
+
+// newWriterResult returns the result of the writer of the stat, nil if the writer does not summarize what it has written.
+func newWriterResult(writer string, stat engine.Stat) *writerResult {
+	ws, ok := stat.(engine.WriterStat)
+	if !ok {
+		return nil
+	}
+	summary := ws.GetWriterSummary()
+	return &writerResult{
+		Writer:        writer,
+		StartTime:     summary.StartAt.Format(util.TIME_WITH_TZ_FMT),
+		StopTime:      summary.StopAt.Format(util.TIME_WITH_TZ_FMT),
+		Bytes:         summary.Bytes,
+		Lines:         summary.Lines,
+		CompressRatio: summary.CompressRatio,
+		Summary:       ws.GetFormattedSummary(),
+	}
+}
+
+func save(db *sqlx.DB, r *run, w *writerResult, execBenchStats []*engine.ExecBenchStat) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(db.Rebind(_INSERT_RUN), r.RunID, r.StartAt, r.StopAt, r.Version, r.CommandLine,
+		r.Generator, r.Writer, r.Benchmark, r.Config, r.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	if w != nil {
+		_, err = tx.Exec(db.Rebind(_INSERT_WRITER_RESULT), r.RunID, w.Writer, w.StartTime, w.StopTime,
+			w.Bytes, w.Lines, w.CompressRatio, w.Summary)
+		if err != nil {
+			return err
+		}
+	}
+
+	for seq, ebs := range execBenchStats {
+		if ebs.Histogram.Count() == 0 {
+			// not actually executed
+			continue
+		}
+		// it completes the statistics as well
+		stat := ebs.GetFormattedSummary()
+		var queryName, querySQL string
+		if q := ebs.GetQuery(); q != nil {
+			queryName, querySQL = q.GetName(), q.GetSQL()
+		}
+		_, err = tx.Exec(db.Rebind(_INSERT_QUERY_RESULT), r.RunID, seq, queryName, ebs.GetOption().Parallel, querySQL,
+			ebs.Histogram.Count(), ebs.TPS, ebs.TimeElapsed,
+			int64(ebs.AvgLatency), int64(ebs.StdDevLatency), int64(ebs.MinLatency), int64(ebs.MaxLatency),
+			int64(ebs.P25Latency), int64(ebs.P50Latency), int64(ebs.P75Latency), int64(ebs.P90Latency),
+			int64(ebs.P95Latency), int64(ebs.P99Latency), int64(ebs.P999Latency), stat)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
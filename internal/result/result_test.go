@@ -0,0 +1,106 @@
+package result
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/ymatrix-data/mxbench/internal/engine"
+)
+
+type query string
+
+func (q query) GetSQL() string  { return "SELECT 1" }
+func (q query) GetName() string { return string(q) }
+
+func newExecBenchStat(name string, parallel int, latency time.Duration) *engine.ExecBenchStat {
+	ebs := engine.NewExecBenchStat(engine.ExecBenchOption{Parallel: parallel}, query(name))
+	for i := 0; i < 100; i++ {
+		ebs.Histogram.Record(int64(latency))
+	}
+	ebs.TimeElapsed = int64(time.Second)
+	return ebs
+}
+
+// writerStat is a writer stat summarizing what it has written, whose other methods are not called
+type writerStat struct {
+	engine.Stat
+	summary engine.WriterSummary
+}
+
+func (s writerStat) GetWriterSummary() engine.WriterSummary { return s.summary }
+func (s writerStat) GetFormattedSummary() string            { return "formatted" }
+
+func newWriterStat(bytes, lines int64) writerStat {
+	startAt := time.Date(2022, 4, 25, 9, 0, 0, 0, time.UTC)
+	return writerStat{summary: engine.WriterSummary{
+		StartAt: startAt, StopAt: startAt.Add(3 * time.Minute), Bytes: bytes, Lines: lines, CompressRatio: 2.5,
+	}}
+}
+
+var _ = Describe("Result", func() {
+	It("should take the result of the writer from its summary", func() {
+		w := newWriterResult("http", newWriterStat(1024, 100))
+		Expect(w).To(Equal(&writerResult{
+			Writer:        "http",
+			StartTime:     "2022-04-25 09:00:00 +0000",
+			StopTime:      "2022-04-25 09:03:00 +0000",
+			Bytes:         1024,
+			Lines:         100,
+			CompressRatio: 2.5,
+			Summary:       "formatted",
+		}))
+
+		Expect(newWriterResult("nil", nil)).To(BeNil())
+	})
+
+	Context("SQLite results-db", func() {
+		if !sqliteSupported {
+			return
+		}
+
+		It("should save and compare runs", func() {
+			dir, err := os.MkdirTemp("", "mxbench-result")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			db, err := open(_SQLITE_PREFIX + filepath.Join(dir, "results.db"))
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+
+			runA := &run{RunID: "a", Writer: "http", Config: "[global]\n  tag-num = 100\n"}
+			err = save(db, runA, newWriterResult("http", newWriterStat(1000, 100)), []*engine.ExecBenchStat{
+				newExecBenchStat("SINGLE_TAG_LATEST_QUERY", 1, 10*time.Millisecond),
+				newExecBenchStat("MULTI_TAG_LATEST_QUERY", 1, 20*time.Millisecond),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			runB := &run{RunID: "b", Writer: "http", Config: "[global]\n  tag-num = 200\n"}
+			err = save(db, runB, newWriterResult("http", newWriterStat(2000, 200)), []*engine.ExecBenchStat{
+				newExecBenchStat("SINGLE_TAG_LATEST_QUERY", 1, 5*time.Millisecond),
+				engine.NewExecBenchStat(engine.ExecBenchOption{Parallel: 1}, query("NOT_EXECUTED")),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			queries, err := loadQueryResults(db, "b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queries).To(HaveLen(1))
+			Expect(queries[0].TPS).To(Equal(100))
+
+			report, err := compare(db, "a", "b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report).To(ContainSubstring("-  tag-num = 100"))
+			Expect(report).To(ContainSubstring("+  tag-num = 200"))
+			Expect(report).To(ContainSubstring("config file: identical"))
+			Expect(report).To(ContainSubstring("+100.00%"))
+			Expect(report).To(ContainSubstring("10.000ms -> 5.000ms (-50.00%)"))
+			Expect(report).To(ContainSubstring("only in A"))
+
+			_, err = compare(db, "a", "c")
+			Expect(err).To(MatchError("run c is not found in results-db"))
+		})
+	})
+})
@@ -0,0 +1,11 @@
+//go:build cgo
+// +build cgo
+
+package result
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The SQLite driver is built by cgo.
+const sqliteSupported = true
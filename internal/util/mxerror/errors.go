@@ -12,6 +12,8 @@ const (
 	ExitCodeSuccess ExitCode = iota
 	ExitCodeCommon
 	ExitCodeIncorrectUsage
+	// the run completes, but some of the thresholds are violated
+	ExitCodeThresholdViolated
 )
 
 type MxbenchError struct {
@@ -25,7 +27,7 @@ func (e *MxbenchError) Error() string {
 
 func (e *MxbenchError) OSExit(doBeforeExit ...func()) {
 	switch e.ExitCode {
-	case ExitCodeCommon, ExitCodeIncorrectUsage:
+	case ExitCodeCommon, ExitCodeIncorrectUsage, ExitCodeThresholdViolated:
 		fmt.Fprintln(os.Stderr, e.err)
 	}
 
@@ -68,6 +70,10 @@ func IncorrectUsageError(errMsg string) *MxbenchError {
 	return Error(ExitCodeIncorrectUsage, errMsg)
 }
 
+func ThresholdViolatedError(errMsg string) *MxbenchError {
+	return Error(ExitCodeThresholdViolated, errMsg)
+}
+
 func SuccessErrorf(format string, args ...interface{}) *MxbenchError {
 	return Errorf(ExitCodeSuccess, format, args...)
 }
@@ -79,3 +85,7 @@ func CommonErrorf(format string, args ...interface{}) *MxbenchError {
 func IncorrectUsageErrorf(format string, args ...interface{}) *MxbenchError {
 	return Errorf(ExitCodeIncorrectUsage, format, args...)
 }
+
+func ThresholdViolatedErrorf(format string, args ...interface{}) *MxbenchError {
+	return Errorf(ExitCodeThresholdViolated, format, args...)
+}
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"encoding/binary"
+	"hash/fnv"
 	"math/rand"
 	"sync"
 	"time"
@@ -14,6 +16,32 @@ var (
 	mu         sync.Mutex
 )
 
+// Seed reseeds the random strings and numbers below,
+// e.g. the VINs and plates generated by templates.
+func Seed(seed int64) {
+	mu.Lock()
+	seededRand = rand.New(rand.NewSource(seed))
+	mu.Unlock()
+}
+
+// DeriveSeed derives the seed of a random stream from seed by the name of the stream,
+// so that the streams drawn concurrently are reproducible independently.
+func DeriveSeed(seed int64, name string) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, seed)
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// NewRand returns a random source of the stream derived from seed by name,
+// or seeded by the clock if seed is 0.
+func NewRand(seed int64, name string) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(DeriveSeed(seed, name)))
+}
+
 func StringWithCharset(length int, charset string) string {
 	b := make([]byte, length)
 	for i := range b {
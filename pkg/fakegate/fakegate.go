@@ -0,0 +1,131 @@
+// Package fakegate provides an in-process stand-in of the http source of mxgate,
+// which accepts the data posted the same way as mxgate does, counting it instead of writing it into the database.
+//
+// Each post is a target table, e.g. public.t1, in the first line, followed by the lines of the data.
+// It is answered by 204 No Content once accepted, or by 500 with a message
+// telling the client to back off, as mxgate does when it is overloaded.
+package fakegate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultBackpressureMessage is what a post is answered with when it is backpressured, unless given
+const DefaultBackpressureMessage = "engine: cache maximum memory size exceeded"
+
+type Options struct {
+	// BackpressureEvery answers every n-th post with a backpressure error instead of accepting it, 0 never
+	BackpressureEvery int
+	// BackpressureMessage is the body of a backpressure error
+	BackpressureMessage string
+	// Latency delays the answer to each post, as if it took so long to write the data
+	Latency time.Duration
+}
+
+// Stats are what the fake has been posted
+type Stats struct {
+	Posts         int64
+	Backpressures int64
+	// Rows and Bytes accepted, where Bytes includes the lines of target tables
+	Rows  int64
+	Bytes int64
+	// Tables are the rows accepted of each target table
+	Tables map[string]int64
+}
+
+type Server struct {
+	opts     Options
+	listener net.Listener
+	server   *http.Server
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Start serves the fake at the address, e.g. 127.0.0.1:0 for a port picked by the system.
+func Start(addr string, opts Options) (*Server, error) {
+	if opts.BackpressureMessage == "" {
+		opts.BackpressureMessage = DefaultBackpressureMessage
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		opts:     opts,
+		listener: listener,
+		stats:    Stats{Tables: map[string]int64{}},
+	}
+	s.server = &http.Server{Handler: s}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// URL returns the url to post to, as given to writer-mxgate-url.
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats
+	stats.Tables = make(map[string]int64, len(s.stats.Tables))
+	for table, rows := range s.stats.Tables {
+		stats.Tables[table] = rows
+	}
+	return stats
+}
+
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if s.opts.Latency > 0 {
+		time.Sleep(s.opts.Latency)
+	}
+
+	table, data, _ := bytes.Cut(body, []byte("\n"))
+	table = bytes.TrimSpace(table)
+	if len(table) == 0 {
+		http.Error(w, "missing target table in the first line", http.StatusBadRequest)
+		return
+	}
+	rows := int64(bytes.Count(data, []byte("\n")))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		rows++
+	}
+
+	s.mu.Lock()
+	s.stats.Posts++
+	if n := int64(s.opts.BackpressureEvery); n > 0 && s.stats.Posts%n == 0 {
+		s.stats.Backpressures++
+		s.mu.Unlock()
+		http.Error(w, fmt.Sprintf("write failed: %s", s.opts.BackpressureMessage), http.StatusInternalServerError)
+		return
+	}
+	s.stats.Rows += rows
+	s.stats.Bytes += int64(len(body))
+	s.stats.Tables[string(table)] += rows
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,13 @@
+package fakegate
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFakegate(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fakegate Suite")
+}
@@ -0,0 +1,80 @@
+package fakegate
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func post(s *Server, body string) (int, string) {
+	resp, err := http.Post(s.URL(), "text/plain", strings.NewReader(body))
+	Expect(err).NotTo(HaveOccurred())
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	Expect(err).NotTo(HaveOccurred())
+	return resp.StatusCode, string(b)
+}
+
+var _ = Describe("Fakegate", func() {
+	var s *Server
+
+	AfterEach(func() {
+		Expect(s.Close()).To(Succeed())
+	})
+
+	It("should count the rows and bytes of each target table", func() {
+		var err error
+		s, err = Start("127.0.0.1:0", Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		code, _ := post(s, "public.t1\n1|a\n2|b\n")
+		Expect(code).To(Equal(http.StatusNoContent))
+		code, _ = post(s, "public.t2\n3|c")
+		Expect(code).To(Equal(http.StatusNoContent))
+
+		stats := s.Stats()
+		Expect(stats.Posts).To(Equal(int64(2)))
+		Expect(stats.Backpressures).To(BeZero())
+		Expect(stats.Rows).To(Equal(int64(3)))
+		Expect(stats.Bytes).To(Equal(int64(len("public.t1\n1|a\n2|b\n") + len("public.t2\n3|c"))))
+		Expect(stats.Tables).To(Equal(map[string]int64{"public.t1": 2, "public.t2": 1}))
+	})
+
+	It("should reject a post without target table or not posted", func() {
+		var err error
+		s, err = Start("127.0.0.1:0", Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		code, _ := post(s, "\n1|a\n")
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		resp, err := http.Get(s.URL())
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+		Expect(s.Stats().Posts).To(BeZero())
+	})
+
+	It("should backpressure every n-th post without counting its rows", func() {
+		var err error
+		s, err = Start("127.0.0.1:0", Options{BackpressureEvery: 2})
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 4; i++ {
+			code, body := post(s, "public.t1\n1|a\n")
+			if i%2 == 1 {
+				Expect(code).To(Equal(http.StatusInternalServerError))
+				Expect(body).To(ContainSubstring(DefaultBackpressureMessage))
+			} else {
+				Expect(code).To(Equal(http.StatusNoContent))
+			}
+		}
+		stats := s.Stats()
+		Expect(stats.Posts).To(Equal(int64(4)))
+		Expect(stats.Backpressures).To(Equal(int64(2)))
+		Expect(stats.Rows).To(Equal(int64(2)))
+	})
+})
@@ -0,0 +1,294 @@
+// Package histogram provides a mergeable histogram of high dynamic range.
+//
+// Values below 2048 are counted exactly, larger values are counted in buckets
+// that keep the 11 most significant bits, so that any value reported by
+// the histogram is within 0.1% of a recorded one, i.e. 3 significant decimal digits.
+// Recording is lock free and the memory is fixed by the highest trackable value.
+package histogram
+
+import (
+	"encoding/json"
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+const (
+	_SUB_BUCKET_BITS       = 11
+	_SUB_BUCKET_COUNT      = 1 << _SUB_BUCKET_BITS
+	_SUB_BUCKET_HALF_COUNT = _SUB_BUCKET_COUNT / 2
+)
+
+type Histogram struct {
+	// values greater than highest are counted as highest,
+	// while min, max and sum are still exact.
+	highest int64
+	counts  []int64
+
+	count, sum int64
+	min, max   int64
+}
+
+// New creates a histogram tracking values from 0 to highest.
+func New(highest int64) *Histogram {
+	if highest < _SUB_BUCKET_COUNT {
+		highest = _SUB_BUCKET_COUNT
+	}
+	return &Histogram{
+		highest: highest,
+		counts:  make([]int64, bucketIndex(highest)+1),
+		min:     math.MaxInt64,
+	}
+}
+
+func bucketIndex(v int64) int {
+	if v < _SUB_BUCKET_COUNT {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - _SUB_BUCKET_BITS
+	return _SUB_BUCKET_COUNT + (shift-1)*_SUB_BUCKET_HALF_COUNT + int(v>>shift) - _SUB_BUCKET_HALF_COUNT
+}
+
+// lowestEquivalentValue returns the smallest value counted in the bucket.
+func lowestEquivalentValue(index int) int64 {
+	if index < _SUB_BUCKET_COUNT {
+		return int64(index)
+	}
+	shift := (index-_SUB_BUCKET_COUNT)/_SUB_BUCKET_HALF_COUNT + 1
+	subBucket := (index-_SUB_BUCKET_COUNT)%_SUB_BUCKET_HALF_COUNT + _SUB_BUCKET_HALF_COUNT
+	return int64(subBucket) << shift
+}
+
+// highestEquivalentValue returns the largest value counted in the bucket.
+func highestEquivalentValue(index int) int64 {
+	if index < _SUB_BUCKET_COUNT {
+		return int64(index)
+	}
+	shift := (index-_SUB_BUCKET_COUNT)/_SUB_BUCKET_HALF_COUNT + 1
+	return lowestEquivalentValue(index) + int64(1)<<shift - 1
+}
+
+func medianEquivalentValue(index int) int64 {
+	low := lowestEquivalentValue(index)
+	return low + (highestEquivalentValue(index)-low)/2
+}
+
+// Record counts a value, negative values are counted as 0.
+// It is safe to be called concurrently.
+func (h *Histogram) Record(v int64) {
+	h.RecordN(v, 1)
+}
+
+// RecordN counts a value for n times.
+func (h *Histogram) RecordN(v, n int64) {
+	if n <= 0 {
+		return
+	}
+	if v < 0 {
+		v = 0
+	}
+	atomic.AddInt64(&h.count, n)
+	atomic.AddInt64(&h.sum, v*n)
+	for {
+		min := atomic.LoadInt64(&h.min)
+		if v >= min || atomic.CompareAndSwapInt64(&h.min, min, v) {
+			break
+		}
+	}
+	for {
+		max := atomic.LoadInt64(&h.max)
+		if v <= max || atomic.CompareAndSwapInt64(&h.max, max, v) {
+			break
+		}
+	}
+	if v > h.highest {
+		v = h.highest
+	}
+	atomic.AddInt64(&h.counts[bucketIndex(v)], n)
+}
+
+// Merge adds all the values counted by another histogram.
+func (h *Histogram) Merge(other *Histogram) {
+	for index := range other.counts {
+		if c := atomic.LoadInt64(&other.counts[index]); c > 0 {
+			v := lowestEquivalentValue(index)
+			if v > h.highest {
+				v = h.highest
+			}
+			atomic.AddInt64(&h.counts[bucketIndex(v)], c)
+		}
+	}
+	if other.Count() == 0 {
+		return
+	}
+	atomic.AddInt64(&h.count, other.Count())
+	atomic.AddInt64(&h.sum, atomic.LoadInt64(&other.sum))
+	for {
+		min, otherMin := atomic.LoadInt64(&h.min), other.Min()
+		if otherMin >= min || atomic.CompareAndSwapInt64(&h.min, min, otherMin) {
+			break
+		}
+	}
+	for {
+		max, otherMax := atomic.LoadInt64(&h.max), other.Max()
+		if otherMax <= max || atomic.CompareAndSwapInt64(&h.max, max, otherMax) {
+			break
+		}
+	}
+}
+
+func (h *Histogram) Count() int64 {
+	return atomic.LoadInt64(&h.count)
+}
+
+// Sum returns the exact sum of the recorded values.
+func (h *Histogram) Sum() int64 {
+	return atomic.LoadInt64(&h.sum)
+}
+
+// CountAtOrBelow returns the number of recorded values not greater than v,
+// values within the precision of v are counted as well.
+func (h *Histogram) CountAtOrBelow(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	if v > h.highest {
+		v = h.highest
+	}
+	var total int64
+	for index := 0; index <= bucketIndex(v); index++ {
+		total += atomic.LoadInt64(&h.counts[index])
+	}
+	return total
+}
+
+// Min returns the exact minimum, 0 if nothing has been recorded.
+func (h *Histogram) Min() int64 {
+	if h.Count() == 0 {
+		return 0
+	}
+	return atomic.LoadInt64(&h.min)
+}
+
+// Max returns the exact maximum.
+func (h *Histogram) Max() int64 {
+	return atomic.LoadInt64(&h.max)
+}
+
+// Mean returns the exact average.
+func (h *Histogram) Mean() float64 {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&h.sum)) / float64(count)
+}
+
+// StdDev returns the standard deviation,
+// estimated by the middle value of the buckets.
+func (h *Histogram) StdDev() float64 {
+	count := h.Count()
+	if count == 0 {
+		return 0
+	}
+	mean := h.Mean()
+	var deviations float64
+	var total int64
+	for index := range h.counts {
+		if c := atomic.LoadInt64(&h.counts[index]); c > 0 {
+			d := float64(medianEquivalentValue(index)) - mean
+			deviations += d * d * float64(c)
+			total += c
+		}
+	}
+	return math.Sqrt(deviations / float64(total))
+}
+
+// ValueAtPercentile returns the value at index floor(n*percentile/100)
+// of the n recorded values in ascending order,
+// which is the highest value of its bucket, never beyond the exact min and max.
+func (h *Histogram) ValueAtPercentile(percentile float64) int64 {
+	counts := make([]int64, len(h.counts))
+	var total int64
+	for index := range h.counts {
+		counts[index] = atomic.LoadInt64(&h.counts[index])
+		total += counts[index]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := int64(float64(total)*percentile/100) + 1
+	if rank > total {
+		rank = total
+	}
+	var acc int64
+	for index, c := range counts {
+		acc += c
+		if acc < rank {
+			continue
+		}
+		v := highestEquivalentValue(index)
+		if max := h.Max(); v > max {
+			v = max
+		}
+		if min := h.Min(); v < min {
+			v = min
+		}
+		return v
+	}
+	return h.Max()
+}
+
+type snapshot struct {
+	Highest int64 `json:"highest"`
+	Count   int64 `json:"count"`
+	Sum     int64 `json:"sum"`
+	Min     int64 `json:"min"`
+	Max     int64 `json:"max"`
+	// Counts are pairs of the lowest value of a bucket and the count of it,
+	// empty buckets are left out.
+	Counts [][2]int64 `json:"counts"`
+}
+
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	s := snapshot{
+		Highest: h.highest,
+		Count:   h.Count(),
+		Sum:     atomic.LoadInt64(&h.sum),
+		Min:     h.Min(),
+		Max:     h.Max(),
+		Counts:  make([][2]int64, 0),
+	}
+	for index := range h.counts {
+		if c := atomic.LoadInt64(&h.counts[index]); c > 0 {
+			s.Counts = append(s.Counts, [2]int64{lowestEquivalentValue(index), c})
+		}
+	}
+	return json.Marshal(&s)
+}
+
+// UnmarshalJSON restores a histogram exported by MarshalJSON,
+// so that histograms of different runs could be merged offline.
+func (h *Histogram) UnmarshalJSON(b []byte) error {
+	var s snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*h = *New(s.Highest)
+	for _, pair := range s.Counts {
+		v := pair[0]
+		if v > h.highest {
+			v = h.highest
+		}
+		if v < 0 {
+			v = 0
+		}
+		h.counts[bucketIndex(v)] += pair[1]
+	}
+	h.count, h.sum, h.max = s.Count, s.Sum, s.Max
+	if s.Count > 0 {
+		h.min = s.Min
+	}
+	return nil
+}
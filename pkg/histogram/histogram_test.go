@@ -0,0 +1,147 @@
+package histogram
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Histogram", func() {
+	Context("buckets", func() {
+		It("should map values to contiguous buckets", func() {
+			for _, v := range []int64{0, 1, 2047, 2048, 2049, 4095, 4096, 1 << 30, 1<<40 + 12345} {
+				index := bucketIndex(v)
+				Expect(lowestEquivalentValue(index)).To(BeNumerically("<=", v))
+				Expect(highestEquivalentValue(index)).To(BeNumerically(">=", v))
+				Expect(bucketIndex(highestEquivalentValue(index) + 1)).To(Equal(index + 1))
+			}
+		})
+
+		It("should keep 3 significant digits", func() {
+			for _, v := range []int64{3000, 123456, 987654321, 3600000000000} {
+				index := bucketIndex(v)
+				width := highestEquivalentValue(index) - lowestEquivalentValue(index)
+				Expect(float64(width) / float64(v)).To(BeNumerically("<", 0.001))
+			}
+		})
+	})
+
+	Context("statistics", func() {
+		It("should be zero when empty", func() {
+			h := New(1000000)
+			Expect(h.Count()).To(Equal(int64(0)))
+			Expect(h.Min()).To(Equal(int64(0)))
+			Expect(h.Max()).To(Equal(int64(0)))
+			Expect(h.ValueAtPercentile(99)).To(Equal(int64(0)))
+			Expect(h.StdDev()).To(Equal(float64(0)))
+		})
+
+		It("should report percentiles of small values exactly", func() {
+			h := New(1000000)
+			for v := int64(1); v <= 1000; v++ {
+				h.Record(v)
+			}
+			Expect(h.Count()).To(Equal(int64(1000)))
+			Expect(h.Min()).To(Equal(int64(1)))
+			Expect(h.Max()).To(Equal(int64(1000)))
+			Expect(h.Mean()).To(Equal(500.5))
+			Expect(h.ValueAtPercentile(25)).To(Equal(int64(251)))
+			Expect(h.ValueAtPercentile(50)).To(Equal(int64(501)))
+			Expect(h.ValueAtPercentile(99)).To(Equal(int64(991)))
+			Expect(h.ValueAtPercentile(99.9)).To(Equal(int64(1000)))
+			Expect(h.ValueAtPercentile(100)).To(Equal(int64(1000)))
+			Expect(h.StdDev()).To(BeNumerically("~", math.Sqrt((1000*1000-1)/12.0), 0.01))
+		})
+
+		It("should report percentiles of large values within precision", func() {
+			h := New(1 << 40)
+			for v := int64(1); v <= 10000; v++ {
+				h.Record(v * 1000000)
+			}
+			Expect(h.Min()).To(Equal(int64(1000000)))
+			Expect(h.Max()).To(Equal(int64(10000000000)))
+			Expect(h.ValueAtPercentile(90)).To(BeNumerically("~", 9001000000, 9001000))
+			Expect(h.ValueAtPercentile(99)).To(BeNumerically("~", 9901000000, 9901000))
+		})
+
+		It("should count values at or below a value", func() {
+			h := New(1 << 40)
+			for v := int64(1); v <= 10000; v++ {
+				h.Record(v * 1000000)
+			}
+			Expect(h.Sum()).To(Equal(int64(10000*10001/2) * 1000000))
+			Expect(h.CountAtOrBelow(-1)).To(Equal(int64(0)))
+			Expect(h.CountAtOrBelow(990000)).To(Equal(int64(0)))
+			Expect(h.CountAtOrBelow(1000000)).To(Equal(int64(1)))
+			Expect(h.CountAtOrBelow(5000000000)).To(BeNumerically("~", 5000, 5))
+			Expect(h.CountAtOrBelow(1 << 50)).To(Equal(int64(10000)))
+		})
+
+		It("should count values beyond the highest as the highest", func() {
+			h := New(1000000)
+			h.Record(5000000)
+			h.Record(-1)
+			Expect(h.Min()).To(Equal(int64(0)))
+			Expect(h.Max()).To(Equal(int64(5000000)))
+			Expect(h.ValueAtPercentile(100)).To(BeNumerically("~", 1000000, 1000))
+		})
+
+		It("should be recorded concurrently", func() {
+			h := New(1000000)
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for v := int64(1); v <= 1000; v++ {
+						h.Record(v)
+					}
+				}()
+			}
+			wg.Wait()
+			Expect(h.Count()).To(Equal(int64(8000)))
+			Expect(h.ValueAtPercentile(50)).To(Equal(int64(501)))
+		})
+	})
+
+	Context("merging", func() {
+		It("should merge histograms", func() {
+			a, b := New(1000000), New(1<<40)
+			for v := int64(1); v <= 500; v++ {
+				a.Record(v)
+			}
+			for v := int64(501); v <= 1000; v++ {
+				b.Record(v)
+			}
+			b.Merge(a)
+			Expect(b.Count()).To(Equal(int64(1000)))
+			Expect(b.Min()).To(Equal(int64(1)))
+			Expect(b.Max()).To(Equal(int64(1000)))
+			Expect(b.ValueAtPercentile(50)).To(Equal(int64(501)))
+		})
+
+		It("should merge histograms restored from JSON", func() {
+			h := New(1 << 40)
+			for v := int64(1); v <= 10000; v++ {
+				h.Record(v * 1000)
+			}
+			b, err := json.Marshal(h)
+			Expect(err).NotTo(HaveOccurred())
+
+			restored := &Histogram{}
+			Expect(json.Unmarshal(b, restored)).To(Succeed())
+			Expect(restored.Count()).To(Equal(h.Count()))
+			Expect(restored.Min()).To(Equal(h.Min()))
+			Expect(restored.Max()).To(Equal(h.Max()))
+			Expect(restored.Mean()).To(Equal(h.Mean()))
+			Expect(restored.ValueAtPercentile(99.9)).To(Equal(h.ValueAtPercentile(99.9)))
+
+			restored.Merge(h)
+			Expect(restored.Count()).To(Equal(2 * h.Count()))
+			Expect(restored.ValueAtPercentile(50)).To(Equal(h.ValueAtPercentile(50)))
+		})
+	})
+})
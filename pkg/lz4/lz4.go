@@ -0,0 +1,249 @@
+// Package lz4 decompresses the LZ4 frame format.
+//
+// Frames may be concatenated or skippable, with blocks linked or independent.
+// The block and content checksums are verified, while the header checksum is skipped,
+// and the legacy format and dictionaries are not supported.
+package lz4
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	_MAGIC                = 0x184D2204
+	_SKIPPABLE_MAGIC_MASK = 0xFFFFFFF0
+	_SKIPPABLE_MAGIC      = 0x184D2A50
+	// _WINDOW_SIZE is how far a match of a linked block may refer back
+	_WINDOW_SIZE = 64 * 1024
+)
+
+var ErrCorrupted = errors.New("lz4: corrupted data")
+
+type Reader struct {
+	r *bufio.Reader
+
+	inFrame         bool
+	independent     bool
+	blockChecksum   bool
+	contentChecksum bool
+	maxBlockSize    int
+	// checksum is the digest of the content of the frame so far
+	checksum xxh32
+
+	// out is the window of the previous blocks followed by the current one,
+	// which is read from pos
+	out []byte
+	pos int
+}
+
+// NewReader returns a reader decompressing the LZ4 frames from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+func (z *Reader) Read(p []byte) (int, error) {
+	for z.pos >= len(z.out) {
+		if err := z.nextBlock(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, z.out[z.pos:])
+	z.pos += n
+	return n, nil
+}
+
+func (z *Reader) nextBlock() error {
+	for !z.inFrame {
+		if err := z.readFrameHeader(); err != nil {
+			return err
+		}
+	}
+
+	size, err := z.readUint32()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if size == 0 {
+		// end of the frame
+		z.inFrame = false
+		if !z.contentChecksum {
+			return nil
+		}
+		checksum, err := z.readUint32()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if checksum != z.checksum.sum() {
+			return ErrCorrupted
+		}
+		return nil
+	}
+	isCompressed := size&0x80000000 == 0
+	size &= 0x7FFFFFFF
+	if int(size) > z.maxBlockSize {
+		return ErrCorrupted
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(z.r, data); err != nil {
+		return unexpectedEOF(err)
+	}
+	if z.blockChecksum {
+		checksum, err := z.readUint32()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if checksum != xxh32Sum(data) {
+			return ErrCorrupted
+		}
+	}
+
+	if z.independent {
+		z.out = z.out[:0]
+	} else if len(z.out) > _WINDOW_SIZE {
+		z.out = z.out[:copy(z.out, z.out[len(z.out)-_WINDOW_SIZE:])]
+	}
+	z.pos = len(z.out)
+	if !isCompressed {
+		z.out = append(z.out, data...)
+	} else if z.out, err = decodeBlock(z.out, data); err != nil {
+		return err
+	}
+	if z.contentChecksum {
+		z.checksum.write(z.out[z.pos:])
+	}
+	return nil
+}
+
+func (z *Reader) readFrameHeader() error {
+	magic, err := z.readUint32()
+	if err != nil {
+		// io.EOF between frames is the end
+		return err
+	}
+	if magic&_SKIPPABLE_MAGIC_MASK == _SKIPPABLE_MAGIC {
+		size, err := z.readUint32()
+		if err == nil {
+			_, err = z.r.Discard(int(size))
+		}
+		return unexpectedEOF(err)
+	}
+	if magic != _MAGIC {
+		return fmt.Errorf("lz4: invalid magic number %#x", magic)
+	}
+
+	var descriptor [2]byte
+	if _, err = io.ReadFull(z.r, descriptor[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	flg, bd := descriptor[0], descriptor[1]
+	if flg>>6 != 1 {
+		return fmt.Errorf("lz4: unsupported version %d", flg>>6)
+	}
+	if flg&1 != 0 {
+		return errors.New("lz4: dictionaries are not supported")
+	}
+	z.independent = flg&0x20 != 0
+	z.blockChecksum = flg&0x10 != 0
+	z.contentChecksum = flg&0x04 != 0
+	blockSizeID := bd >> 4 & 0x07
+	if blockSizeID < 4 {
+		return ErrCorrupted
+	}
+	z.maxBlockSize = 1 << (8 + 2*blockSizeID)
+
+	// the content size and the header checksum
+	skip := 1
+	if flg&0x08 != 0 {
+		skip += 8
+	}
+	if _, err = z.r.Discard(skip); err != nil {
+		return unexpectedEOF(err)
+	}
+	z.inFrame = true
+	z.checksum.reset()
+	z.out = z.out[:0]
+	z.pos = 0
+	return nil
+}
+
+func (z *Reader) readUint32() (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(z.r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decodeBlock appends the block decompressed to dst,
+// whose content is the window that the matches may refer to.
+func decodeBlock(dst, src []byte) ([]byte, error) {
+	i := 0
+	for i < len(src) {
+		token := src[i]
+		i++
+
+		litLen, n := readLength(src[i:], int(token>>4))
+		if n < 0 {
+			return nil, ErrCorrupted
+		}
+		i += n
+		if i+litLen > len(src) {
+			return nil, ErrCorrupted
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+		if i == len(src) {
+			// the last sequence has literals only
+			break
+		}
+
+		if i+2 > len(src) {
+			return nil, ErrCorrupted
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		matchLen, n := readLength(src[i:], int(token&0x0F))
+		if n < 0 || offset == 0 || offset > len(dst) {
+			return nil, ErrCorrupted
+		}
+		i += n
+		matchLen += 4
+
+		start := len(dst) - offset
+		if offset >= matchLen {
+			dst = append(dst, dst[start:start+matchLen]...)
+			continue
+		}
+		// the match overlaps itself, repeating the last offset bytes
+		for k := 0; k < matchLen; k++ {
+			dst = append(dst, dst[start+k])
+		}
+	}
+	return dst, nil
+}
+
+// readLength reads the extra bytes of a length of 15 in the token,
+// returning the length and the number of bytes read, -1 if src ends.
+func readLength(src []byte, length int) (int, int) {
+	if length != 15 {
+		return length, 0
+	}
+	for i, b := range src {
+		length += int(b)
+		if b != 255 {
+			return length, i + 1
+		}
+	}
+	return 0, -1
+}
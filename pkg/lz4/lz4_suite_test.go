@@ -0,0 +1,13 @@
+package lz4
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLZ4(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "LZ4 Suite")
+}
@@ -0,0 +1,138 @@
+package lz4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// frame builds a frame of the blocks with their checksums, whose header checksum is not verified
+func frame(flg byte, blocks ...[]byte) []byte {
+	buf := []byte{0x04, 0x22, 0x4D, 0x18, flg, 0x40, 0}
+	for _, block := range blocks {
+		buf = append(buf, block...)
+		if flg&0x10 != 0 {
+			buf = binary.LittleEndian.AppendUint32(buf, xxh32Sum(block[4:]))
+		}
+	}
+	buf = append(buf, 0, 0, 0, 0)
+	if flg&0x04 != 0 {
+		content, _ := decompress(frame(flg&^0x04, blocks...))
+		buf = binary.LittleEndian.AppendUint32(buf, xxh32Sum([]byte(content)))
+	}
+	return buf
+}
+
+func block(data []byte, isCompressed bool) []byte {
+	size := uint32(len(data))
+	if !isCompressed {
+		size |= 0x80000000
+	}
+	return append(binary.LittleEndian.AppendUint32(nil, size), data...)
+}
+
+func decompress(data []byte) (string, error) {
+	out, err := io.ReadAll(NewReader(bytes.NewReader(data)))
+	return string(out), err
+}
+
+var _ = Describe("Reader", func() {
+	// "abc" and a match of 9 bytes overlapping itself, then "\n"
+	overlapped := []byte{0x35, 'a', 'b', 'c', 3, 0, 0x10, '\n'}
+	// 20 literals with an extra length byte
+	long := append([]byte{0xF0, 5}, "0123456789abcdefghi\n"...)
+
+	It("should decompress the blocks of frames", func() {
+		out, err := decompress(frame(0x60, block(overlapped, true), block([]byte("xyz\n"), false), block(long, true)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("abcabcabcabc\nxyz\n0123456789abcdefghi\n"))
+
+		// concatenated with checksums and a skippable frame in between
+		data := frame(0x74, block(overlapped, true))
+		data = append(data, 0x50, 0x2A, 0x4D, 0x18, 2, 0, 0, 0, 0xff, 0xff)
+		data = append(data, frame(0x60, block(long, true))...)
+		out, err = decompress(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("abcabcabcabc\n0123456789abcdefghi\n"))
+	})
+
+	It("should hash by xxHash32", func() {
+		Expect(xxh32Sum(nil)).To(Equal(uint32(0x02CC5D05)))
+		Expect(xxh32Sum([]byte("a"))).To(Equal(uint32(0x550D7456)))
+		Expect(xxh32Sum([]byte("abc"))).To(Equal(uint32(0x32D153FF)))
+
+		// written in pieces across the stripes of 16 bytes
+		data := bytes.Repeat([]byte("0123456789abcdefghi\n"), 10)
+		var d xxh32
+		d.reset()
+		for _, n := range []int{3, 20, 1, 16, 50} {
+			d.write(data[:n])
+			data = data[n:]
+		}
+		d.write(data)
+		Expect(d.sum()).To(Equal(xxh32Sum(bytes.Repeat([]byte("0123456789abcdefghi\n"), 10))))
+	})
+
+	It("should verify the checksums of the frames compressed by lz4", func() {
+		// by "lz4 -c", with the content checksum, and by "lz4 -c -BX", also with the block checksums
+		for _, data := range [][]byte{
+			{
+				0x04, 0x22, 0x4d, 0x18, 0x64, 0x40, 0xa7, 0x12, 0x00, 0x00, 0x00, 0x8f, 0x6d, 0x78, 0x62, 0x65,
+				0x6e, 0x63, 0x68, 0x2c, 0x08, 0x00, 0x00, 0x50, 0x65, 0x6e, 0x63, 0x68, 0x0a, 0x00, 0x00, 0x00,
+				0x00, 0xb3, 0x8e, 0x35, 0x54,
+			},
+			{
+				0x04, 0x22, 0x4d, 0x18, 0x74, 0x40, 0xbd, 0x12, 0x00, 0x00, 0x00, 0x8f, 0x6d, 0x78, 0x62, 0x65,
+				0x6e, 0x63, 0x68, 0x2c, 0x08, 0x00, 0x00, 0x50, 0x65, 0x6e, 0x63, 0x68, 0x0a, 0xd6, 0xfe, 0xc6,
+				0x1e, 0x00, 0x00, 0x00, 0x00, 0xb3, 0x8e, 0x35, 0x54,
+			},
+		} {
+			out, err := decompress(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(Equal("mxbench,mxbench,mxbench,mxbench\n"))
+
+			// a bit flipped in a literal
+			data[13] ^= 0x01
+			_, err = decompress(data)
+			Expect(err).To(MatchError(ErrCorrupted))
+		}
+	})
+
+	It("should refer to the previous blocks of linked blocks", func() {
+		// a match of 8 bytes 13 bytes back, in the previous block
+		linked := []byte{0x04, 13, 0}
+		out, err := decompress(frame(0x40, block(overlapped, true), block(linked, true)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("abcabcabcabc\nabcabcab"))
+
+		_, err = decompress(frame(0x60, block(overlapped, true), block(linked, true)))
+		Expect(err).To(MatchError(ErrCorrupted))
+
+		// the window slides over 64KB
+		first, second := make([]byte, 40000), make([]byte, 40000)
+		for i := range first {
+			first[i], second[i] = byte(i%251), byte(i%241)
+		}
+		content := append(append([]byte(nil), first...), second...)
+		out, err = decompress(frame(0x40, block(first, false), block(second, false), block([]byte{0x04, 0xFF, 0xFF}, true)))
+		Expect(err).NotTo(HaveOccurred())
+		start := len(content) - 0xFFFF
+		Expect(out).To(Equal(string(content) + string(content[start:start+8])))
+	})
+
+	It("should fail on corrupted data", func() {
+		for _, data := range [][]byte{
+			[]byte("not lz4 data"),
+			frame(0x60, block([]byte{0x35, 'a', 'b', 'c', 9, 0}, true)),
+			frame(0x60, block([]byte{0xF0, 255}, true)),
+			frame(0x60, block(overlapped, true))[:12],
+			frame(0x61),
+		} {
+			_, err := decompress(data)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
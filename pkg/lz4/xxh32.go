@@ -0,0 +1,94 @@
+package lz4
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	_PRIME32_1 = 2654435761
+	_PRIME32_2 = 2246822519
+	_PRIME32_3 = 3266489917
+	_PRIME32_4 = 668265263
+	_PRIME32_5 = 374761393
+)
+
+// xxh32 is the digest of xxHash32 with the seed 0, of which the checksums of LZ4 frames are
+type xxh32 struct {
+	v     [4]uint32
+	buf   [16]byte
+	n     int
+	total uint64
+}
+
+func (d *xxh32) reset() {
+	var prime1, prime2 uint32 = _PRIME32_1, _PRIME32_2
+	*d = xxh32{v: [4]uint32{prime1 + prime2, prime2, 0, -prime1}}
+}
+
+func (d *xxh32) write(p []byte) {
+	d.total += uint64(len(p))
+	if d.n > 0 {
+		n := copy(d.buf[d.n:], p)
+		d.n += n
+		p = p[n:]
+		if d.n < len(d.buf) {
+			return
+		}
+		d.rounds(d.buf[:])
+		d.n = 0
+	}
+	full := len(p) &^ 15
+	d.rounds(p[:full])
+	d.n = copy(d.buf[:], p[full:])
+}
+
+// rounds consumes the stripes of 16 bytes of p, whose length is a multiple of 16
+func (d *xxh32) rounds(p []byte) {
+	for ; len(p) >= 16; p = p[16:] {
+		for i := range d.v {
+			d.v[i] = round(d.v[i], binary.LittleEndian.Uint32(p[4*i:]))
+		}
+	}
+}
+
+func (d *xxh32) sum() uint32 {
+	var h uint32
+	if d.total >= 16 {
+		h = bits.RotateLeft32(d.v[0], 1) + bits.RotateLeft32(d.v[1], 7) +
+			bits.RotateLeft32(d.v[2], 12) + bits.RotateLeft32(d.v[3], 18)
+	} else {
+		h = _PRIME32_5
+	}
+	h += uint32(d.total)
+
+	p := d.buf[:d.n]
+	for ; len(p) >= 4; p = p[4:] {
+		h += binary.LittleEndian.Uint32(p) * _PRIME32_3
+		h = bits.RotateLeft32(h, 17) * _PRIME32_4
+	}
+	for _, b := range p {
+		h += uint32(b) * _PRIME32_5
+		h = bits.RotateLeft32(h, 11) * _PRIME32_1
+	}
+
+	h ^= h >> 15
+	h *= _PRIME32_2
+	h ^= h >> 13
+	h *= _PRIME32_3
+	h ^= h >> 16
+	return h
+}
+
+func round(acc, input uint32) uint32 {
+	acc += input * _PRIME32_2
+	return bits.RotateLeft32(acc, 13) * _PRIME32_1
+}
+
+// xxh32Sum returns the xxHash32 of p
+func xxh32Sum(p []byte) uint32 {
+	var d xxh32
+	d.reset()
+	d.write(p)
+	return d.sum()
+}
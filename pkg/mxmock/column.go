@@ -3,6 +3,7 @@ package mxmock
 import (
 	"fmt"
 
+	"github.com/brianvoe/gofakeit/v6"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -16,7 +17,8 @@ type Column struct {
 	typ      Type
 }
 
-func (c *Column) initTyp(conn *sqlx.DB) error {
+// initTyp creates the type of the column, drawing its values from faker
+func (c *Column) initTyp(conn *sqlx.DB, faker *gofakeit.Faker) error {
 	var err error
 	var enum Type
 	initFunc := func() {
@@ -31,13 +33,16 @@ func (c *Column) initTyp(conn *sqlx.DB) error {
 			return
 		}
 		// If type not defined in TypMap, means the type is enum
-		enum, err = NewEnum(c.TypeName, conn)
+		enum, err = NewEnum(c.Name, c.TypeName, conn)
 		if err != nil {
 			return
 		}
 		c.typ = enum
 	}
 	initFunc()
+	if s, ok := c.typ.(fakerSetter); ok && err == nil {
+		s.setFaker(faker)
+	}
 	return err
 }
 
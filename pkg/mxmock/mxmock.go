@@ -3,6 +3,7 @@ package mxmock
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -19,15 +20,33 @@ type MXMocker struct {
 	keyMap                  map[string]bool
 	RowCh                   chan []string
 	fakeAutoIncrementColumn bool
+	rnd                     *rand.Rand
+	// faker draws the values of the columns, seeded by rnd
+	faker *gofakeit.Faker
+	// keyNames are the sorted keys of keyMap
+	keyNames []string
 }
 
 func NewMXMockerFromColumns(columns []*Column) (*MXMocker, error) {
-	faker := gofakeit.New(time.Now().UnixNano())
-	gofakeit.SetGlobalFaker(faker)
+	return NewMXMockerFromColumnsWithRand(columns, rand.New(rand.NewSource(time.Now().UnixNano())))
+}
+
+// NewMXMockerFromColumnsWithRand creates a mocker drawing from rnd,
+// which also seeds the faker of the mocker, so that the mocked rows are reproducible.
+// rnd is not safe for concurrent use, neither is the mocker.
+func NewMXMockerFromColumnsWithRand(columns []*Column, rnd *rand.Rand) (*MXMocker, error) {
+	return NewMXMockerFromColumnsWithConn(nil, columns, rnd)
+}
 
+// NewMXMockerFromColumnsWithConn creates a mocker as NewMXMockerFromColumnsWithRand does,
+// reading the labels of the enum types of the columns by conn.
+func NewMXMockerFromColumnsWithConn(conn *sqlx.DB, columns []*Column, rnd *rand.Rand) (*MXMocker, error) {
 	mocker := &MXMocker{
+		conn:    conn,
 		columns: columns,
 		RowCh:   make(chan []string, 1),
+		rnd:     rnd,
+		faker:   gofakeit.New(rnd.Int63()),
 	}
 
 	err := mocker.init()
@@ -48,7 +67,7 @@ func (m *MXMocker) init() error {
 		m.keyMap = map[string]bool{}
 	}
 	for _, c := range m.columns {
-		err := c.initTyp(m.conn)
+		err := c.initTyp(m.conn, m.faker)
 		if err != nil {
 			return err
 		}
@@ -87,6 +106,19 @@ func (m *MXMocker) ExcludeColumn(names ...string) {
 	for key := range nMap {
 		delete(m.keyMap, key)
 	}
+	m.keyNames = nil
+}
+
+// sortedKeyNames returns the keys in order, as the order of ranging over a map is random
+func (m *MXMocker) sortedKeyNames() []string {
+	if m.keyNames == nil {
+		m.keyNames = make([]string, 0, len(m.keyMap))
+		for keyName := range m.keyMap {
+			m.keyNames = append(m.keyNames, keyName)
+		}
+		sort.Strings(m.keyNames)
+	}
+	return m.keyNames
 }
 
 func NewColumnsFromDB(conn *sqlx.DB, schema, table string) ([]*Column, error) {
@@ -204,11 +236,8 @@ func (m *MXMocker) MockBatchWithTotalValues(lines, totalValues int) ([][]string,
 	}
 
 	// Generate sampled column indexes/names
-	keyNames := make([]string, 0, len(m.keyMap))
-	for keyName := range m.keyMap {
-		keyNames = append(keyNames, keyName)
-	}
-	rand.Shuffle(len(keyNames), func(i, j int) { keyNames[i], keyNames[j] = keyNames[j], keyNames[i] })
+	keyNames := append(make([]string, 0, len(m.keyMap)), m.sortedKeyNames()...)
+	m.rnd.Shuffle(len(keyNames), func(i, j int) { keyNames[i], keyNames[j] = keyNames[j], keyNames[i] })
 	keyNames = keyNames[:totalValues]
 
 	result := [][]string{}
@@ -2,6 +2,7 @@ package mxmock
 
 import (
 	"fmt"
+	"math/rand"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -461,5 +462,27 @@ var _ = Describe("Mxmock", func() {
 				fmt.Println(idx, ":", column)
 			}
 		})
+
+		It("should mock the same rows by the same seed, whatever the other mockers draw", func() {
+			newMocker := func(seed int64) *MXMocker {
+				mocker, err := NewMXMockerFromColumnsWithRand([]*Column{
+					{Name: "c1", TypeName: "uuid"},
+					{Name: "c2", TypeName: "_uuid"},
+					{Name: "c3", TypeName: "bool"},
+					{Name: "c4", TypeName: "_int4", TypeDesc: "integer[]"},
+					{Name: "c5", TypeName: "text", TypeDesc: "text"},
+				}, rand.New(rand.NewSource(seed)))
+				Expect(err).NotTo(HaveOccurred())
+				return mocker
+			}
+			m1 := newMocker(1)
+			other := newMocker(2)
+			rows := m1.Mock(3)
+			other.Mock(3)
+			rows = append(rows, m1.Mock(3)...)
+
+			m2 := newMocker(1)
+			Expect(m2.Mock(6)).To(Equal(rows))
+		})
 	})
 })
@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/brianvoe/gofakeit/v6"
 )
 
 type Type interface {
@@ -21,6 +23,16 @@ type Type interface {
 
 type BaseType struct {
 	colName string
+	// faker is of the mocker creating the type, nil if the type is created alone
+	faker *gofakeit.Faker
+}
+
+// defaultFaker draws the values of the types not created by a mocker
+var defaultFaker = gofakeit.New(0)
+
+// fakerSetter is implemented by the types drawing their values from a faker, i.e. those embedding BaseType
+type fakerSetter interface {
+	setFaker(f *gofakeit.Faker)
 }
 
 type ValueRange struct {
@@ -47,6 +59,18 @@ func (t *BaseType) GetColName() string {
 	return t.colName
 }
 
+// Faker returns the faker to draw the random values of the type from
+func (t *BaseType) Faker() *gofakeit.Faker {
+	if t.faker == nil {
+		return defaultFaker
+	}
+	return t.faker
+}
+
+func (t *BaseType) setFaker(f *gofakeit.Faker) {
+	t.faker = f
+}
+
 // Extract Float precision from the float typedesc
 func (t *BaseType) FloatPrecision(td string) (int, int, error) {
 	if !t.BracketsExists(td) {
@@ -24,7 +24,7 @@ func (b *Bit) Random(keys ...string) string {
 		}
 		var bitValue string
 		for i := 0; i < b.Length; i++ {
-			if gofakeit.Bool() {
+			if b.Faker().Bool() {
 				bitValue = bitValue + "1"
 			} else {
 				bitValue = bitValue + "0"
@@ -79,6 +79,14 @@ func (b *Bits) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (b *Bits) setFaker(f *gofakeit.Faker) {
+	b.BaseType.setFaker(f)
+	for _, e := range b.bits {
+		e.setFaker(f)
+	}
+}
+
 type VarBit = Bit
 
 type VarBits = Bits
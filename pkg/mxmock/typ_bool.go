@@ -22,7 +22,7 @@ func (b *Bool) Random(keys ...string) string {
 		if key != b.colName {
 			continue
 		}
-		return fmt.Sprintf("%t", gofakeit.Bool())
+		return fmt.Sprintf("%t", b.Faker().Bool())
 	}
 	return ""
 }
@@ -62,3 +62,11 @@ func (bs Bools) Parse(string) {}
 func (bs *Bools) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (bs *Bools) setFaker(f *gofakeit.Faker) {
+	bs.BaseType.setFaker(f)
+	for _, e := range bs.bools {
+		e.setFaker(f)
+	}
+}
@@ -22,9 +22,9 @@ func (b ByteA) Random(keys ...string) string {
 		if key != b.colName {
 			continue
 		}
-		result := make([]byte, gofakeit.Number(0, 1024)+1)
+		result := make([]byte, b.Faker().Number(0, 1024)+1)
 		for i := range result {
-			result[i] = byte(gofakeit.Number(0, 255))
+			result[i] = byte(b.Faker().Number(0, 255))
 		}
 		return fmt.Sprintf("%v", result)
 	}
@@ -65,3 +65,11 @@ func (bs ByteAs) Parse(string) {}
 func (bs *ByteAs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (bs *ByteAs) setFaker(f *gofakeit.Faker) {
+	bs.BaseType.setFaker(f)
+	for _, e := range bs.byteAs {
+		e.setFaker(f)
+	}
+}
@@ -22,7 +22,7 @@ func (bp *BPChar) Random(keys ...string) string {
 		if key != bp.colName {
 			continue
 		}
-		return gofakeit.LetterN(uint(bp.Length))
+		return bp.Faker().LetterN(uint(bp.Length))
 	}
 	return ""
 }
@@ -71,6 +71,14 @@ func (bps *BPChars) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (bps *BPChars) setFaker(f *gofakeit.Faker) {
+	bps.BaseType.setFaker(f)
+	for _, e := range bps.bpChars {
+		e.setFaker(f)
+	}
+}
+
 type VarChar struct {
 	BaseType
 	Length int
@@ -87,7 +95,7 @@ func (v *VarChar) Random(keys ...string) string {
 		if key != v.colName {
 			continue
 		}
-		return gofakeit.LetterN(uint(v.Length))
+		return v.Faker().LetterN(uint(v.Length))
 	}
 	return ""
 }
@@ -135,3 +143,11 @@ func (vs VarChars) Parse(td string) {
 func (vs *VarChars) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (vs *VarChars) setFaker(f *gofakeit.Faker) {
+	vs.BaseType.setFaker(f)
+	for _, e := range vs.varChars {
+		e.setFaker(f)
+	}
+}
@@ -1,7 +1,6 @@
 package mxmock
 
 import (
-	"github.com/brianvoe/gofakeit/v6"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -26,7 +25,7 @@ func (vs EnumVals) Val() []string {
 	return r
 }
 
-func NewEnum(dt string, conn *sqlx.DB) (*Enum, error) {
+func NewEnum(colName, dt string, conn *sqlx.DB) (*Enum, error) {
 	rows, err := conn.Query(_SELECT_ENUM_VALUES, dt)
 	if err != nil {
 		return nil, err
@@ -48,12 +47,19 @@ func NewEnum(dt string, conn *sqlx.DB) (*Enum, error) {
 	}
 
 	return &Enum{
-		vals: vals.Val(),
+		BaseType: NewBaseType(colName),
+		vals:     vals.Val(),
 	}, nil
 }
 
-func (e Enum) Random(keys ...string) string {
-	return gofakeit.RandomString(e.vals)
+func (e *Enum) Random(keys ...string) string {
+	for _, key := range keys {
+		if key != e.colName || len(e.vals) == 0 {
+			continue
+		}
+		return e.Faker().RandomString(e.vals)
+	}
+	return ""
 }
 
 func (e *Enum) ValueRange() map[string]*ValueRange {
@@ -24,7 +24,7 @@ func (f4 *Float4) Random(keys ...string) string {
 		if key != f4.colName {
 			continue
 		}
-		return fmt.Sprintf("%f", gofakeit.Float32Range(-2767, 2767))
+		return fmt.Sprintf("%f", f4.Faker().Float32Range(-2767, 2767))
 	}
 	return ""
 }
@@ -65,6 +65,14 @@ func (f4s *Float4s) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (f4s *Float4s) setFaker(f *gofakeit.Faker) {
+	f4s.BaseType.setFaker(f)
+	for _, e := range f4s.float4s {
+		e.setFaker(f)
+	}
+}
+
 type Float8 struct {
 	BaseType
 }
@@ -80,7 +88,7 @@ func (f8 *Float8) Random(keys ...string) string {
 		if key != f8.colName {
 			continue
 		}
-		return fmt.Sprintf("%f", gofakeit.Float32Range(-2767, 2767))
+		return fmt.Sprintf("%f", f8.Faker().Float32Range(-2767, 2767))
 	}
 	return ""
 }
@@ -121,6 +129,14 @@ func (f8s *Float8s) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (f8s *Float8s) setFaker(f *gofakeit.Faker) {
+	f8s.BaseType.setFaker(f)
+	for _, e := range f8s.float8s {
+		e.setFaker(f)
+	}
+}
+
 type Numeric struct {
 	BaseType
 	Max       int
@@ -143,7 +159,7 @@ func (n *Numeric) Random(keys ...string) string {
 			continue
 		}
 		fmtStr := "%." + fmt.Sprintf("%d", n.Precision) + "f"
-		f := gofakeit.Float64Range(1, float64(n.Max))
+		f := n.Faker().Float64Range(1, float64(n.Max))
 		stringFloat := strconv.FormatFloat(f, 'f', n.Precision, 64)
 		if len(stringFloat) > n.Max {
 			f = math.Log10(f)
@@ -193,6 +209,14 @@ func (ns *Numerics) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ns *Numerics) setFaker(f *gofakeit.Faker) {
+	ns.BaseType.setFaker(f)
+	for _, e := range ns.numerics {
+		e.setFaker(f)
+	}
+}
+
 type Money struct {
 	BaseType
 }
@@ -208,7 +232,7 @@ func (m *Money) Random(keys ...string) string {
 		if key != m.colName {
 			continue
 		}
-		return fmt.Sprintf("%f", gofakeit.Float32Range(-2767, 2767))
+		return fmt.Sprintf("%f", m.Faker().Float32Range(-2767, 2767))
 	}
 	return ""
 }
@@ -248,3 +272,11 @@ func (ms Moneys) Parse(string) {}
 func (ms *Moneys) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ms *Moneys) setFaker(f *gofakeit.Faker) {
+	ms.BaseType.setFaker(f)
+	for _, e := range ms.moneys {
+		e.setFaker(f)
+	}
+}
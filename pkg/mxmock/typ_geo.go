@@ -24,10 +24,10 @@ func (b *Box) Random(keys ...string) string {
 		}
 		return fmt.Sprintf(
 			"%d,%d,%d,%d",
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
+			b.Faker().Number(1, 999),
+			b.Faker().Number(1, 999),
+			b.Faker().Number(1, 999),
+			b.Faker().Number(1, 999),
 		)
 	}
 	return ""
@@ -69,6 +69,14 @@ func (bs Boxes) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (bs *Boxes) setFaker(f *gofakeit.Faker) {
+	bs.BaseType.setFaker(f)
+	for _, e := range bs.boxes {
+		e.setFaker(f)
+	}
+}
+
 type Circle struct {
 	BaseType
 }
@@ -86,9 +94,9 @@ func (c *Circle) Random(keys ...string) string {
 		}
 		return fmt.Sprintf(
 			"<(%d,%d),%d>",
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
+			c.Faker().Number(1, 999),
+			c.Faker().Number(1, 999),
+			c.Faker().Number(1, 999),
 		)
 	}
 	return ""
@@ -130,6 +138,14 @@ func (cs Circles) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (cs *Circles) setFaker(f *gofakeit.Faker) {
+	cs.BaseType.setFaker(f)
+	for _, e := range cs.circles {
+		e.setFaker(f)
+	}
+}
+
 type Line = Box
 
 type Lines = Boxes
@@ -163,8 +179,8 @@ func (p *Point) Random(keys ...string) string {
 		}
 		return fmt.Sprintf(
 			"%d,%d",
-			gofakeit.Number(1, 999),
-			gofakeit.Number(1, 999),
+			p.Faker().Number(1, 999),
+			p.Faker().Number(1, 999),
 		)
 	}
 	return ""
@@ -205,3 +221,11 @@ func (ps Points) Parse(string) {}
 func (ps Points) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ps *Points) setFaker(f *gofakeit.Faker) {
+	ps.BaseType.setFaker(f)
+	for _, e := range ps.points {
+		e.setFaker(f)
+	}
+}
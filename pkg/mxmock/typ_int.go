@@ -22,7 +22,7 @@ func (i2 *Int2) Random(keys ...string) string {
 		if key != i2.colName {
 			continue
 		}
-		return fmt.Sprintf("%d", gofakeit.Number(-2767, 2767))
+		return fmt.Sprintf("%d", i2.Faker().Number(-2767, 2767))
 	}
 	return ""
 }
@@ -63,6 +63,14 @@ func (i2s *Int2s) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (i2s *Int2s) setFaker(f *gofakeit.Faker) {
+	i2s.BaseType.setFaker(f)
+	for _, e := range i2s.int2s {
+		e.setFaker(f)
+	}
+}
+
 type Int4 struct {
 	BaseType
 }
@@ -78,7 +86,7 @@ func (i4 *Int4) Random(keys ...string) string {
 		if key != i4.colName {
 			continue
 		}
-		return fmt.Sprintf("%d", gofakeit.Number(-7483647, 7483647))
+		return fmt.Sprintf("%d", i4.Faker().Number(-7483647, 7483647))
 	}
 	return ""
 }
@@ -119,6 +127,14 @@ func (i4s *Int4s) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (i4s *Int4s) setFaker(f *gofakeit.Faker) {
+	i4s.BaseType.setFaker(f)
+	for _, e := range i4s.int4s {
+		e.setFaker(f)
+	}
+}
+
 type Int8 struct {
 	BaseType
 }
@@ -134,7 +150,7 @@ func (i8 *Int8) Random(keys ...string) string {
 		if key != i8.colName {
 			continue
 		}
-		return fmt.Sprintf("%d", gofakeit.Number(-372036854775807, 372036854775807))
+		return fmt.Sprintf("%d", i8.Faker().Number(-372036854775807, 372036854775807))
 	}
 	return ""
 }
@@ -175,6 +191,14 @@ func (i8s *Int8s) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (i8s *Int8s) setFaker(f *gofakeit.Faker) {
+	i8s.BaseType.setFaker(f)
+	for _, e := range i8s.int8s {
+		e.setFaker(f)
+	}
+}
+
 type Oid struct {
 	BaseType
 }
@@ -190,7 +214,7 @@ func (id *Oid) Random(keys ...string) string {
 		if key != id.colName {
 			continue
 		}
-		return fmt.Sprintf("%d", gofakeit.Number(-7483647, 7483647))
+		return fmt.Sprintf("%d", id.Faker().Number(-7483647, 7483647))
 	}
 	return ""
 }
@@ -21,7 +21,7 @@ func (i *INet) Random(keys ...string) string {
 		if key != i.colName {
 			continue
 		}
-		return gofakeit.IPv6Address()
+		return i.Faker().IPv6Address()
 	}
 	return ""
 }
@@ -64,6 +64,14 @@ func (is *INets) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (is *INets) setFaker(f *gofakeit.Faker) {
+	is.BaseType.setFaker(f)
+	for _, e := range is.inets {
+		e.setFaker(f)
+	}
+}
+
 type CIDR struct {
 	BaseType
 }
@@ -79,7 +87,7 @@ func (c *CIDR) Random(keys ...string) string {
 		if key != c.colName {
 			continue
 		}
-		return gofakeit.IPv6Address()
+		return c.Faker().IPv6Address()
 	}
 	return ""
 }
@@ -121,3 +129,11 @@ func (cs CIDRs) Parse(string) {}
 func (cs *CIDRs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (cs *CIDRs) setFaker(f *gofakeit.Faker) {
+	cs.BaseType.setFaker(f)
+	for _, e := range cs.cidrs {
+		e.setFaker(f)
+	}
+}
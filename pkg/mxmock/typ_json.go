@@ -25,7 +25,7 @@ func (j *JSON) Random(keys ...string) string {
 		if key != j.colName {
 			continue
 		}
-		_ = gofakeit.Struct(j)
+		_ = j.Faker().Struct(j)
 		b, _ := json.Marshal(j)
 		return string(b)
 	}
@@ -69,5 +69,13 @@ func (js *JSONs) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (js *JSONs) setFaker(f *gofakeit.Faker) {
+	js.BaseType.setFaker(f)
+	for _, e := range js.jsons {
+		e.setFaker(f)
+	}
+}
+
 type JSONB = JSON
 type JSONBs = JSONs
@@ -21,7 +21,7 @@ func (m *MacAddr) Random(keys ...string) string {
 		if key != m.colName {
 			continue
 		}
-		return gofakeit.MacAddress()
+		return m.Faker().MacAddress()
 	}
 	return ""
 }
@@ -61,3 +61,11 @@ func (ms MacAddrs) Parse(string) {}
 func (ms *MacAddrs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ms *MacAddrs) setFaker(f *gofakeit.Faker) {
+	ms.BaseType.setFaker(f)
+	for _, e := range ms.macAddrs {
+		e.setFaker(f)
+	}
+}
@@ -23,7 +23,7 @@ func (p *PgLsn) Random(keys ...string) string {
 			continue
 		}
 		return fmt.Sprintf("%02x/%02x",
-			gofakeit.Word(), gofakeit.Word())
+			p.Faker().Word(), p.Faker().Word())
 	}
 	return ""
 }
@@ -63,3 +63,11 @@ func (ps PgLsns) Parse(string) {}
 func (ps PgLsns) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ps *PgLsns) setFaker(f *gofakeit.Faker) {
+	ps.BaseType.setFaker(f)
+	for _, e := range ps.pgLsns {
+		e.setFaker(f)
+	}
+}
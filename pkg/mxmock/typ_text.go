@@ -21,7 +21,7 @@ func (t *Text) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Sentence(30)
+		return t.Faker().Sentence(30)
 	}
 	return ""
 }
@@ -62,5 +62,13 @@ func (ts *Texts) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ts *Texts) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.texts {
+		e.setFaker(f)
+	}
+}
+
 // TODO
 type CiText struct{}
@@ -21,7 +21,7 @@ func (d *Date) Random(keys ...string) string {
 		if key != d.colName {
 			continue
 		}
-		return gofakeit.Date().Format("2006-01-02")
+		return d.Faker().Date().Format("2006-01-02")
 	}
 	return ""
 }
@@ -62,6 +62,14 @@ func (ds *Dates) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ds *Dates) setFaker(f *gofakeit.Faker) {
+	ds.BaseType.setFaker(f)
+	for _, e := range ds.dates {
+		e.setFaker(f)
+	}
+}
+
 type Time struct {
 	BaseType
 }
@@ -77,7 +85,7 @@ func (t Time) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Date().Format("15:04:05")
+		return t.Faker().Date().Format("15:04:05")
 	}
 	return ""
 }
@@ -118,6 +126,14 @@ func (ts Times) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ts *Times) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.times {
+		e.setFaker(f)
+	}
+}
+
 type TimeTZ struct {
 	BaseType
 }
@@ -133,7 +149,7 @@ func (t *TimeTZ) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Date().Format("15:04:05.000000")
+		return t.Faker().Date().Format("15:04:05.000000")
 	}
 	return ""
 }
@@ -174,6 +190,14 @@ func (ts *TimeTZs) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ts *TimeTZs) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.timeTZs {
+		e.setFaker(f)
+	}
+}
+
 type Timestamp struct {
 	BaseType
 }
@@ -189,7 +213,7 @@ func (t *Timestamp) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Date().Format("2006-01-02 15:04:05")
+		return t.Faker().Date().Format("2006-01-02 15:04:05")
 	}
 	return ""
 }
@@ -230,6 +254,14 @@ func (ts Timestamps) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ts *Timestamps) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.timestamps {
+		e.setFaker(f)
+	}
+}
+
 type TimestampTZ struct {
 	BaseType
 }
@@ -245,7 +277,7 @@ func (t *TimestampTZ) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Date().Format("2006-01-02 15:04:05.000000")
+		return t.Faker().Date().Format("2006-01-02 15:04:05.000000")
 	}
 	return ""
 }
@@ -285,3 +317,11 @@ func (ts TimestampTZs) Parse(string) {}
 func (ts *TimestampTZs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ts *TimestampTZs) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.timestampTZs {
+		e.setFaker(f)
+	}
+}
@@ -21,18 +21,18 @@ func (t *TSQuery) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		number := gofakeit.Number(1, 9999)
+		number := t.Faker().Number(1, 9999)
 		number = number % 5
 		if number == 0 {
-			return gofakeit.Word() + " & " + gofakeit.Word()
+			return t.Faker().Word() + " & " + t.Faker().Word()
 		} else if number == 1 {
-			return gofakeit.Word() + " | " + gofakeit.Word()
+			return t.Faker().Word() + " | " + t.Faker().Word()
 		} else if number == 2 {
-			return " ! " + gofakeit.Word() + " & " + gofakeit.Word()
+			return " ! " + t.Faker().Word() + " & " + t.Faker().Word()
 		} else if number == 3 {
-			return gofakeit.Word() + " & " + gofakeit.Word() + "  & ! " + gofakeit.Word()
+			return t.Faker().Word() + " & " + t.Faker().Word() + "  & ! " + t.Faker().Word()
 		} else {
-			return gofakeit.Word() + " & ( " + gofakeit.Word() + " | " + gofakeit.Word() + " )"
+			return t.Faker().Word() + " & ( " + t.Faker().Word() + " | " + t.Faker().Word() + " )"
 		}
 	}
 	return ""
@@ -74,6 +74,14 @@ func (ts *TSQueries) ValueRange() map[string]*ValueRange {
 	return nil
 }
 
+// setFaker sets the faker of the elements as well
+func (ts *TSQueries) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.tsQueries {
+		e.setFaker(f)
+	}
+}
+
 type TSVector struct {
 	BaseType
 }
@@ -89,7 +97,7 @@ func (t *TSVector) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		return gofakeit.Sentence(100)
+		return t.Faker().Sentence(100)
 	}
 	return ""
 }
@@ -129,3 +137,11 @@ func (ts TSVectors) Parse(string) {}
 func (ts *TSVectors) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ts *TSVectors) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.tsVectors {
+		e.setFaker(f)
+	}
+}
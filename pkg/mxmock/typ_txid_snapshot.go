@@ -23,8 +23,8 @@ func (t *TxidSnapshot) Random(keys ...string) string {
 		if key != t.colName {
 			continue
 		}
-		x, _ := strconv.Atoi(gofakeit.DigitN(8))
-		y, _ := strconv.Atoi(gofakeit.DigitN(8))
+		x, _ := strconv.Atoi(t.Faker().DigitN(8))
+		y, _ := strconv.Atoi(t.Faker().DigitN(8))
 		if x > y {
 			return fmt.Sprintf("%v:%v:", y, x)
 		}
@@ -68,3 +68,11 @@ func (ts TxidSnapshots) Parse(string) {}
 func (ts *TxidSnapshots) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (ts *TxidSnapshots) setFaker(f *gofakeit.Faker) {
+	ts.BaseType.setFaker(f)
+	for _, e := range ts.txidSnapshots {
+		e.setFaker(f)
+	}
+}
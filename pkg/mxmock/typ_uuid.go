@@ -21,7 +21,7 @@ func (u *UUID) Random(keys ...string) string {
 		if key != u.colName {
 			continue
 		}
-		return gofakeit.UUID()
+		return u.Faker().UUID()
 	}
 	return ""
 }
@@ -62,3 +62,11 @@ func (us UUIDs) Parse(string) {}
 func (us *UUIDs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (us *UUIDs) setFaker(f *gofakeit.Faker) {
+	us.BaseType.setFaker(f)
+	for _, e := range us.uuids {
+		e.setFaker(f)
+	}
+}
@@ -26,7 +26,7 @@ func (x *XML) Random(keys ...string) string {
 		if key != x.colName {
 			continue
 		}
-		_ = gofakeit.Struct(x)
+		_ = x.Faker().Struct(x)
 		b, _ := xml.MarshalIndent(x, "  ", "    ")
 		return xml.Header + string(b)
 	}
@@ -68,3 +68,11 @@ func (xs XMLs) Parse(string) {}
 func (xs *XMLs) ValueRange() map[string]*ValueRange {
 	return nil
 }
+
+// setFaker sets the faker of the elements as well
+func (xs *XMLs) setFaker(f *gofakeit.Faker) {
+	xs.BaseType.setFaker(f)
+	for _, e := range xs.xmls {
+		e.setFaker(f)
+	}
+}
@@ -0,0 +1,188 @@
+package parquet
+
+import (
+	"fmt"
+)
+
+// physical types
+const (
+	_TYPE_BOOLEAN              = 0
+	_TYPE_INT32                = 1
+	_TYPE_INT64                = 2
+	_TYPE_INT96                = 3
+	_TYPE_FLOAT                = 4
+	_TYPE_DOUBLE               = 5
+	_TYPE_BYTE_ARRAY           = 6
+	_TYPE_FIXED_LEN_BYTE_ARRAY = 7
+)
+
+// repetition types
+const (
+	_REQUIRED = 0
+	_OPTIONAL = 1
+	_REPEATED = 2
+)
+
+// converted types, which the logical types are also mapped to
+const (
+	_CONVERTED_NONE             = -1
+	_CONVERTED_DECIMAL          = 5
+	_CONVERTED_DATE             = 6
+	_CONVERTED_TIMESTAMP_MILLIS = 9
+	_CONVERTED_TIMESTAMP_MICROS = 10
+	// _CONVERTED_TIMESTAMP_NANOS has no converted type, it is of the logical type only
+	_CONVERTED_TIMESTAMP_NANOS = 100
+	_CONVERTED_UUID            = 101
+)
+
+// encodings
+const (
+	_ENCODING_PLAIN            = 0
+	_ENCODING_PLAIN_DICTIONARY = 2
+	_ENCODING_RLE              = 3
+	_ENCODING_RLE_DICTIONARY   = 8
+)
+
+// compression codecs
+const (
+	_CODEC_UNCOMPRESSED = 0
+	_CODEC_SNAPPY       = 1
+	_CODEC_GZIP         = 2
+	_CODEC_ZSTD         = 6
+)
+
+// page types
+const (
+	_PAGE_DATA       = 0
+	_PAGE_DICTIONARY = 2
+	_PAGE_DATA_V2    = 3
+)
+
+// column is a leaf of the schema
+type column struct {
+	name          string
+	typ           int64
+	typeLength    int64
+	optional      bool
+	convertedType int64
+	scale         int64
+}
+
+// chunk is a column chunk of a row group
+type chunk struct {
+	codec      int64
+	numValues  int64
+	offset     int64
+	size       int64
+	dataOffset int64
+}
+
+type rowGroup struct {
+	numRows int64
+	chunks  []chunk
+}
+
+type fileMetaData struct {
+	columns   []*column
+	rowGroups []rowGroup
+	numRows   int64
+}
+
+// parseFileMetaData parses the metadata of a file, whose column chunks are in the bytes before dataEnd
+func parseFileMetaData(s tstruct, dataEnd int64) (*fileMetaData, error) {
+	schema := s.list(2)
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("no schema")
+	}
+	meta := &fileMetaData{numRows: s.int(3)}
+
+	root, _ := schema[0].(tstruct)
+	if int(root.int(5)) != len(schema)-1 {
+		return nil, fmt.Errorf("nested columns are not supported")
+	}
+	for _, e := range schema[1:] {
+		elem, _ := e.(tstruct)
+		col, err := parseColumn(elem)
+		if err != nil {
+			return nil, err
+		}
+		meta.columns = append(meta.columns, col)
+	}
+
+	for _, g := range s.list(4) {
+		group, _ := g.(tstruct)
+		rg := rowGroup{numRows: group.int(3)}
+		chunks := group.list(1)
+		if len(chunks) != len(meta.columns) {
+			return nil, fmt.Errorf("%d column chunks in a row group of %d columns", len(chunks), len(meta.columns))
+		}
+		for _, c := range chunks {
+			cc, _ := c.(tstruct)
+			if cc.string(1) != "" {
+				return nil, fmt.Errorf("column chunks in external files are not supported")
+			}
+			md := cc.structOf(3)
+			ch := chunk{
+				codec:      md.int(4),
+				numValues:  md.int(5),
+				size:       md.int(7),
+				dataOffset: md.int(9),
+			}
+			ch.offset = ch.dataOffset
+			if dictOffset := md.int(11); md.has(11) && dictOffset > 0 && dictOffset < ch.offset {
+				ch.offset = dictOffset
+			}
+			if ch.offset < int64(len(_MAGIC)) || ch.size <= 0 || ch.size > dataEnd-ch.offset {
+				return nil, fmt.Errorf("invalid column chunk of %d bytes at %d", ch.size, ch.offset)
+			}
+			rg.chunks = append(rg.chunks, ch)
+		}
+		meta.rowGroups = append(meta.rowGroups, rg)
+	}
+	return meta, nil
+}
+
+func parseColumn(elem tstruct) (*column, error) {
+	col := &column{
+		name:          elem.string(4),
+		typ:           elem.int(1),
+		typeLength:    elem.int(2),
+		convertedType: _CONVERTED_NONE,
+		scale:         elem.int(7),
+	}
+	if elem.has(5) || !elem.has(1) {
+		return nil, fmt.Errorf("nested column %s is not supported", col.name)
+	}
+	switch elem.int(3) {
+	case _OPTIONAL:
+		col.optional = true
+	case _REPEATED:
+		return nil, fmt.Errorf("repeated column %s is not supported", col.name)
+	}
+	if elem.has(6) {
+		col.convertedType = elem.int(6)
+	}
+
+	// the logical type overrides the converted type
+	logicalType := elem.structOf(10)
+	switch {
+	case logicalType.has(5):
+		col.convertedType = _CONVERTED_DECIMAL
+		col.scale = logicalType.structOf(5).int(1)
+	case logicalType.has(6):
+		col.convertedType = _CONVERTED_DATE
+	case logicalType.has(8):
+		unit := logicalType.structOf(8).structOf(2)
+		switch {
+		case unit.has(1):
+			col.convertedType = _CONVERTED_TIMESTAMP_MILLIS
+		case unit.has(2):
+			col.convertedType = _CONVERTED_TIMESTAMP_MICROS
+		case unit.has(3):
+			col.convertedType = _CONVERTED_TIMESTAMP_NANOS
+		}
+	case logicalType.has(14):
+		col.convertedType = _CONVERTED_UUID
+	}
+	return col, nil
+}
@@ -0,0 +1,258 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+var errShortPage = errors.New("page is shorter than its values")
+
+// chunkReader reads the pages of a column chunk, counting the bytes left
+type chunkReader struct {
+	r    *bufio.Reader
+	left int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.left -= n
+	return n, err
+}
+
+func (r *chunkReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.left--
+	}
+	return b, err
+}
+
+func (r *chunkReader) Len() int {
+	return r.left
+}
+
+// columnReader reads the values of a column chunk page by page
+type columnReader struct {
+	col   *column
+	chunk chunk
+	r     *chunkReader
+	zstd  func() (*zstd.Decoder, error)
+
+	dict []string
+	// values of the current page, where nulls are nil
+	values []*string
+	pos    int
+	// read is the number of values of the chunk read
+	read int64
+}
+
+func (cr *columnReader) next() (*string, error) {
+	for cr.pos >= len(cr.values) {
+		if cr.read >= cr.chunk.numValues {
+			return nil, fmt.Errorf("column %s has fewer values than rows", cr.col.name)
+		}
+		if err := cr.readPage(); err != nil {
+			return nil, fmt.Errorf("failed to read column %s: %w", cr.col.name, err)
+		}
+	}
+	v := cr.values[cr.pos]
+	cr.pos++
+	return v, nil
+}
+
+func (cr *columnReader) readPage() error {
+	header, err := readStruct(cr.r)
+	if err != nil {
+		return err
+	}
+	size := header.int(3)
+	if size < 0 || size > int64(cr.r.Len()) {
+		return fmt.Errorf("invalid page size %d with %d bytes left", size, cr.r.Len())
+	}
+	page := make([]byte, size)
+	if _, err = io.ReadFull(cr.r, page); err != nil {
+		return err
+	}
+	uncompressedSize := int(header.int(2))
+
+	switch header.int(1) {
+	case _PAGE_DICTIONARY:
+		if page, err = cr.decompress(page, uncompressedSize); err != nil {
+			return err
+		}
+		cr.dict, err = cr.col.decodePlain(page, int(header.structOf(7).int(1)))
+		return err
+
+	case _PAGE_DATA:
+		dh := header.structOf(5)
+		if page, err = cr.decompress(page, uncompressedSize); err != nil {
+			return err
+		}
+		n, err := cr.numValues(dh.int(1))
+		if err != nil {
+			return err
+		}
+		var defs []uint32
+		if cr.col.optional {
+			if dh.int(3) != _ENCODING_RLE {
+				return fmt.Errorf("unsupported encoding %d of definition levels", dh.int(3))
+			}
+			if len(page) < 4 {
+				return errShortPage
+			}
+			l := int(binary.LittleEndian.Uint32(page))
+			if len(page) < 4+l {
+				return errShortPage
+			}
+			if defs, err = decodeHybrid(page[4:4+l], 1, n); err != nil {
+				return err
+			}
+			page = page[4+l:]
+		}
+		return cr.setValues(dh.int(2), page, n, defs)
+
+	case _PAGE_DATA_V2:
+		dh := header.structOf(8)
+		n, err := cr.numValues(dh.int(1))
+		if err != nil {
+			return err
+		}
+		repLen, defLen := int(dh.int(6)), int(dh.int(5))
+		if repLen < 0 || defLen < 0 || len(page) < repLen+defLen {
+			return errShortPage
+		}
+		var defs []uint32
+		if cr.col.optional {
+			if defs, err = decodeHybrid(page[repLen:repLen+defLen], 1, n); err != nil {
+				return err
+			}
+		}
+		page = page[repLen+defLen:]
+		if dh.bool(7, true) {
+			if page, err = cr.decompress(page, uncompressedSize-repLen-defLen); err != nil {
+				return err
+			}
+		}
+		return cr.setValues(dh.int(4), page, n, defs)
+	}
+	// index pages are skipped
+	return nil
+}
+
+// numValues checks the number of values of a data page against the values of the chunk left
+func (cr *columnReader) numValues(n int64) (int, error) {
+	if n < 0 || n > cr.chunk.numValues-cr.read {
+		return 0, fmt.Errorf("invalid number of values %d with %d values of the chunk left", n, cr.chunk.numValues-cr.read)
+	}
+	return int(n), nil
+}
+
+// setValues decodes the non-null values of a data page of n values
+func (cr *columnReader) setValues(encoding int64, data []byte, n int, defs []uint32) error {
+	numNonNull := n
+	if defs != nil {
+		numNonNull = 0
+		for _, def := range defs {
+			numNonNull += int(def)
+		}
+	}
+
+	var values []string
+	var err error
+	switch encoding {
+	case _ENCODING_PLAIN:
+		values, err = cr.col.decodePlain(data, numNonNull)
+	case _ENCODING_PLAIN_DICTIONARY, _ENCODING_RLE_DICTIONARY:
+		values, err = cr.decodeDictionary(data, numNonNull)
+	case _ENCODING_RLE:
+		if cr.col.typ != _TYPE_BOOLEAN || len(data) < 4 {
+			return fmt.Errorf("unsupported RLE encoded values")
+		}
+		var bits []uint32
+		if bits, err = decodeHybrid(data[4:], 1, numNonNull); err == nil {
+			for _, b := range bits {
+				values = append(values, strconv.FormatBool(b == 1))
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported encoding %d", encoding)
+	}
+	if err != nil {
+		return err
+	}
+
+	cr.values = make([]*string, n)
+	j := 0
+	for i := range cr.values {
+		if defs == nil || defs[i] == 1 {
+			cr.values[i] = &values[j]
+			j++
+		}
+	}
+	cr.pos = 0
+	cr.read += int64(n)
+	return nil
+}
+
+func (cr *columnReader) decodeDictionary(data []byte, n int) ([]string, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	if len(data) == 0 {
+		return nil, errShortPage
+	}
+	indices, err := decodeHybrid(data[1:], int(data[0]), n)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, n)
+	for i, idx := range indices {
+		if int(idx) >= len(cr.dict) {
+			return nil, fmt.Errorf("dictionary index %d out of range %d", idx, len(cr.dict))
+		}
+		values[i] = cr.dict[idx]
+	}
+	return values, nil
+}
+
+func (cr *columnReader) decompress(data []byte, size int) ([]byte, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid uncompressed page size %d", size)
+	}
+	switch cr.chunk.codec {
+	case _CODEC_UNCOMPRESSED:
+		return data, nil
+	case _CODEC_SNAPPY:
+		if n, err := snappy.DecodedLen(data); err != nil || n != size {
+			return nil, fmt.Errorf("invalid uncompressed page size %d", size)
+		}
+		return snappy.Decode(make([]byte, size), data)
+	case _CODEC_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	case _CODEC_ZSTD:
+		d, err := cr.zstd()
+		if err != nil {
+			return nil, err
+		}
+		// the size is trusted no more than the buffer, which the values grow beyond
+		capacity := size
+		if capacity > _BUFFER_SIZE {
+			capacity = _BUFFER_SIZE
+		}
+		return d.DecodeAll(data, make([]byte, 0, capacity))
+	}
+	return nil, fmt.Errorf("unsupported compression codec %d", cr.chunk.codec)
+}
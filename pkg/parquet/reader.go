@@ -0,0 +1,163 @@
+// Package parquet reads the rows of Parquet files as text.
+//
+// Only flat schemas are supported, i.e. columns of primitive types which are
+// required or optional, with the values encoded in PLAIN or dictionaries,
+// in pages that are uncompressed or compressed by SNAPPY, GZIP or ZSTD.
+// Dates, timestamps, decimals and UUIDs are formatted as PostgreSQL reads them.
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	_MAGIC       = "PAR1"
+	_FOOTER_SIZE = 8
+	_BUFFER_SIZE = 1024 * 1024
+)
+
+type Reader struct {
+	f    *os.File
+	meta *fileMetaData
+	zstd *zstd.Decoder
+
+	// nextRowGroup is the index of the row group to read after the current one
+	nextRowGroup int
+	rowsLeft     int64
+	columns      []*columnReader
+}
+
+// Open opens the Parquet file, and reads its metadata,
+// failing if the schema or the compression is not supported.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{f: f}
+	if r.meta, err = readFileMetaData(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a supported Parquet file: %w", path, err)
+	}
+	return r, nil
+}
+
+func readFileMetaData(f *os.File) (*fileMetaData, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < int64(len(_MAGIC)+_FOOTER_SIZE) {
+		return nil, fmt.Errorf("file is too small")
+	}
+	footer := make([]byte, _FOOTER_SIZE)
+	if _, err = f.ReadAt(footer, info.Size()-_FOOTER_SIZE); err != nil {
+		return nil, err
+	}
+	if string(footer[4:]) != _MAGIC {
+		return nil, fmt.Errorf("no magic number")
+	}
+	size := int64(binary.LittleEndian.Uint32(footer))
+	if size > info.Size()-_FOOTER_SIZE-int64(len(_MAGIC)) {
+		return nil, fmt.Errorf("invalid size of metadata %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err = f.ReadAt(buf, info.Size()-_FOOTER_SIZE-size); err != nil {
+		return nil, err
+	}
+	s, err := readStruct(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	meta, err := parseFileMetaData(s, info.Size()-_FOOTER_SIZE-size)
+	if err != nil {
+		return nil, err
+	}
+	for _, rg := range meta.rowGroups {
+		for i, ch := range rg.chunks {
+			switch ch.codec {
+			case _CODEC_UNCOMPRESSED, _CODEC_SNAPPY, _CODEC_GZIP, _CODEC_ZSTD:
+			default:
+				return nil, fmt.Errorf("unsupported compression codec %d of column %s", ch.codec, meta.columns[i].name)
+			}
+		}
+	}
+	return meta, nil
+}
+
+// Columns returns the names of the columns
+func (r *Reader) Columns() []string {
+	names := make([]string, 0, len(r.meta.columns))
+	for _, col := range r.meta.columns {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+func (r *Reader) NumRows() int64 {
+	return r.meta.numRows
+}
+
+// Next returns the values of the next row in the order of Columns, where nulls are nil,
+// or io.EOF if there is no more row.
+func (r *Reader) Next() ([]*string, error) {
+	for r.rowsLeft == 0 {
+		if r.nextRowGroup >= len(r.meta.rowGroups) {
+			return nil, io.EOF
+		}
+		r.startRowGroup(r.meta.rowGroups[r.nextRowGroup])
+		r.nextRowGroup++
+	}
+
+	row := make([]*string, len(r.columns))
+	for i, cr := range r.columns {
+		v, err := cr.next()
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	r.rowsLeft--
+	return row, nil
+}
+
+func (r *Reader) startRowGroup(rg rowGroup) {
+	r.rowsLeft = rg.numRows
+	r.columns = make([]*columnReader, len(rg.chunks))
+	for i, ch := range rg.chunks {
+		r.columns[i] = &columnReader{
+			col:   r.meta.columns[i],
+			chunk: ch,
+			r: &chunkReader{
+				r:    bufio.NewReaderSize(io.NewSectionReader(r.f, ch.offset, ch.size), _BUFFER_SIZE),
+				left: int(ch.size),
+			},
+			zstd: r.zstdDecoder,
+		}
+	}
+}
+
+func (r *Reader) zstdDecoder() (*zstd.Decoder, error) {
+	if r.zstd == nil {
+		d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		r.zstd = d
+	}
+	return r.zstd, nil
+}
+
+func (r *Reader) Close() error {
+	if r.zstd != nil {
+		r.zstd.Close()
+	}
+	return r.f.Close()
+}
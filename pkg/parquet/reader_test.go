@@ -0,0 +1,227 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func schemaElement(name string, typ int32, optional bool, fields ...tfield) wstruct {
+	repetition := int32(_REQUIRED)
+	if optional {
+		repetition = _OPTIONAL
+	}
+	return append(wstruct{{1, typ}, {3, repetition}, {4, name}}, fields...)
+}
+
+func readAll(path string) ([]string, [][]*string, error) {
+	r, err := Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+	var rows [][]*string
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return r.Columns(), rows, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func text(row []*string) []string {
+	values := make([]string, len(row))
+	for i, v := range row {
+		values[i] = "NULL"
+		if v != nil {
+			values[i] = *v
+		}
+	}
+	return values
+}
+
+var _ = Describe("Reader", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "parquet")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	columns := []testColumn{
+		{
+			schema: schemaElement("ts", _TYPE_INT64, false, tfield{6, int32(_CONVERTED_TIMESTAMP_MICROS)}),
+			values: []interface{}{int64(1650000000123456), int64(1650000001000000), int64(1650000002000000)},
+		},
+		{
+			schema: schemaElement("vin", _TYPE_BYTE_ARRAY, false, tfield{6, int32(0)}),
+			values: []interface{}{"v1", "v2", "v1"},
+			dict:   true,
+		},
+		{
+			schema:   schemaElement("speed", _TYPE_DOUBLE, true),
+			optional: true,
+			values:   []interface{}{1.5, nil, -2.25},
+		},
+		{
+			schema:   schemaElement("price", _TYPE_INT32, true, tfield{6, int32(_CONVERTED_DECIMAL)}, tfield{7, int32(2)}, tfield{8, int32(9)}),
+			optional: true,
+			values:   []interface{}{nil, int32(12345), int32(-5)},
+		},
+		{
+			schema: schemaElement("day", _TYPE_INT32, false, tfield{10, wstruct{{6, wstruct{}}}}),
+			values: []interface{}{int32(19000), int32(19000), int32(19001)},
+		},
+		{
+			schema:   schemaElement("ok", _TYPE_BOOLEAN, true),
+			optional: true,
+			values:   []interface{}{true, false, nil},
+		},
+	}
+	expected := [][]string{
+		{"2022-04-15 05:20:00.123456", "v1", "1.5", "NULL", "2022-01-08", "true"},
+		{"2022-04-15 05:20:01", "v2", "NULL", "123.45", "2022-01-08", "false"},
+		{"2022-04-15 05:20:02", "v1", "-2.25", "-0.05", "2022-01-09", "NULL"},
+	}
+
+	It("should read the rows of the files of supported codecs and pages", func() {
+		for _, codec := range []int64{_CODEC_UNCOMPRESSED, _CODEC_SNAPPY, _CODEC_GZIP, _CODEC_ZSTD} {
+			for _, v2 := range []bool{false, true} {
+				for _, rowsPerGroup := range []int{1, 2, 3} {
+					desc := fmt.Sprintf("codec %d, v2 %v, %d rows per group", codec, v2, rowsPerGroup)
+					path := filepath.Join(dir, "t.parquet")
+					tf := testFile{codec: codec, dataPageV2: v2, rowsPerGroup: rowsPerGroup, columns: columns}
+					Expect(tf.write(path)).To(Succeed(), desc)
+
+					names, rows, err := readAll(path)
+					Expect(err).NotTo(HaveOccurred(), desc)
+					Expect(names).To(Equal([]string{"ts", "vin", "speed", "price", "day", "ok"}), desc)
+					Expect(rows).To(HaveLen(len(expected)), desc)
+					for i, row := range rows {
+						Expect(text(row)).To(Equal(expected[i]), desc)
+					}
+				}
+			}
+		}
+	})
+
+	It("should reject the files not supported", func() {
+		path := filepath.Join(dir, "t.parquet")
+		Expect(os.WriteFile(path, []byte("ts,vin\n"), 0644)).To(Succeed())
+		_, err := Open(path)
+		Expect(err).To(HaveOccurred())
+
+		// LZ4
+		Expect(testFile{codec: 5, rowsPerGroup: 3, columns: columns[:1]}.write(path)).To(Succeed())
+		_, err = Open(path)
+		Expect(err).To(MatchError(ContainSubstring("unsupported compression codec 5 of column ts")))
+
+		repeated := testColumn{schema: wstruct{{1, int32(_TYPE_INT32)}, {3, int32(_REPEATED)}, {4, "r"}}, values: []interface{}{int32(1)}}
+		Expect(testFile{rowsPerGroup: 1, columns: []testColumn{repeated}}.write(path)).To(Succeed())
+		_, err = Open(path)
+		Expect(err).To(MatchError(ContainSubstring("repeated column r is not supported")))
+	})
+
+	It("should reject the lengths beyond the bytes left", func() {
+		for _, data := range [][]byte{
+			// a list of 2^63-1 i32
+			{0x19, 0xf5, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+			// a list of 3 i32 with 2 left
+			{0x19, 0x35, 0x02, 0x04},
+			// a binary of 2^32-1 bytes
+			{0x18, 0xff, 0xff, 0xff, 0xff, 0x0f},
+			// a map of 2^63-1 entries
+			{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0x55},
+		} {
+			_, err := readStruct(bytes.NewReader(data))
+			Expect(err).To(MatchError(ContainSubstring("invalid length")), fmt.Sprintf("% x", data))
+		}
+
+		// the column chunks beyond the bytes before the metadata, of 100 bytes
+		for _, ch := range [][2]int64{{4, 96}, {0, 10}, {4, 0}, {4, -1}, {90, 11}, {4, math.MaxInt64}, {math.MaxInt64, 1}} {
+			buf := &bytes.Buffer{}
+			writeStruct(buf, wstruct{
+				{2, []interface{}{wstruct{{4, "schema"}, {5, int32(1)}}, schemaElement("c", _TYPE_INT32, false)}},
+				{3, int64(1)},
+				{4, []interface{}{wstruct{{1, []interface{}{wstruct{{2, ch[0]}, {3, wstruct{
+					{4, int32(_CODEC_UNCOMPRESSED)}, {5, int64(1)}, {7, ch[1]}, {9, ch[0]},
+				}}}}}, {3, int64(1)}}}},
+			})
+			s, err := readStruct(bytes.NewReader(buf.Bytes()))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = parseFileMetaData(s, 100)
+			if ch == [2]int64{4, 96} {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(MatchError(ContainSubstring("invalid column chunk")), fmt.Sprint(ch))
+			}
+		}
+	})
+
+	It("should reject the malformed pages", func() {
+		readPage := func(header wstruct, numValues int64) error {
+			buf := &bytes.Buffer{}
+			writeStruct(buf, header)
+			buf.Write([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+			cr := &columnReader{
+				col:   &column{name: "c", typ: _TYPE_INT32},
+				chunk: chunk{codec: _CODEC_UNCOMPRESSED, numValues: numValues},
+				r:     &chunkReader{r: bufio.NewReader(bytes.NewReader(buf.Bytes())), left: buf.Len()},
+			}
+			return cr.readPage()
+		}
+		dataPage := func(size, numValues int32) wstruct {
+			return wstruct{
+				{1, int32(_PAGE_DATA)}, {2, size}, {3, size},
+				{5, wstruct{{1, numValues}, {2, int32(_ENCODING_PLAIN)}, {3, int32(_ENCODING_RLE)}}},
+			}
+		}
+
+		Expect(readPage(dataPage(8, 2), 2)).To(Succeed())
+		Expect(readPage(dataPage(-1, 2), 2)).To(MatchError(ContainSubstring("invalid page size -1")))
+		Expect(readPage(dataPage(math.MaxInt32, 2), 2)).To(MatchError(ContainSubstring("invalid page size")))
+		Expect(readPage(dataPage(8, -1), 2)).To(MatchError(ContainSubstring("invalid number of values -1")))
+		Expect(readPage(dataPage(8, 3), 2)).To(MatchError(ContainSubstring("invalid number of values 3")))
+		Expect(readPage(dataPage(8, 3), 3)).To(Equal(errShortPage))
+
+		dictPage := func(numValues int32) wstruct {
+			return wstruct{{1, int32(_PAGE_DICTIONARY)}, {2, int32(8)}, {3, int32(8)}, {7, wstruct{{1, numValues}}}}
+		}
+		Expect(readPage(dictPage(2), 2)).To(Succeed())
+		Expect(readPage(dictPage(-1), 2)).To(Equal(errShortPage))
+		Expect(readPage(dictPage(math.MaxInt32), 2)).To(Equal(errShortPage))
+	})
+
+	It("should decode the hybrid encoding", func() {
+		// a run of 3 fives, then 8 bit-packed values 0..7
+		values, err := decodeHybrid([]byte{3 << 1, 5, 1<<1 | 1, 0x88, 0xc6, 0xfa}, 3, 11)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal([]uint32{5, 5, 5, 0, 1, 2, 3, 4, 5, 6, 7}))
+
+		_, err = decodeHybrid([]byte{1<<1 | 1, 0x88}, 3, 8)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should format decimals", func() {
+		Expect(formatDecimal(big.NewInt(12345), 2)).To(Equal("123.45"))
+		Expect(formatDecimal(big.NewInt(-5), 3)).To(Equal("-0.005"))
+		Expect(formatDecimal(big.NewInt(7), 0)).To(Equal("7"))
+		col := &column{convertedType: _CONVERTED_DECIMAL, scale: 1}
+		Expect(col.formatBytes([]byte{0xff, 0x38})).To(Equal("-20.0"))
+	})
+})
@@ -0,0 +1,66 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decodeHybrid decodes n values of the RLE/bit-packing hybrid encoding,
+// which the levels and dictionary indices are encoded in.
+func decodeHybrid(data []byte, bitWidth, n int) ([]uint32, error) {
+	if bitWidth > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", bitWidth)
+	}
+	// a run of few bytes may hold many values, which the values grow to
+	capacity := n
+	if capacity > len(data)*8 {
+		capacity = len(data) * 8
+	}
+	values := make([]uint32, 0, capacity)
+	byteWidth := (bitWidth + 7) / 8
+	for len(values) < n {
+		header, k := binary.Uvarint(data)
+		if k <= 0 {
+			return nil, errShortPage
+		}
+		data = data[k:]
+
+		if header&1 == 0 {
+			// a run of the same value
+			count := int(header >> 1)
+			if len(data) < byteWidth {
+				return nil, errShortPage
+			}
+			var v uint32
+			for i := 0; i < byteWidth; i++ {
+				v |= uint32(data[i]) << (8 * i)
+			}
+			data = data[byteWidth:]
+			for i := 0; i < count && len(values) < n; i++ {
+				values = append(values, v)
+			}
+			continue
+		}
+
+		// groups of 8 bit-packed values
+		count := int(header>>1) * 8
+		size := count * bitWidth / 8
+		if size > len(data) {
+			size = len(data)
+		}
+		// padded to read 8 bytes at any value
+		packed := make([]byte, size+8)
+		copy(packed, data[:size])
+		data = data[size:]
+		mask := uint64(1)<<bitWidth - 1
+		for i := 0; i < count && len(values) < n; i++ {
+			bit := i * bitWidth
+			if bit/8 >= size && bitWidth > 0 {
+				return nil, errShortPage
+			}
+			v := binary.LittleEndian.Uint64(packed[bit/8:]) >> (bit % 8)
+			values = append(values, uint32(v&mask))
+		}
+	}
+	return values, nil
+}
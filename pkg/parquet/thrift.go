@@ -0,0 +1,209 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// types of the thrift compact protocol
+const (
+	_T_STOP   = 0
+	_T_TRUE   = 1
+	_T_FALSE  = 2
+	_T_BYTE   = 3
+	_T_I16    = 4
+	_T_I32    = 5
+	_T_I64    = 6
+	_T_DOUBLE = 7
+	_T_BINARY = 8
+	_T_LIST   = 9
+	_T_SET    = 10
+	_T_MAP    = 11
+	_T_STRUCT = 12
+)
+
+// tstruct is a thrift struct decoded by field id, whose values are
+// bool, int64, float64, []byte, []interface{} or tstruct.
+// Maps are skipped, as none is read by the reader.
+type tstruct map[int16]interface{}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+	// Len returns the number of bytes left, which the lengths read are checked against
+	Len() int
+}
+
+// checkLen checks the length read against the bytes left,
+// as each of the bytes or the values takes a byte at least.
+func checkLen(r byteReader, n uint64) error {
+	if n > uint64(r.Len()) {
+		return fmt.Errorf("invalid length %d with %d bytes left", n, r.Len())
+	}
+	return nil
+}
+
+func readStruct(r byteReader) (tstruct, error) {
+	s := tstruct{}
+	var lastID int16
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		typ := b & 0x0f
+		if typ == _T_STOP {
+			return s, nil
+		}
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := readVarint(r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		lastID = id
+
+		var v interface{}
+		switch typ {
+		case _T_TRUE:
+			v = true
+		case _T_FALSE:
+			v = false
+		default:
+			if v, err = readValue(r, typ); err != nil {
+				return nil, err
+			}
+		}
+		if v != nil {
+			s[id] = v
+		}
+	}
+}
+
+func readValue(r byteReader, typ byte) (interface{}, error) {
+	switch typ {
+	case _T_TRUE, _T_FALSE:
+		// booleans in containers take a byte
+		b, err := r.ReadByte()
+		return b == _T_TRUE, err
+	case _T_BYTE:
+		b, err := r.ReadByte()
+		return int64(int8(b)), err
+	case _T_I16, _T_I32, _T_I64:
+		return readVarint(r)
+	case _T_DOUBLE:
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf[:])), nil
+	case _T_BINARY:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if err = checkLen(r, n); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, n)
+		_, err = io.ReadFull(r, buf)
+		return buf, err
+	case _T_LIST, _T_SET:
+		return readList(r)
+	case _T_MAP:
+		return nil, skipMap(r)
+	case _T_STRUCT:
+		return readStruct(r)
+	}
+	return nil, fmt.Errorf("unknown thrift type %d", typ)
+}
+
+func readList(r byteReader) ([]interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(b >> 4)
+	if size == 15 {
+		if size, err = binary.ReadUvarint(r); err != nil {
+			return nil, err
+		}
+	}
+	if err = checkLen(r, size); err != nil {
+		return nil, err
+	}
+	list := make([]interface{}, 0, size)
+	for i := uint64(0); i < size; i++ {
+		v, err := readValue(r, b&0x0f)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func skipMap(r byteReader) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil || size == 0 {
+		return err
+	}
+	if err = checkLen(r, size); err != nil {
+		return err
+	}
+	types, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < size; i++ {
+		if _, err = readValue(r, types>>4); err != nil {
+			return err
+		}
+		if _, err = readValue(r, types&0x0f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVarint reads a zigzag encoded integer
+func readVarint(r io.ByteReader) (int64, error) {
+	v, err := binary.ReadUvarint(r)
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (s tstruct) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s tstruct) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s tstruct) bool(id int16, dflt bool) bool {
+	if v, ok := s[id].(bool); ok {
+		return v
+	}
+	return dflt
+}
+
+func (s tstruct) string(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+func (s tstruct) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+func (s tstruct) structOf(id int16) tstruct {
+	v, _ := s[id].(tstruct)
+	return v
+}
@@ -0,0 +1,139 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	_DATE_FMT      = "2006-01-02"
+	_TIMESTAMP_FMT = "2006-01-02 15:04:05.999999999"
+	// _JULIAN_DAY_OF_EPOCH is the julian day of 1970-01-01, which INT96 timestamps count from
+	_JULIAN_DAY_OF_EPOCH = 2440588
+)
+
+// decodePlain decodes n plain encoded values of the column as text
+func (c *column) decodePlain(data []byte, n int) ([]string, error) {
+	// each of the values takes a bit at least
+	if n < 0 || n > len(data)*8 {
+		return nil, errShortPage
+	}
+	values := make([]string, 0, n)
+	width := 0
+	switch c.typ {
+	case _TYPE_BOOLEAN:
+		if len(data)*8 < n {
+			return nil, errShortPage
+		}
+		for i := 0; i < n; i++ {
+			values = append(values, strconv.FormatBool(data[i/8]>>(i%8)&1 == 1))
+		}
+		return values, nil
+	case _TYPE_INT32, _TYPE_FLOAT:
+		width = 4
+	case _TYPE_INT64, _TYPE_DOUBLE:
+		width = 8
+	case _TYPE_INT96:
+		width = 12
+	case _TYPE_FIXED_LEN_BYTE_ARRAY:
+		if c.typeLength <= 0 {
+			return nil, fmt.Errorf("invalid type length %d of column %s", c.typeLength, c.name)
+		}
+		width = int(c.typeLength)
+	case _TYPE_BYTE_ARRAY:
+		for i := 0; i < n; i++ {
+			if len(data) < 4 {
+				return nil, errShortPage
+			}
+			l := int(binary.LittleEndian.Uint32(data))
+			if len(data) < 4+l {
+				return nil, errShortPage
+			}
+			values = append(values, c.formatBytes(data[4:4+l]))
+			data = data[4+l:]
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown physical type %d of column %s", c.typ, c.name)
+	}
+
+	if len(data) < n*width {
+		return nil, errShortPage
+	}
+	for i := 0; i < n; i++ {
+		v := data[i*width : (i+1)*width]
+		switch c.typ {
+		case _TYPE_INT32:
+			values = append(values, c.formatInt(int64(int32(binary.LittleEndian.Uint32(v)))))
+		case _TYPE_INT64:
+			values = append(values, c.formatInt(int64(binary.LittleEndian.Uint64(v))))
+		case _TYPE_INT96:
+			nanos := int64(binary.LittleEndian.Uint64(v))
+			days := int64(binary.LittleEndian.Uint32(v[8:])) - _JULIAN_DAY_OF_EPOCH
+			values = append(values, time.Unix(days*24*60*60, nanos).UTC().Format(_TIMESTAMP_FMT))
+		case _TYPE_FLOAT:
+			values = append(values, strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(v))), 'g', -1, 32))
+		case _TYPE_DOUBLE:
+			values = append(values, strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(v)), 'g', -1, 64))
+		case _TYPE_FIXED_LEN_BYTE_ARRAY:
+			values = append(values, c.formatBytes(v))
+		}
+	}
+	return values, nil
+}
+
+func (c *column) formatInt(v int64) string {
+	switch c.convertedType {
+	case _CONVERTED_DATE:
+		return time.Unix(v*24*60*60, 0).UTC().Format(_DATE_FMT)
+	case _CONVERTED_TIMESTAMP_MILLIS:
+		return time.UnixMilli(v).UTC().Format(_TIMESTAMP_FMT)
+	case _CONVERTED_TIMESTAMP_MICROS:
+		return time.UnixMicro(v).UTC().Format(_TIMESTAMP_FMT)
+	case _CONVERTED_TIMESTAMP_NANOS:
+		return time.Unix(0, v).UTC().Format(_TIMESTAMP_FMT)
+	case _CONVERTED_DECIMAL:
+		return formatDecimal(big.NewInt(v), c.scale)
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func (c *column) formatBytes(b []byte) string {
+	switch c.convertedType {
+	case _CONVERTED_DECIMAL:
+		// big-endian two's complement
+		v := new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+		}
+		return formatDecimal(v, c.scale)
+	case _CONVERTED_UUID:
+		if len(b) == 16 {
+			h := hex.EncodeToString(b)
+			return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+		}
+	}
+	return string(b)
+}
+
+// formatDecimal formats unscaled * 10^-scale
+func formatDecimal(unscaled *big.Int, scale int64) string {
+	s := unscaled.String()
+	if scale <= 0 {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= int(scale) {
+		s = strings.Repeat("0", int(scale)-len(s)+1) + s
+	}
+	return sign + s[:len(s)-int(scale)] + "." + s[len(s)-int(scale):]
+}
@@ -0,0 +1,303 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"os"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// a minimal writer of Parquet files for the tests
+
+type tfield struct {
+	id int16
+	v  interface{}
+}
+
+// wstruct is a thrift struct to write, whose fields are in order of id
+type wstruct []tfield
+
+func thriftType(v interface{}) byte {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return _T_TRUE
+		}
+		return _T_FALSE
+	case int32:
+		return _T_I32
+	case int64:
+		return _T_I64
+	case string, []byte:
+		return _T_BINARY
+	case []interface{}:
+		return _T_LIST
+	case wstruct:
+		return _T_STRUCT
+	}
+	panic("unknown thrift value")
+}
+
+func writeVarint(buf *bytes.Buffer, v int64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], uint64(v<<1^v>>63))])
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func writeStruct(buf *bytes.Buffer, s wstruct) {
+	var lastID int16
+	for _, f := range s {
+		typ := thriftType(f.v)
+		if delta := f.id - lastID; delta > 0 && delta <= 15 {
+			buf.WriteByte(byte(delta)<<4 | typ)
+		} else {
+			buf.WriteByte(typ)
+			writeVarint(buf, int64(f.id))
+		}
+		if _, ok := f.v.(bool); !ok {
+			writeValue(buf, f.v)
+		}
+		lastID = f.id
+	}
+	buf.WriteByte(_T_STOP)
+}
+
+func writeValue(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case int32:
+		writeVarint(buf, int64(v))
+	case int64:
+		writeVarint(buf, v)
+	case string:
+		writeUvarint(buf, uint64(len(v)))
+		buf.WriteString(v)
+	case []byte:
+		writeUvarint(buf, uint64(len(v)))
+		buf.Write(v)
+	case []interface{}:
+		var typ byte = _T_I32
+		if len(v) > 0 {
+			typ = thriftType(v[0])
+		}
+		if len(v) < 15 {
+			buf.WriteByte(byte(len(v))<<4 | typ)
+		} else {
+			buf.WriteByte(0xf0 | typ)
+			writeUvarint(buf, uint64(len(v)))
+		}
+		for _, e := range v {
+			writeValue(buf, e)
+		}
+	case wstruct:
+		writeStruct(buf, v)
+	}
+}
+
+// writeBitPacked writes values in a bit-packed run of the hybrid encoding
+func writeBitPacked(buf *bytes.Buffer, values []uint32, bitWidth int) {
+	groups := (len(values) + 7) / 8
+	writeUvarint(buf, uint64(groups<<1|1))
+	packed := make([]byte, groups*bitWidth)
+	for i, v := range values {
+		for b := 0; b < bitWidth; b++ {
+			bit := i*bitWidth + b
+			packed[bit/8] |= byte(v>>b&1) << (bit % 8)
+		}
+	}
+	buf.Write(packed)
+}
+
+func plainEncode(values []interface{}) []byte {
+	buf := &bytes.Buffer{}
+	var boolBits []uint32
+	for _, v := range values {
+		switch v := v.(type) {
+		case bool:
+			if v {
+				boolBits = append(boolBits, 1)
+			} else {
+				boolBits = append(boolBits, 0)
+			}
+		case int32:
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		case int64:
+			_ = binary.Write(buf, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(buf, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			_ = binary.Write(buf, binary.LittleEndian, uint32(len(v)))
+			buf.WriteString(v)
+		}
+	}
+	if boolBits != nil {
+		packed := &bytes.Buffer{}
+		writeBitPacked(packed, boolBits, 1)
+		// skip the header of the run
+		return packed.Bytes()[1:]
+	}
+	return buf.Bytes()
+}
+
+func compress(codec int64, data []byte) []byte {
+	switch codec {
+	case _CODEC_SNAPPY:
+		return snappy.Encode(nil, data)
+	case _CODEC_GZIP:
+		buf := &bytes.Buffer{}
+		w := gzip.NewWriter(buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+		return buf.Bytes()
+	case _CODEC_ZSTD:
+		w, _ := zstd.NewWriter(nil)
+		defer w.Close()
+		return w.EncodeAll(data, nil)
+	}
+	return data
+}
+
+type testColumn struct {
+	// schema is the SchemaElement of the column
+	schema   wstruct
+	optional bool
+	// values are of the rows, nil for null
+	values []interface{}
+	dict   bool
+}
+
+type testFile struct {
+	codec        int64
+	dataPageV2   bool
+	rowsPerGroup int
+	columns      []testColumn
+}
+
+func (tf testFile) write(path string) error {
+	out := &bytes.Buffer{}
+	out.WriteString(_MAGIC)
+
+	numRows := len(tf.columns[0].values)
+	rowGroups := []interface{}{}
+	for start := 0; start < numRows; start += tf.rowsPerGroup {
+		end := start + tf.rowsPerGroup
+		if end > numRows {
+			end = numRows
+		}
+		chunks := []interface{}{}
+		for _, col := range tf.columns {
+			chunks = append(chunks, tf.writeChunk(out, col, col.values[start:end]))
+		}
+		rowGroups = append(rowGroups, wstruct{{1, chunks}, {2, int64(0)}, {3, int64(end - start)}})
+	}
+
+	schema := []interface{}{wstruct{{4, "schema"}, {5, int32(len(tf.columns))}}}
+	for _, col := range tf.columns {
+		schema = append(schema, col.schema)
+	}
+	meta := &bytes.Buffer{}
+	writeStruct(meta, wstruct{{1, int32(1)}, {2, schema}, {3, int64(numRows)}, {4, rowGroups}})
+	out.Write(meta.Bytes())
+	_ = binary.Write(out, binary.LittleEndian, uint32(meta.Len()))
+	out.WriteString(_MAGIC)
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
+
+func (tf testFile) writeChunk(out *bytes.Buffer, col testColumn, values []interface{}) wstruct {
+	start := int64(out.Len())
+	var nonNull []interface{}
+	var defs []uint32
+	for _, v := range values {
+		if v == nil {
+			defs = append(defs, 0)
+			continue
+		}
+		defs = append(defs, 1)
+		nonNull = append(nonNull, v)
+	}
+
+	var dictOffset int64
+	encoding := int32(_ENCODING_PLAIN)
+	valuesData := plainEncode(nonNull)
+	if col.dict {
+		dictOffset = start
+		var dict []interface{}
+		indexOf := map[interface{}]uint32{}
+		var indices []uint32
+		for _, v := range nonNull {
+			if _, ok := indexOf[v]; !ok {
+				indexOf[v] = uint32(len(dict))
+				dict = append(dict, v)
+			}
+			indices = append(indices, indexOf[v])
+		}
+		dictData := plainEncode(dict)
+		compressed := compress(tf.codec, dictData)
+		writeStruct(out, wstruct{
+			{1, int32(_PAGE_DICTIONARY)}, {2, int32(len(dictData))}, {3, int32(len(compressed))},
+			{7, wstruct{{1, int32(len(dict))}, {2, int32(_ENCODING_PLAIN)}}},
+		})
+		out.Write(compressed)
+
+		encoding = _ENCODING_RLE_DICTIONARY
+		bitWidth := bits.Len(uint(len(dict) - 1))
+		if bitWidth == 0 {
+			bitWidth = 1
+		}
+		buf := &bytes.Buffer{}
+		buf.WriteByte(byte(bitWidth))
+		writeBitPacked(buf, indices, bitWidth)
+		valuesData = buf.Bytes()
+	}
+
+	levels := &bytes.Buffer{}
+	if col.optional {
+		writeBitPacked(levels, defs, 1)
+	}
+	dataOffset := int64(out.Len())
+	if tf.dataPageV2 {
+		compressed := compress(tf.codec, valuesData)
+		writeStruct(out, wstruct{
+			{1, int32(_PAGE_DATA_V2)},
+			{2, int32(levels.Len() + len(valuesData))},
+			{3, int32(levels.Len() + len(compressed))},
+			{8, wstruct{
+				{1, int32(len(values))}, {2, int32(len(values) - len(nonNull))}, {3, int32(len(values))},
+				{4, encoding}, {5, int32(levels.Len())}, {6, int32(0)},
+			}},
+		})
+		out.Write(levels.Bytes())
+		out.Write(compressed)
+	} else {
+		page := &bytes.Buffer{}
+		if col.optional {
+			_ = binary.Write(page, binary.LittleEndian, uint32(levels.Len()))
+			page.Write(levels.Bytes())
+		}
+		page.Write(valuesData)
+		compressed := compress(tf.codec, page.Bytes())
+		writeStruct(out, wstruct{
+			{1, int32(_PAGE_DATA)}, {2, int32(page.Len())}, {3, int32(len(compressed))},
+			{5, wstruct{{1, int32(len(values))}, {2, encoding}, {3, int32(_ENCODING_RLE)}, {4, int32(_ENCODING_RLE)}}},
+		})
+		out.Write(compressed)
+	}
+
+	metaData := wstruct{
+		{1, col.schema[0].v}, {2, []interface{}{encoding}}, {3, []interface{}{col.schema[2].v}},
+		{4, int32(tf.codec)}, {5, int64(len(values))}, {6, int64(0)}, {7, int64(out.Len()) - start},
+		{9, dataOffset},
+	}
+	if col.dict {
+		metaData = append(metaData, tfield{11, dictOffset})
+	}
+	return wstruct{{2, start}, {3, metaData}}
+}
@@ -15,4 +15,24 @@ var _ = Describe("Test export config items", func() {
 		Expect(stderr).To(BeEmpty())
 		Expect(stdout).To(Equal(DefaultConfig()))
 	})
+	It("should print config in yaml or json if given", func() {
+		code, stdout, stderr, err := ExecMxbench("config yaml --tag-num 10")
+		Expect(code).To(BeZero())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(BeEmpty())
+		Expect(stdout).To(ContainSubstring("global:\n"))
+		Expect(stdout).To(ContainSubstring("\n  tag-num: 10\n"))
+		Expect(stdout).To(ContainSubstring("\n  # total-metrics-count: 300\n"))
+
+		code, stdout, stderr, err = ExecMxbench("config json --tag-num 10")
+		Expect(code).To(BeZero())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(BeEmpty())
+		Expect(stdout).To(ContainSubstring(`"tag-num": 10,`))
+		Expect(stdout).To(ContainSubstring(`"total-metrics-count": 300,`))
+
+		code, _, stderr, _ = ExecMxbench("config xml")
+		Expect(code).To(Equal(2))
+		Expect(stderr).To(ContainSubstring("command config takes an optional format of toml, yaml or json"))
+	})
 })
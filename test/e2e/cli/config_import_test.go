@@ -1,9 +1,14 @@
 package cli_test
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/ymatrix-data/mxbench/internal/util"
 	. "github.com/ymatrix-data/mxbench/test/e2e/cli"
 )
 
@@ -16,6 +21,18 @@ var _ = Describe("Test import config items", func() {
 			Expect(stderr).To(ContainSubstring("error reading config file"))
 			Expect(stdout).To(BeEmpty())
 		})
+		It("should error listing the closest key names of unknown keys", func() {
+			tmpCfgFile := filepath.Join(util.TempDir(), "mxbench_unknown_e2e.yaml")
+			defer os.Remove(tmpCfgFile)
+			_ = os.WriteFile(tmpCfgFile, []byte(`
+global:
+  tag-nums: 10
+`), 0644)
+			code, _, stderr, err := ExecMxbench(fmt.Sprintf("run --config %s", tmpCfgFile))
+			Expect(code).To(Equal(1))
+			Expect(err).To(HaveOccurred())
+			Expect(stderr).To(ContainSubstring(`unknown key "tag-nums" in [global], did you mean "tag-num"`))
+		})
 	})
 
 })
@@ -18,20 +18,53 @@ const DefaultConfigTemplate = `[benchmark]
     ## Queries by combing expressions
     # benchmark-combination-queries = ""
 
+    ## keep executing the queries on a connection after an execution fails, reconnecting if the connection is lost,
+    ## instead of stopping the connection. Either way the errors are counted by class in the report
+    # benchmark-continue-on-error = false
+
     ## custom query SQLs, use "," to separate query statements.
     ## For example, ["SELECT COUNT(*) from t1", "SELECT MAX(ts) from t1"]
+    ## Placeholders are substituted on every execution: {{vin}}, {{vins:10}}, {{ts_range:3600}}, {{metric_col}}, {{random_int:1,100}}
     # benchmark-custom-queries = []
 
+    ## names of the custom queries in order, use "," to separate names.
+    ## A custom query without a name is named CUSTOM_QUERY_<n>, where n is its number from 1
+    # benchmark-custom-query-names = []
+
+    ## fail the benchmark if any result is invalid, only take effect when benchmark-validate-results is true
+    # benchmark-fail-on-invalid-results = false
+
     ## the count of metrics of json type, max is 1600
     # benchmark-json-metrics-count = 10
 
+    ## weights of the queries of the mixed workload, as name=weight, use "," to separate them.
+    ## For example, ["SINGLE_TAG_LATEST_QUERY=70", "SINGLE_TAG_DETAIL_QUERY=25", "CUSTOM_QUERY_1=5"].
+    ## All the queries are weighted equally by default, otherwise each of them should be given a weight
+    # benchmark-mixed-weights = []
+
+    ## run all the queries concurrently by one pool of connections with each of the parallels,
+    ## each execution picking a query by its weight in benchmark-mixed-weights, instead of running the queries one after another.
+    ## The stats are still reported for each query
+    # benchmark-mixed-workload = false
+
     ## parallels of benchmark,use "," to run queries with different concurrency.
     ## For example, input [1, 8] to run queries with parallel of 1 and 8 respectively.
     # benchmark-parallel = []
 
+    ## prepare each query once per connection and execute it with bound parameters by the extended protocol.
+    ## Only the telematics queries named in benchmark-run-query-names can be prepared, the others are still executed by their SQL text
+    # benchmark-prepared-statements = false
+
     ## progress format. support "list", "json"
     # benchmark-progress-format = "list"
 
+    ## cancel an execution of a query running longer than it by statement_timeout, 0 means no timeout
+    # benchmark-query-timeout-in-millisecond = "0"
+
+    ## the times to retry an execution failed by a serialization failure, a deadlock or a lost connection,
+    ## reconnecting first if the connection is lost
+    # benchmark-retries = 0
+
     ## query names to be run, the default includes none of telematics benchmark queries.
     ## Please input the query names, and use "," to separate query names.
     ## For example, input:
@@ -56,6 +89,11 @@ const DefaultConfigTemplate = `[benchmark]
     ## the start timestamp of query
     # benchmark-ts-start = ""
 
+    ## fetch the results of the telematics queries named in benchmark-run-query-names and validate them,
+    ## e.g. the latest queries return a row of each vin, and the detail query returns a row at each timestamp of the range.
+    ## Invalid results are counted in the report
+    # benchmark-validate-results = false
+
 [database]
 
   ## The database name of YMatrix
@@ -99,7 +137,9 @@ const DefaultConfigTemplate = `[benchmark]
     ## num of goroutines that it will use to call write function
     # generator-num-goroutine = 1
 
-    ## The randomness of metrics, OFF/S/M/L
+    ## The randomness of metrics, OFF/S/M/L/PER-VIN.
+    ## OFF/S/M/L copy the rows of all the devices from a template of 1/1200/3600/7200 rows,
+    ## while PER-VIN keeps the metrics of each device evolving continuously across timestamps.
     # generator-randomness = "OFF"
 
     ## the estimated mega bytes of batch size to call write function
@@ -125,6 +165,10 @@ const DefaultConfigTemplate = `[benchmark]
   ## log level. support "debug", "verbose", "info", "error"
   # log-level = "info"
 
+  ## the address to serve prometheus metrics at /metrics during the run, e.g. ":9187".
+  ## Empty means not to serve
+  # metrics-addr = ""
+
   ## metrics-descriptions
   # metrics-descriptions = ""
 
@@ -147,9 +191,17 @@ const DefaultConfigTemplate = `[benchmark]
   ## the file path for report to dump
   # report-path = "/tmp"
 
+  ## the database to save the config and statistics of each run into,
+  ## a PostgreSQL/YMatrix connection string, or "sqlite://<file>" for a local SQLite file. Empty means not to save
+  # results-db = ""
+
   ## the name of the schema
   # schema-name = "public"
 
+  ## the seed of the generated data and the parameters of benchmark queries,
+  ## runs of the same seed and config generate the same data and queries. 0 means a random seed, which is logged
+  # seed = 0
+
   ## whether to load data and run benchmark queries simultaneously
   # simultaneous-loading-and-query = false
 
@@ -162,6 +214,14 @@ const DefaultConfigTemplate = `[benchmark]
   ## the name of the table
   # table-name = ""
 
+  ## the tables to create, load and benchmark concurrently in one run, in JSON,
+  ## e.g. '[{"table-name": "t1", "tag-num": 1000}, {"table-name": "t2", "generator": {"generator-batch-size": 2}}]'.
+  ## Each table needs a table-name, while tag-num, metrics-type, total-metrics-count, metrics-descriptions,
+  ## storage-type and generator plugin options are inherited from the global ones unless given,
+  ## ddl-file-path, ts-column-name and vin-column-name are not.
+  ## Empty means to run the single table of table-name
+  # tables = ""
+
   ## the number of the tags/devices
   # tag-num = 25000
 
@@ -170,6 +230,10 @@ const DefaultConfigTemplate = `[benchmark]
   ## e.g. If it is given 1000 metrics, then 2 of them can be retrieved in column "ext" of JSON.
   # total-metrics-count = 300
 
+  ## the timestamp column of the table of ddl-file-path,
+  ## empty means the first column
+  # ts-column-name = ""
+
   ## the end timestamp; in 'realtime' mode it decides the the table's partition end time
   # ts-end = "2022-04-25 09:03:00"
 
@@ -182,12 +246,25 @@ const DefaultConfigTemplate = `[benchmark]
   ## print version
   # version = false
 
+  ## the device column of the table of ddl-file-path,
+  ## empty means the second column
+  # vin-column-name = ""
+
   ## whether to watch progress of each step or not
   # watch = true
 
   ## the workspace of mxbench, directory to dump or backup
   # workspace = "/tmp/mxbench"
 
+[thresholds]
+
+  ## limits of the results, in the form of "<query name or writer> <metric> <op> <value> [@<parallel>]".
+  ## Metrics of queries: tps, avg, stddev, min, max, p25, p50, p75, p90, p95, p99, p99.9, the values of latencies are durations.
+  ## Metrics of writer: rows-per-second, bytes-per-second, rows, bytes, compress-ratio.
+  ## Ops: <, <=, >, >=. mxbench exits with code 3 if any is violated,
+  ## e.g. "SINGLE_TAG_LATEST_QUERY p95 < 20ms @8", "writer rows-per-second > 1000000"
+  # thresholds = []
+
 %[5]s
 `
 
@@ -216,11 +293,21 @@ func DefaultConfig() string {
 	return fmt.Sprintf(DefaultConfigTemplate, pgDatabase, hostname, pgUser, pgPort, `[writer]
 
   ## Writer populates data to MatrixGate
-  ## Types restricted to: http/stdin/nil
+  ## Types restricted to: http/stdin/copy/insert/nil
   writer = "http"
 
   [writer.http]
 
+    ## the times to post again after backing off as mxgate asks for backpressure,
+    ## before failing the run, 0 fails at once
+    # writer-backoff-max-retries = 10
+
+    ## write into an in-process fake of mxgate, which counts the data instead of writing it into the database
+    # writer-fake-mxgate = false
+
+    ## the fake of mxgate backpressures every n-th post, 0 never
+    # writer-fake-mxgate-backpressure-every = 0
+
     ## interval for mxgate
     # writer-interval = -1
 
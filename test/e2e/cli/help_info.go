@@ -11,7 +11,10 @@ const DefaultHelpTemplate = `Usage of mxbench
 
 The commands are:
     run            Run mxbench in command line
-    config         Print full sample configuration to STDOUT
+    config         Print full sample configuration to STDOUT, in toml, or yaml or json if given
+    sweep          Run mxbench for each combination of the values in [sweep] of --config
+    scenario       Run the [[scenario]] phases of --config one after another
+    compare        Compare the results of 2 runs saved in --results-db
     help           Show usage
     version        Show version
 
@@ -37,16 +40,32 @@ The arguments are:
       --simultaneous-loading-and-query   whether to load data and run benchmark queries simultaneously
       --workspace string                 the workspace of mxbench, directory to dump or backup (default "/tmp/mxbench")
       --ddl-file-path string             the file path of ddl
+      --ts-column-name string            the timestamp column of the table of ddl-file-path,
+                                         empty means the first column
+      --vin-column-name string           the device column of the table of ddl-file-path,
+                                         empty means the second column
       --storage-type string              storage type
   -C, --config string                    configuration file to load
       --skip-set-gucs                    whether to skip set GUCs
       --pre-benchmark-query string       some specific sql such as analyze and vaccum database before run benchmark queries
+      --tables string                    the tables to create, load and benchmark concurrently in one run, in JSON,
+                                         e.g. '[{"table-name": "t1", "tag-num": 1000}, {"table-name": "t2", "generator": {"generator-batch-size": 2}}]'.
+                                         Each table needs a table-name, while tag-num, metrics-type, total-metrics-count, metrics-descriptions,
+                                         storage-type and generator plugin options are inherited from the global ones unless given,
+                                         ddl-file-path, ts-column-name and vin-column-name are not.
+                                         Empty means to run the single table of table-name
+      --seed int                         the seed of the generated data and the parameters of benchmark queries,
+                                         runs of the same seed and config generate the same data and queries. 0 means a random seed, which is logged
       --log-level string                 log level. support "debug", "verbose", "info", "error" (default "info")
   -h, --help                             print usage
       --version                          print version
       --watch                            whether to watch progress of each step or not (default true)
       --report-format string             the format of report (default "csv")
       --report-path string               the file path for report to dump (default "/tmp")
+      --results-db string                the database to save the config and statistics of each run into,
+                                         a PostgreSQL/YMatrix connection string, or "sqlite://<file>" for a local SQLite file. Empty means not to save
+      --metrics-addr string              the address to serve prometheus metrics at /metrics during the run, e.g. ":9187".
+                                         Empty means not to serve
 
   Database Options:
       --db-master-host string   The hostname of YMatrix master (default "%[1]s")
@@ -66,11 +85,18 @@ The arguments are:
 %[7]s
   Writer Options:
       --writer string   Writer populates data to MatrixGate
-                        Types restricted to: http/stdin/nil (default "http")
+                        Types restricted to: http/stdin/copy/insert/nil (default "http")
       --writer-progress-format string        progress format, support "list", "json" (default "list")
       --writer-progress-include-table-size   whether progress include table size
       --writer-progress-with-timezone        whether print time with timezone
 %[6]s
+  Thresholds Options:
+      --thresholds strings   limits of the results, in the form of "<query name or writer> <metric> <op> <value> [@<parallel>]".
+                             Metrics of queries: tps, avg, stddev, min, max, p25, p50, p75, p90, p95, p99, p99.9, the values of latencies are durations.
+                             Metrics of writer: rows-per-second, bytes-per-second, rows, bytes, compress-ratio.
+                             Ops: <, <=, >, >=. mxbench exits with code 3 if any is violated,
+                             e.g. "SINGLE_TAG_LATEST_QUERY p95 < 20ms @8", "writer rows-per-second > 1000000"
+
 Examples:
 
     # generate a mxbench config file with given args:
@@ -79,6 +105,9 @@ Examples:
     # example to generate with listed argements, all non-listed values be default:
     %[4]s config --db-master-port 6000 > mxbench.conf
 
+    # generate in yaml or json, which --config loads by the file extension:
+    %[4]s config yaml [<args...>] > mxbench.yaml
+
     # edit mxbench.conf with your customized configuration for each plugin:
     # such as delimiter or time format
     vim mxbench.conf
@@ -113,7 +142,9 @@ func TelematicsgGeneratorStdinWriterNilBenchmarkUsage() string {
                                           e.g. It is set to be 5, then for tag "tag1" with ts of "2022-04-02 15:04:03",
                                           5 lines of data will be generated and sent into DBMS.
                                           Eventually, however, they will be merged as 1 row in DBMS. (default 1)
-      --generator-randomness string       The randomness of metrics, OFF/S/M/L (default "OFF")
+      --generator-randomness string       The randomness of metrics, OFF/S/M/L/PER-VIN.
+                                          OFF/S/M/L copy the rows of all the devices from a template of 1/1200/3600/7200 rows,
+                                          while PER-VIN keeps the metrics of each device evolving continuously across timestamps. (default "OFF")
       --generator-empty-value-ratio int   the ratio of empty metrics value in one line.
                                           Expected to be an integer ranging from 0 to 100 (included). (default 90)
 `, ``, ``)
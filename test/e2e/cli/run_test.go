@@ -164,4 +164,36 @@ var _ = Describe("Test 'run' command", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(stderr).To(ContainSubstring("Database is non_existed_db_3"))
 	})
+	It("run into the fake mxgate without the database", func() {
+		// DebugOutput = true
+		workspace := filepath.Join(util.TempDir(), "mxbench_run_fake_mxgate")
+		defer os.RemoveAll(workspace)
+		_ = os.WriteFile(tmpCfgFile, []byte(`
+[database]
+  db-database = 'non_existed_db_1'
+  db-master-port = 1
+[benchmark]
+  benchmark = "telematics"
+[generator]
+  generator = "telematics"
+[writer]
+  writer = "http"
+  [writer.http]
+    writer-fake-mxgate = true
+    writer-fake-mxgate-backpressure-every = 2
+[global]
+  table-name = "st_signal_fake_mxgate"
+  tag-num = 10
+  ts-start = "2022-04-25 09:00:00"
+  ts-end = "2022-04-25 09:01:00"
+`), 0644)
+		code, stdout, stderr, err := ExecMxbenchWithTimeout(context.Background(),
+			fmt.Sprintf("run --config %s --workspace %s", tmpCfgFile, workspace), 30)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(BeZero())
+		Expect(stdout + stderr).To(ContainSubstring("The fake mxgate accepted 600 rows"))
+		ddls, _ := filepath.Glob(filepath.Join(workspace, "*", "mxbench_ddl.sql"))
+		Expect(ddls).To(HaveLen(1))
+	})
 })